@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/edellingham/nirimatic/internal/cheatsheet"
+	"github.com/edellingham/nirimatic/internal/config"
+)
+
+// runBinds runs `nirimatic binds <command>` and returns the exit code
+func runBinds(loc config.ConfigLocation, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: nirimatic binds check|export")
+		return 2
+	}
+
+	switch args[0] {
+	case "check":
+		return checkBinds(loc)
+	case "export":
+		return exportBinds(loc, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown binds command %q\n", args[0])
+		return 2
+	}
+}
+
+// checkBinds prints every problem found in the binds. It fails if any
+// of them would make niri reject the config.
+func checkBinds(loc config.ConfigLocation) int {
+	cfg, err := config.LoadNiriConfig(loc.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", loc.Path, err)
+		return 1
+	}
+
+	problems := cfg.CheckBinds()
+	errors := 0
+	for _, p := range problems {
+		fmt.Println(p)
+		if p.Severity == config.SeverityError {
+			errors++
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Printf("%d binds, no problems found\n", len(cfg.Binds))
+	}
+	if errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// exportBinds writes a cheat sheet of the binds to stdout or a file
+func exportBinds(loc config.ConfigLocation, args []string) int {
+	flags := flag.NewFlagSet("binds export", flag.ContinueOnError)
+	formatName := flags.String("format", "", "md or html (default from the output file, else md)")
+	output := flags.String("o", "", "write to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format := cheatsheet.FormatForPath(*output)
+	if *formatName != "" {
+		f, err := cheatsheet.ParseFormat(*formatName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		format = f
+	}
+
+	cfg, err := config.LoadNiriConfig(loc.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", loc.Path, err)
+		return 1
+	}
+
+	out := cheatsheet.New(cfg).Render(format)
+	if *output == "" {
+		fmt.Print(out)
+		return 0
+	}
+	if err := os.WriteFile(*output, []byte(out), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, err)
+		return 1
+	}
+	return 0
+}
@@ -1,20 +1,45 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
 	"github.com/edellingham/nirimatic/internal/tui"
+	"github.com/edellingham/nirimatic/internal/tui/screens"
 )
 
 func main() {
-	app := tui.NewApp()
+	var configPath string
+	flag.StringVar(&configPath, "config", "", "path to the niri config (overrides $NIRI_CONFIG)")
+	flag.StringVar(&configPath, "c", "", "shorthand for --config")
+	flag.Usage = usage
+	flag.Parse()
+
+	loc := config.ResolveConfigPath(configPath)
+
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "binds":
+			os.Exit(runBinds(loc, flag.Args()[1:]))
+		case "rule":
+			os.Exit(runRule(loc, flag.Args()[1:]))
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+			usage()
+			os.Exit(2)
+		}
+	}
+
+	app := tui.NewApp(loc)
 
 	p := tea.NewProgram(
 		app,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
+		tea.WithOutput(screens.Terminal),
 	)
 
 	if _, err := p.Run(); err != nil {
@@ -22,3 +47,19 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// usage prints the command line help
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "usage: nirimatic [--config path] [command]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Without a command, the configuration TUI starts.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "commands:")
+	fmt.Fprintln(out, "  binds check        report duplicate, shadowed and broken keybinds")
+	fmt.Fprintln(out, "  binds export       print a keybind cheat sheet (--format md|html, -o file)")
+	fmt.Fprintln(out, "  rule from-focused  write a window rule for the focused window")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "flags:")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/niriipc"
+	"github.com/edellingham/nirimatic/internal/tui/screens"
+)
+
+// runRule runs `nirimatic rule <command>` and returns the exit code
+func runRule(loc config.ConfigLocation, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: nirimatic rule from-focused")
+		return 2
+	}
+
+	switch args[0] {
+	case "from-focused":
+		return ruleFromFocused(loc)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown rule command %q\n", args[0])
+		return 2
+	}
+}
+
+// ruleFromFocused opens the window rule editor for niri's focused window
+// and appends the rule to the config
+func ruleFromFocused(loc config.ConfigLocation) int {
+	cfg, err := config.LoadNiriConfig(loc.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", loc.Path, err)
+		return 1
+	}
+
+	client, err := niriipc.NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to niri: %v\n", err)
+		return 1
+	}
+	window, err := client.FocusedWindow()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error asking niri for the focused window: %v\n", err)
+		return 1
+	}
+	if window == nil {
+		fmt.Fprintln(os.Stderr, "No window is focused")
+		return 1
+	}
+	workspaces, err := client.Workspaces()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error asking niri for workspaces: %v\n", err)
+		return 1
+	}
+
+	editor := screens.NewRuleEditorModel(cfg, *window, workspaces)
+	if _, err := tea.NewProgram(editor).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running the rule editor: %v\n", err)
+		return 1
+	}
+	saved, err := editor.Saved()
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error saving %s: %v\n", loc.Path, err)
+		return 1
+	case !saved:
+		fmt.Println("Cancelled, no rule added")
+		return 0
+	}
+	fmt.Printf("Added a window rule to %s\n", loc.Path)
+	return 0
+}
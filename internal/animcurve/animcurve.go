@@ -0,0 +1,187 @@
+// Package animcurve simulates niri's animations: the damped spring and
+// the easing curves, so their motion can be shown before it is saved
+package animcurve
+
+import (
+	"math"
+	"time"
+
+	"github.com/edellingham/nirimatic/internal/config"
+)
+
+// Step is the time between samples of a curve
+const Step = time.Millisecond
+
+// maxDuration caps the simulation of springs that barely settle
+const maxDuration = 10 * time.Second
+
+// Curve is the position over time of an animation going from 0 to 1
+type Curve struct {
+	Points    []float64     // position at every Step, starting at 0
+	Settle    time.Duration // time until the animation comes to rest, or the time simulated if it doesn't
+	Settled   bool          // false for a spring still moving after maxDuration
+	Overshoot float64       // furthest it goes past 1, 0 if it never does
+}
+
+// Simulate computes the curve of an animation. slowdown stretches time
+// as niri's animations slowdown setting does.
+func Simulate(p config.AnimationParams, slowdown float64) Curve {
+	if slowdown <= 0 {
+		slowdown = 1
+	}
+
+	var c Curve
+	if p.Kind == config.AnimationSpring {
+		c = simulateSpring(p.Spring)
+	} else {
+		c = simulateEasing(p.Easing)
+	}
+	if slowdown != 1 {
+		c = c.stretch(slowdown)
+	}
+	for _, x := range c.Points {
+		c.Overshoot = max(c.Overshoot, x-1)
+	}
+	return c
+}
+
+// simulateSpring samples a spring until it stays within epsilon of the
+// target
+func simulateSpring(s config.Spring) Curve {
+	epsilon := s.Epsilon
+	if epsilon <= 0 {
+		epsilon = 0.0001
+	}
+	limit := int(maxDuration / Step)
+
+	var c Curve
+	last := 0 // last sample still outside epsilon
+	for i := 0; i <= limit; i++ {
+		x := SpringPosition(s, (time.Duration(i) * Step).Seconds())
+		c.Points = append(c.Points, x)
+		if math.Abs(x-1) >= epsilon {
+			last = i
+		}
+		// A spring is at rest once it has stayed settled for a while
+		if i-last > 200 {
+			break
+		}
+	}
+	if last+1 >= len(c.Points) {
+		// Still moving when the simulation gave up
+		c.Settle = time.Duration(len(c.Points)-1) * Step
+		return c
+	}
+	c.Points = c.Points[:last+2]
+	c.Points[last+1] = 1
+	c.Settle = time.Duration(last+1) * Step
+	c.Settled = true
+	return c
+}
+
+// SpringPosition returns the position of a spring going from 0 to 1,
+// t seconds in. This is the critically, under- and overdamped solution
+// niri uses, with a mass of 1 and no initial velocity.
+func SpringPosition(s config.Spring, t float64) float64 {
+	stiffness := max(s.Stiffness, 1)
+	omega0 := math.Sqrt(stiffness)
+	beta := s.DampingRatio * omega0 // damping / 2m
+	x0 := -1.0                      // start relative to the target
+	envelope := math.Exp(-beta * t)
+
+	switch {
+	case beta < omega0:
+		omega1 := math.Sqrt(omega0*omega0 - beta*beta)
+		return 1 + envelope*(x0*math.Cos(omega1*t)+(beta*x0/omega1)*math.Sin(omega1*t))
+	case beta == omega0:
+		return 1 + envelope*(x0+beta*x0*t)
+	default:
+		omega2 := math.Sqrt(beta*beta - omega0*omega0)
+		return 1 + envelope*(x0*math.Cosh(omega2*t)+(beta*x0/omega2)*math.Sinh(omega2*t))
+	}
+}
+
+// simulateEasing samples an easing curve over its duration
+func simulateEasing(e config.Easing) Curve {
+	n := max(e.DurationMs, 0)
+	c := Curve{Settle: time.Duration(n) * time.Millisecond, Settled: true}
+	for i := 0; i <= n; i++ {
+		t := 1.0
+		if n > 0 {
+			t = float64(i) / float64(n)
+		}
+		c.Points = append(c.Points, EasingPosition(e, t))
+	}
+	return c
+}
+
+// EasingPosition returns the position of an easing curve at progress t,
+// from 0 to 1
+func EasingPosition(e config.Easing, t float64) float64 {
+	t = max(0, min(1, t))
+	switch e.Curve {
+	case "linear":
+		return t
+	case "ease-out-quad":
+		return 1 - (1-t)*(1-t)
+	case "ease-out-cubic":
+		return 1 - math.Pow(1-t, 3)
+	case "ease-out-expo":
+		if t == 1 {
+			return 1
+		}
+		return 1 - math.Pow(2, -10*t)
+	case "cubic-bezier":
+		return cubicBezier(e.Bezier, t)
+	}
+	return t
+}
+
+// cubicBezier evaluates a CSS-style cubic bezier timing function: it
+// finds the curve parameter whose x is t and returns its y
+func cubicBezier(p [4]float64, t float64) float64 {
+	x1, y1, x2, y2 := p[0], p[1], p[2], p[3]
+	bezier := func(a, b, u float64) float64 {
+		return 3*a*u*(1-u)*(1-u) + 3*b*u*u*(1-u) + u*u*u
+	}
+
+	// x is monotonic for control points in [0, 1], so bisect
+	lo, hi := 0.0, 1.0
+	u := t
+	for range 50 {
+		x := bezier(x1, x2, u)
+		if math.Abs(x-t) < 1e-7 {
+			break
+		}
+		if x < t {
+			lo = u
+		} else {
+			hi = u
+		}
+		u = (lo + hi) / 2
+	}
+	return bezier(y1, y2, u)
+}
+
+// stretch slows the curve down by factor, resampling it
+func (c Curve) stretch(factor float64) Curve {
+	n := int(math.Round(float64(len(c.Points)-1) * factor))
+	out := Curve{Settle: time.Duration(float64(c.Settle) * factor), Settled: c.Settled}
+	for i := 0; i <= n; i++ {
+		src := min(len(c.Points)-1, int(float64(i)/factor))
+		out.Points = append(out.Points, c.Points[src])
+	}
+	return out
+}
+
+// At returns the position at time t, 1 once the curve has ended
+func (c Curve) At(t time.Duration) float64 {
+	i := int(t / Step)
+	if i < 0 {
+		return 0
+	}
+	if i >= len(c.Points) {
+		return 1
+	}
+	return c.Points[i]
+}
@@ -0,0 +1,171 @@
+// Package cheatsheet renders niri keybinds as a printable cheat sheet
+package cheatsheet
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/edellingham/nirimatic/internal/config"
+)
+
+// Format is an output format for the cheat sheet
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat returns the format named s
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want md or html)", s)
+}
+
+// FormatForPath picks the format from a file extension, defaulting to
+// Markdown
+func FormatForPath(path string) Format {
+	if f, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil {
+		return f
+	}
+	return FormatMarkdown
+}
+
+// Entry is one bind on the cheat sheet
+type Entry struct {
+	Keys        []string // chord parts, e.g. Mod, Shift, T
+	Description string
+}
+
+// Section is a group of binds under a heading
+type Section struct {
+	Title   string
+	Entries []Entry
+}
+
+// Sheet is the cheat sheet for a config
+type Sheet struct {
+	ModKey   string
+	Sections []Section
+}
+
+// sectionOrder lists the section headings in display order
+var sectionOrder = []string{
+	"Focus", "Move", "Workspaces", "Layout", "Spawn", "Media", "Screenshots", "System", "Other",
+}
+
+// New groups the binds of a config into sections
+func New(cfg *config.NiriConfig) *Sheet {
+	groups := map[string][]Entry{}
+	for _, b := range cfg.Binds {
+		title := category(b)
+		groups[title] = append(groups[title], Entry{
+			Keys:        chordKeys(b.Chord),
+			Description: describe(b),
+		})
+	}
+
+	sheet := &Sheet{ModKey: cfg.ModKey}
+	for _, title := range sectionOrder {
+		if entries := groups[title]; len(entries) > 0 {
+			sheet.Sections = append(sheet.Sections, Section{Title: title, Entries: entries})
+		}
+	}
+	return sheet
+}
+
+// Render renders the sheet in a format
+func (s *Sheet) Render(format Format) string {
+	if format == FormatHTML {
+		return s.HTML()
+	}
+	return s.Markdown()
+}
+
+// mediaPrograms are programs whose spawn binds count as media keys
+var mediaPrograms = []string{"wpctl", "pactl", "pamixer", "amixer", "playerctl", "brightnessctl", "light"}
+
+// category returns the section a bind belongs in
+func category(b *config.Bind) string {
+	key := strings.ToLower(b.Chord.Key)
+	if strings.HasPrefix(key, "xf86audio") || strings.HasPrefix(key, "xf86monbrightness") {
+		return "Media"
+	}
+
+	action, ok := config.LookupAction(b.Action)
+	if !ok {
+		return "Other"
+	}
+	switch action.Category {
+	case "focus":
+		return "Focus"
+	case "move":
+		return "Move"
+	case "monitor":
+		if strings.HasPrefix(action.Name, "focus-") {
+			return "Focus"
+		}
+		return "Move"
+	case "workspace":
+		return "Workspaces"
+	case "size", "floating":
+		return "Layout"
+	case "spawn":
+		if len(b.Args) > 0 && slices.Contains(mediaPrograms, filepath.Base(firstWord(b.Args[0].Str))) {
+			return "Media"
+		}
+		return "Spawn"
+	case "screenshot":
+		return "Screenshots"
+	}
+	return "System"
+}
+
+// describe returns the text shown for a bind: its hotkey overlay title,
+// the command it spawns, or the action's description
+func describe(b *config.Bind) string {
+	if b.HotkeyOverlayTitle != "" {
+		return b.HotkeyOverlayTitle
+	}
+	switch b.Action {
+	case "spawn", "spawn-sh":
+		var words []string
+		for _, a := range b.Args {
+			if str, ok := a.AsString(); ok {
+				words = append(words, str)
+			} else {
+				words = append(words, a.String())
+			}
+		}
+		return "Run " + strings.Join(words, " ")
+	}
+	action, ok := config.LookupAction(b.Action)
+	if !ok {
+		return b.ActionString()
+	}
+	if len(b.Args) == 0 && len(b.ActionProps) == 0 {
+		return action.Description
+	}
+	return fmt.Sprintf("%s (%s)", action.Description, b.ArgsString())
+}
+
+// chordKeys splits a chord into the keys to press
+func chordKeys(k config.KeyChord) []string {
+	return append(slices.Clone(k.Modifiers), k.Key)
+}
+
+// firstWord returns the first space-separated word of s, for spawn-sh
+// commands and programs given with arguments
+func firstWord(s string) string {
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
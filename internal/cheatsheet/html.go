@@ -0,0 +1,97 @@
+package cheatsheet
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// palette holds the Eldritch colors the HTML page uses, so it matches
+// the TUI
+type palette struct {
+	Background, CurrentLine, Foreground, Comment string
+	Cyan, Green, Pink, Purple                    string
+}
+
+var htmlTemplate = template.Must(template.New("cheatsheet").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>niri keybinds</title>
+<style>
+  body {
+    background: {{.Colors.Background}};
+    color: {{.Colors.Foreground}};
+    font-family: "JetBrains Mono", "Fira Code", monospace;
+    margin: 2rem auto;
+    max-width: 60rem;
+    padding: 0 1rem;
+  }
+  h1 { color: {{.Colors.Cyan}}; }
+  h2 {
+    color: {{.Colors.Pink}};
+    border-bottom: 1px solid {{.Colors.Purple}};
+    padding-bottom: 0.25rem;
+  }
+  p { color: {{.Colors.Comment}}; }
+  table { border-collapse: collapse; width: 100%; }
+  td { padding: 0.3rem 0.6rem; vertical-align: top; }
+  tr:nth-child(even) { background: {{.Colors.CurrentLine}}; }
+  td.keys { white-space: nowrap; width: 1%; color: {{.Colors.Comment}}; }
+  kbd {
+    background: {{.Colors.CurrentLine}};
+    border: 1px solid {{.Colors.Purple}};
+    border-radius: 4px;
+    color: {{.Colors.Green}};
+    padding: 0.05rem 0.35rem;
+  }
+  @media print {
+    body { margin: 0; max-width: none; }
+    h2 { break-after: avoid; }
+    tr { break-inside: avoid; }
+  }
+</style>
+</head>
+<body>
+<h1>niri keybinds</h1>
+<p><kbd>Mod</kbd> is {{.ModKey}}.</p>
+{{range .Sections}}
+<h2>{{.Title}}</h2>
+<table>
+{{- range .Entries}}
+<tr><td class="keys">{{range $i, $k := .Keys}}{{if $i}} + {{end}}<kbd>{{$k}}</kbd>{{end}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the sheet as a standalone HTML page in the Eldritch
+// colors
+func (s *Sheet) HTML() string {
+	data := struct {
+		*Sheet
+		Colors palette
+	}{
+		Sheet: s,
+		Colors: palette{
+			Background:  string(styles.ColorBackground),
+			CurrentLine: string(styles.ColorCurrentLine),
+			Foreground:  string(styles.ColorForeground),
+			Comment:     string(styles.ColorComment),
+			Cyan:        string(styles.ColorCyan),
+			Green:       string(styles.ColorGreen),
+			Pink:        string(styles.ColorPink),
+			Purple:      string(styles.ColorPurple),
+		},
+	}
+
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		// The template is fixed, so this only fails on a programming error
+		panic(err)
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package cheatsheet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders the sheet as a Markdown document with a table per
+// section
+func (s *Sheet) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# niri keybinds\n\n")
+	fmt.Fprintf(&b, "`Mod` is %s.\n", s.ModKey)
+
+	for _, sec := range s.Sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", sec.Title)
+		b.WriteString("| Keys | Action |\n")
+		b.WriteString("|------|--------|\n")
+		for _, e := range sec.Entries {
+			keys := make([]string, len(e.Keys))
+			for i, k := range e.Keys {
+				keys[i] = "`" + escapeMarkdown(k) + "`"
+			}
+			fmt.Fprintf(&b, "| %s | %s |\n", strings.Join(keys, " + "), escapeMarkdown(e.Description))
+		}
+	}
+	return b.String()
+}
+
+// escapeMarkdown keeps text from breaking out of a table cell
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
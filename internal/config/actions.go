@@ -0,0 +1,403 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ArgKind is the type of an action argument or property
+type ArgKind int
+
+const (
+	ArgString ArgKind = iota
+	ArgInt
+	ArgBool
+	ArgSizeChange // "+10%", "-50", "1000" or "50%"
+	ArgWorkspace  // workspace index or name
+	ArgChoice     // one of a fixed set of strings
+	ArgLayout     // "next", "prev" or a keyboard layout index
+)
+
+func (k ArgKind) String() string {
+	switch k {
+	case ArgInt:
+		return "int"
+	case ArgBool:
+		return "bool"
+	case ArgSizeChange:
+		return "size"
+	case ArgWorkspace:
+		return "index|name"
+	case ArgChoice:
+		return "choice"
+	case ArgLayout:
+		return "next|prev|index"
+	}
+	return "string"
+}
+
+// ArgSpec describes an argument of an action
+type ArgSpec struct {
+	Name     string
+	Kind     ArgKind
+	Optional bool
+	Repeated bool     // takes any number of values, at least one unless optional
+	Choices  []string // for ArgChoice
+}
+
+// PropSpec describes a property of an action, such as focus=false
+type PropSpec struct {
+	Name string
+	Kind ArgKind
+}
+
+// Action describes one of niri's bind actions
+type Action struct {
+	Name        string
+	Description string
+	Category    string // focus, move, workspace, monitor, size, floating, spawn, screenshot or system
+	Since       string // first niri release with the action
+	Args        []ArgSpec
+	Props       []PropSpec
+}
+
+// Argument shorthands for the catalog
+var (
+	argWorkspace = []ArgSpec{{Name: "workspace", Kind: ArgWorkspace}}
+	argSize      = []ArgSpec{{Name: "change", Kind: ArgSizeChange}}
+	argIndex     = []ArgSpec{{Name: "index", Kind: ArgInt}}
+	argOutput    = []ArgSpec{{Name: "output", Kind: ArgString}}
+	propFocus    = []PropSpec{{Name: "focus", Kind: ArgBool}}
+)
+
+// actions is the catalog of niri actions
+var actions = []Action{
+	// System
+	{Name: "quit", Category: "system", Since: "0.1.0", Description: "Exit niri",
+		Props: []PropSpec{{Name: "skip-confirmation", Kind: ArgBool}}},
+	{Name: "power-off-monitors", Category: "system", Since: "0.1.0", Description: "Turn all monitors off until the next input"},
+	{Name: "power-on-monitors", Category: "system", Since: "25.01", Description: "Turn all monitors back on"},
+	{Name: "toggle-keyboard-shortcuts-inhibit", Category: "system", Since: "0.1.10", Description: "Let the focused app grab or release niri's shortcuts"},
+	{Name: "show-hotkey-overlay", Category: "system", Since: "0.1.1", Description: "Show the important hotkeys"},
+	{Name: "do-screen-transition", Category: "system", Since: "0.1.8", Description: "Crossfade the next screen change",
+		Props: []PropSpec{{Name: "delay-ms", Kind: ArgInt}}},
+	{Name: "load-config-file", Category: "system", Since: "25.08", Description: "Reload the config file"},
+	{Name: "switch-layout", Category: "system", Since: "0.1.0", Description: "Switch the keyboard layout",
+		Args: []ArgSpec{{Name: "layout", Kind: ArgLayout}}},
+	{Name: "toggle-overview", Category: "system", Since: "25.05", Description: "Open or close the overview"},
+	{Name: "open-overview", Category: "system", Since: "25.05", Description: "Open the overview"},
+	{Name: "close-overview", Category: "system", Since: "25.05", Description: "Close the overview"},
+	{Name: "set-dynamic-cast-window", Category: "system", Since: "25.05", Description: "Cast the focused window in the dynamic cast target"},
+	{Name: "set-dynamic-cast-monitor", Category: "system", Since: "25.05", Description: "Cast the focused monitor in the dynamic cast target",
+		Args: []ArgSpec{{Name: "output", Kind: ArgString, Optional: true}}},
+	{Name: "clear-dynamic-cast-target", Category: "system", Since: "25.05", Description: "Stop casting to the dynamic cast target"},
+	{Name: "toggle-debug-tint", Category: "system", Since: "0.1.0", Description: "Tint surfaces to debug rendering"},
+	{Name: "debug-toggle-opaque-regions", Category: "system", Since: "0.1.6", Description: "Highlight opaque regions"},
+	{Name: "debug-toggle-damage", Category: "system", Since: "0.1.6", Description: "Highlight damaged regions"},
+
+	// Spawning
+	{Name: "spawn", Category: "spawn", Since: "0.1.0", Description: "Run a program",
+		Args: []ArgSpec{{Name: "command", Kind: ArgString, Repeated: true}}},
+	{Name: "spawn-sh", Category: "spawn", Since: "25.08", Description: "Run a shell command",
+		Args: []ArgSpec{{Name: "command", Kind: ArgString}}},
+
+	// Screenshots
+	{Name: "screenshot", Category: "screenshot", Since: "0.1.0", Description: "Open the screenshot UI",
+		Props: []PropSpec{{Name: "show-pointer", Kind: ArgBool}}},
+	{Name: "screenshot-screen", Category: "screenshot", Since: "0.1.0", Description: "Screenshot the focused monitor",
+		Props: []PropSpec{{Name: "write-to-disk", Kind: ArgBool}, {Name: "show-pointer", Kind: ArgBool}}},
+	{Name: "screenshot-window", Category: "screenshot", Since: "0.1.0", Description: "Screenshot the focused window",
+		Props: []PropSpec{{Name: "write-to-disk", Kind: ArgBool}}},
+
+	// Windows
+	{Name: "close-window", Category: "system", Since: "0.1.0", Description: "Close the focused window"},
+	{Name: "fullscreen-window", Category: "size", Since: "0.1.0", Description: "Toggle fullscreen on the focused window"},
+	{Name: "toggle-windowed-fullscreen", Category: "size", Since: "25.05", Description: "Toggle fullscreen while keeping the window's size"},
+	{Name: "toggle-window-urgent", Category: "system", Since: "25.05", Description: "Toggle the urgent mark on a window",
+		Args: []ArgSpec{{Name: "id", Kind: ArgInt}}},
+	{Name: "set-window-urgent", Category: "system", Since: "25.05", Description: "Mark a window urgent",
+		Args: []ArgSpec{{Name: "id", Kind: ArgInt}}},
+	{Name: "unset-window-urgent", Category: "system", Since: "25.05", Description: "Clear a window's urgent mark",
+		Args: []ArgSpec{{Name: "id", Kind: ArgInt}}},
+
+	// Focus
+	{Name: "focus-window", Category: "focus", Since: "0.1.7", Description: "Focus a window by id",
+		Args: []ArgSpec{{Name: "id", Kind: ArgInt}}},
+	{Name: "focus-window-in-column", Category: "focus", Since: "25.01", Description: "Focus a window in the column by index",
+		Args: argIndex},
+	{Name: "focus-window-previous", Category: "focus", Since: "25.02", Description: "Focus the previously focused window"},
+	{Name: "focus-column-left", Category: "focus", Since: "0.1.0", Description: "Focus the column to the left"},
+	{Name: "focus-column-right", Category: "focus", Since: "0.1.0", Description: "Focus the column to the right"},
+	{Name: "focus-column-first", Category: "focus", Since: "0.1.0", Description: "Focus the first column"},
+	{Name: "focus-column-last", Category: "focus", Since: "0.1.0", Description: "Focus the last column"},
+	{Name: "focus-column-right-or-first", Category: "focus", Since: "0.1.6", Description: "Focus the column to the right, wrapping around"},
+	{Name: "focus-column-left-or-last", Category: "focus", Since: "0.1.6", Description: "Focus the column to the left, wrapping around"},
+	{Name: "focus-column", Category: "focus", Since: "25.01", Description: "Focus a column by index",
+		Args: argIndex},
+	{Name: "focus-window-or-monitor-up", Category: "focus", Since: "0.1.3", Description: "Focus the window above or the monitor above"},
+	{Name: "focus-window-or-monitor-down", Category: "focus", Since: "0.1.3", Description: "Focus the window below or the monitor below"},
+	{Name: "focus-column-or-monitor-left", Category: "focus", Since: "0.1.3", Description: "Focus the column to the left or the monitor to the left"},
+	{Name: "focus-column-or-monitor-right", Category: "focus", Since: "0.1.3", Description: "Focus the column to the right or the monitor to the right"},
+	{Name: "focus-window-down", Category: "focus", Since: "0.1.0", Description: "Focus the window below"},
+	{Name: "focus-window-up", Category: "focus", Since: "0.1.0", Description: "Focus the window above"},
+	{Name: "focus-window-down-or-column-left", Category: "focus", Since: "0.1.4", Description: "Focus the window below or the column to the left"},
+	{Name: "focus-window-down-or-column-right", Category: "focus", Since: "0.1.4", Description: "Focus the window below or the column to the right"},
+	{Name: "focus-window-up-or-column-left", Category: "focus", Since: "0.1.4", Description: "Focus the window above or the column to the left"},
+	{Name: "focus-window-up-or-column-right", Category: "focus", Since: "0.1.4", Description: "Focus the window above or the column to the right"},
+	{Name: "focus-window-or-workspace-down", Category: "focus", Since: "0.1.2", Description: "Focus the window below or the workspace below"},
+	{Name: "focus-window-or-workspace-up", Category: "focus", Since: "0.1.2", Description: "Focus the window above or the workspace above"},
+	{Name: "focus-window-top", Category: "focus", Since: "0.1.7", Description: "Focus the topmost window in the column"},
+	{Name: "focus-window-bottom", Category: "focus", Since: "0.1.7", Description: "Focus the bottommost window in the column"},
+	{Name: "focus-window-down-or-top", Category: "focus", Since: "0.1.7", Description: "Focus the window below, wrapping to the top"},
+	{Name: "focus-window-up-or-bottom", Category: "focus", Since: "0.1.7", Description: "Focus the window above, wrapping to the bottom"},
+
+	// Moving windows and columns
+	{Name: "move-column-left", Category: "move", Since: "0.1.0", Description: "Move the focused column to the left"},
+	{Name: "move-column-right", Category: "move", Since: "0.1.0", Description: "Move the focused column to the right"},
+	{Name: "move-column-to-first", Category: "move", Since: "0.1.0", Description: "Move the focused column to the start"},
+	{Name: "move-column-to-last", Category: "move", Since: "0.1.0", Description: "Move the focused column to the end"},
+	{Name: "move-column-left-or-to-monitor-left", Category: "move", Since: "0.1.8", Description: "Move the column left, or to the monitor on the left"},
+	{Name: "move-column-right-or-to-monitor-right", Category: "move", Since: "0.1.8", Description: "Move the column right, or to the monitor on the right"},
+	{Name: "move-column-to-index", Category: "move", Since: "25.01", Description: "Move the focused column to an index",
+		Args: argIndex},
+	{Name: "move-window-down", Category: "move", Since: "0.1.0", Description: "Move the focused window down in its column"},
+	{Name: "move-window-up", Category: "move", Since: "0.1.0", Description: "Move the focused window up in its column"},
+	{Name: "move-window-down-or-to-workspace-down", Category: "move", Since: "0.1.2", Description: "Move the window down, or to the workspace below"},
+	{Name: "move-window-up-or-to-workspace-up", Category: "move", Since: "0.1.2", Description: "Move the window up, or to the workspace above"},
+	{Name: "consume-or-expel-window-left", Category: "move", Since: "0.1.2", Description: "Move the window into or out of the column to the left"},
+	{Name: "consume-or-expel-window-right", Category: "move", Since: "0.1.2", Description: "Move the window into or out of the column to the right"},
+	{Name: "consume-window-into-column", Category: "move", Since: "0.1.0", Description: "Pull the next column's window into the focused column"},
+	{Name: "expel-window-from-column", Category: "move", Since: "0.1.0", Description: "Move the focused window out into its own column"},
+	{Name: "swap-window-left", Category: "move", Since: "25.01", Description: "Swap the window with the one in the column to the left"},
+	{Name: "swap-window-right", Category: "move", Since: "25.01", Description: "Swap the window with the one in the column to the right"},
+	{Name: "toggle-column-tabbed-display", Category: "move", Since: "25.02", Description: "Toggle the column between tabs and a stack"},
+	{Name: "set-column-display", Category: "move", Since: "25.02", Description: "Show the column as tabs or a stack",
+		Args: []ArgSpec{{Name: "display", Kind: ArgChoice, Choices: []string{"normal", "tabbed"}}}},
+	{Name: "center-column", Category: "move", Since: "0.1.0", Description: "Center the focused column on screen"},
+	{Name: "center-window", Category: "move", Since: "25.01", Description: "Center the focused window on screen"},
+	{Name: "center-visible-columns", Category: "move", Since: "25.01", Description: "Center all fully visible columns on screen"},
+
+	// Workspaces
+	{Name: "focus-workspace-down", Category: "workspace", Since: "0.1.0", Description: "Switch to the workspace below"},
+	{Name: "focus-workspace-up", Category: "workspace", Since: "0.1.0", Description: "Switch to the workspace above"},
+	{Name: "focus-workspace", Category: "workspace", Since: "0.1.0", Description: "Switch to a workspace by index or name",
+		Args: argWorkspace},
+	{Name: "focus-workspace-previous", Category: "workspace", Since: "0.1.3", Description: "Switch to the previous workspace"},
+	{Name: "move-window-to-workspace-down", Category: "workspace", Since: "0.1.0", Description: "Move the focused window to the workspace below"},
+	{Name: "move-window-to-workspace-up", Category: "workspace", Since: "0.1.0", Description: "Move the focused window to the workspace above"},
+	{Name: "move-window-to-workspace", Category: "workspace", Since: "0.1.0", Description: "Move the focused window to a workspace",
+		Args: argWorkspace, Props: propFocus},
+	{Name: "move-column-to-workspace-down", Category: "workspace", Since: "0.1.0", Description: "Move the focused column to the workspace below"},
+	{Name: "move-column-to-workspace-up", Category: "workspace", Since: "0.1.0", Description: "Move the focused column to the workspace above"},
+	{Name: "move-column-to-workspace", Category: "workspace", Since: "0.1.0", Description: "Move the focused column to a workspace",
+		Args: argWorkspace, Props: propFocus},
+	{Name: "move-workspace-down", Category: "workspace", Since: "0.1.0", Description: "Move the focused workspace down"},
+	{Name: "move-workspace-up", Category: "workspace", Since: "0.1.0", Description: "Move the focused workspace up"},
+	{Name: "move-workspace-to-index", Category: "workspace", Since: "25.01", Description: "Move the focused workspace to an index",
+		Args: argIndex},
+	{Name: "set-workspace-name", Category: "workspace", Since: "25.01", Description: "Name the focused workspace",
+		Args: []ArgSpec{{Name: "name", Kind: ArgString}}},
+	{Name: "unset-workspace-name", Category: "workspace", Since: "25.01", Description: "Remove the focused workspace's name"},
+
+	// Monitors
+	{Name: "focus-monitor-left", Category: "monitor", Since: "0.1.0", Description: "Focus the monitor to the left"},
+	{Name: "focus-monitor-right", Category: "monitor", Since: "0.1.0", Description: "Focus the monitor to the right"},
+	{Name: "focus-monitor-down", Category: "monitor", Since: "0.1.0", Description: "Focus the monitor below"},
+	{Name: "focus-monitor-up", Category: "monitor", Since: "0.1.0", Description: "Focus the monitor above"},
+	{Name: "focus-monitor-previous", Category: "monitor", Since: "0.1.9", Description: "Focus the previous monitor"},
+	{Name: "focus-monitor-next", Category: "monitor", Since: "0.1.9", Description: "Focus the next monitor"},
+	{Name: "focus-monitor", Category: "monitor", Since: "25.01", Description: "Focus a monitor by name",
+		Args: argOutput},
+	{Name: "move-window-to-monitor-left", Category: "monitor", Since: "0.1.0", Description: "Move the focused window to the monitor to the left"},
+	{Name: "move-window-to-monitor-right", Category: "monitor", Since: "0.1.0", Description: "Move the focused window to the monitor to the right"},
+	{Name: "move-window-to-monitor-down", Category: "monitor", Since: "0.1.0", Description: "Move the focused window to the monitor below"},
+	{Name: "move-window-to-monitor-up", Category: "monitor", Since: "0.1.0", Description: "Move the focused window to the monitor above"},
+	{Name: "move-window-to-monitor-previous", Category: "monitor", Since: "0.1.9", Description: "Move the focused window to the previous monitor"},
+	{Name: "move-window-to-monitor-next", Category: "monitor", Since: "0.1.9", Description: "Move the focused window to the next monitor"},
+	{Name: "move-window-to-monitor", Category: "monitor", Since: "25.01", Description: "Move the focused window to a monitor by name",
+		Args: argOutput},
+	{Name: "move-column-to-monitor-left", Category: "monitor", Since: "0.1.0", Description: "Move the focused column to the monitor to the left"},
+	{Name: "move-column-to-monitor-right", Category: "monitor", Since: "0.1.0", Description: "Move the focused column to the monitor to the right"},
+	{Name: "move-column-to-monitor-down", Category: "monitor", Since: "0.1.0", Description: "Move the focused column to the monitor below"},
+	{Name: "move-column-to-monitor-up", Category: "monitor", Since: "0.1.0", Description: "Move the focused column to the monitor above"},
+	{Name: "move-column-to-monitor-previous", Category: "monitor", Since: "0.1.9", Description: "Move the focused column to the previous monitor"},
+	{Name: "move-column-to-monitor-next", Category: "monitor", Since: "0.1.9", Description: "Move the focused column to the next monitor"},
+	{Name: "move-column-to-monitor", Category: "monitor", Since: "25.01", Description: "Move the focused column to a monitor by name",
+		Args: argOutput},
+	{Name: "move-workspace-to-monitor-left", Category: "monitor", Since: "0.1.4", Description: "Move the focused workspace to the monitor to the left"},
+	{Name: "move-workspace-to-monitor-right", Category: "monitor", Since: "0.1.4", Description: "Move the focused workspace to the monitor to the right"},
+	{Name: "move-workspace-to-monitor-down", Category: "monitor", Since: "0.1.4", Description: "Move the focused workspace to the monitor below"},
+	{Name: "move-workspace-to-monitor-up", Category: "monitor", Since: "0.1.4", Description: "Move the focused workspace to the monitor above"},
+	{Name: "move-workspace-to-monitor-previous", Category: "monitor", Since: "0.1.9", Description: "Move the focused workspace to the previous monitor"},
+	{Name: "move-workspace-to-monitor-next", Category: "monitor", Since: "0.1.9", Description: "Move the focused workspace to the next monitor"},
+	{Name: "move-workspace-to-monitor", Category: "monitor", Since: "25.01", Description: "Move the focused workspace to a monitor by name",
+		Args: argOutput},
+
+	// Sizing
+	{Name: "set-window-width", Category: "size", Since: "25.01", Description: "Change the focused window's width",
+		Args: argSize},
+	{Name: "set-window-height", Category: "size", Since: "0.1.2", Description: "Change the focused window's height",
+		Args: argSize},
+	{Name: "reset-window-height", Category: "size", Since: "0.1.2", Description: "Reset the focused window's height"},
+	{Name: "switch-preset-column-width", Category: "size", Since: "0.1.0", Description: "Cycle the column through the preset widths"},
+	{Name: "switch-preset-column-width-back", Category: "size", Since: "25.05", Description: "Cycle the column through the preset widths backwards"},
+	{Name: "switch-preset-window-width", Category: "size", Since: "25.01", Description: "Cycle the window through the preset widths"},
+	{Name: "switch-preset-window-width-back", Category: "size", Since: "25.05", Description: "Cycle the window through the preset widths backwards"},
+	{Name: "switch-preset-window-height", Category: "size", Since: "0.1.9", Description: "Cycle the window through the preset heights"},
+	{Name: "switch-preset-window-height-back", Category: "size", Since: "25.05", Description: "Cycle the window through the preset heights backwards"},
+	{Name: "maximize-column", Category: "size", Since: "0.1.0", Description: "Make the focused column full width"},
+	{Name: "maximize-window-to-edges", Category: "size", Since: "25.08", Description: "Maximize the focused window to the screen edges"},
+	{Name: "set-column-width", Category: "size", Since: "0.1.0", Description: "Change the focused column's width",
+		Args: argSize},
+	{Name: "expand-column-to-available-width", Category: "size", Since: "25.02", Description: "Grow the column to fill the free space on screen"},
+
+	// Floating
+	{Name: "toggle-window-floating", Category: "floating", Since: "25.01", Description: "Move the window between floating and tiling"},
+	{Name: "move-window-to-floating", Category: "floating", Since: "25.01", Description: "Make the focused window float"},
+	{Name: "move-window-to-tiling", Category: "floating", Since: "25.01", Description: "Tile the focused window"},
+	{Name: "focus-floating", Category: "floating", Since: "25.01", Description: "Focus the floating layer"},
+	{Name: "focus-tiling", Category: "floating", Since: "25.01", Description: "Focus the tiling layer"},
+	{Name: "switch-focus-between-floating-and-tiling", Category: "floating", Since: "25.01", Description: "Toggle focus between the floating and tiling layers"},
+	{Name: "move-floating-window", Category: "floating", Since: "25.01", Description: "Move the focused floating window",
+		Props: []PropSpec{{Name: "x", Kind: ArgSizeChange}, {Name: "y", Kind: ArgSizeChange}}},
+	{Name: "toggle-window-rule-opacity", Category: "floating", Since: "25.02", Description: "Toggle the opacity set by window rules"},
+}
+
+// Actions returns the catalog of niri actions
+func Actions() []Action {
+	return actions
+}
+
+// LookupAction returns the catalog entry for an action
+func LookupAction(name string) (*Action, bool) {
+	i := slices.IndexFunc(actions, func(a Action) bool { return a.Name == name })
+	if i < 0 {
+		return nil, false
+	}
+	return &actions[i], true
+}
+
+// IsKnownAction reports whether niri has an action with this name
+func IsKnownAction(name string) bool {
+	_, ok := LookupAction(name)
+	return ok
+}
+
+// ActionCompletions returns the action names starting with prefix
+func ActionCompletions(prefix string) []string {
+	var out []string
+	for _, a := range actions {
+		if strings.HasPrefix(a.Name, prefix) {
+			out = append(out, a.Name)
+		}
+	}
+	return out
+}
+
+// Usage describes how the action is written, e.g.
+// `move-window-to-workspace <index|name> [focus=<bool>]`
+func (a *Action) Usage() string {
+	parts := []string{a.Name}
+	for _, arg := range a.Args {
+		s := "<" + arg.Name + ">"
+		switch arg.Kind {
+		case ArgChoice:
+			s = strings.Join(arg.Choices, "|")
+		case ArgLayout:
+			s = "next|prev|<index>"
+		}
+		if arg.Repeated {
+			s += "..."
+		}
+		if arg.Optional {
+			s = "[" + s + "]"
+		}
+		parts = append(parts, s)
+	}
+	for _, p := range a.Props {
+		parts = append(parts, fmt.Sprintf("[%s=<%s>]", p.Name, p.Kind))
+	}
+	return strings.Join(parts, " ")
+}
+
+// CheckArgs reports the first argument or property that doesn't fit
+// the action
+func (a *Action) CheckArgs(args []Value, props []Prop) error {
+	i := 0
+	for _, spec := range a.Args {
+		if i >= len(args) {
+			if !spec.Optional {
+				return fmt.Errorf("%s needs a %s argument", a.Name, spec.Name)
+			}
+			break
+		}
+		for {
+			if err := checkArg(spec.Kind, spec.Choices, args[i]); err != nil {
+				return fmt.Errorf("%s: %s %w", a.Name, spec.Name, err)
+			}
+			i++
+			if !spec.Repeated || i >= len(args) {
+				break
+			}
+		}
+	}
+	if i < len(args) {
+		return fmt.Errorf("%s takes %d argument(s), got %d", a.Name, i, len(args))
+	}
+
+	for _, p := range props {
+		j := slices.IndexFunc(a.Props, func(s PropSpec) bool { return s.Name == p.Key })
+		if j < 0 {
+			return fmt.Errorf("%s has no %s property", a.Name, p.Key)
+		}
+		if err := checkArg(a.Props[j].Kind, nil, p.Value); err != nil {
+			return fmt.Errorf("%s: %s %w", a.Name, p.Key, err)
+		}
+	}
+	return nil
+}
+
+// sizeChangeRe matches niri's size changes: an optional sign, a number
+// and an optional percent sign
+var sizeChangeRe = regexp.MustCompile(`^[+-]?\d+(\.\d+)?%?$`)
+
+// layoutIndexRe matches a keyboard layout index, which niri takes as a
+// string argument
+var layoutIndexRe = regexp.MustCompile(`^\d+$`)
+
+// checkArg checks a value against an argument kind
+func checkArg(kind ArgKind, choices []string, v Value) error {
+	switch kind {
+	case ArgString:
+		if v.Kind != KindString {
+			return fmt.Errorf("must be a string")
+		}
+	case ArgInt:
+		if v.Kind != KindInt {
+			return fmt.Errorf("must be an integer")
+		}
+	case ArgBool:
+		if v.Kind != KindBool {
+			return fmt.Errorf("must be true or false")
+		}
+	case ArgWorkspace:
+		if v.Kind != KindString && v.Kind != KindInt {
+			return fmt.Errorf("must be a workspace index or name")
+		}
+	case ArgSizeChange:
+		if v.Kind != KindString || !sizeChangeRe.MatchString(v.Str) {
+			return fmt.Errorf(`must be a size like "+10%%", "-50" or "50%%"`)
+		}
+	case ArgChoice:
+		if v.Kind != KindString || !slices.Contains(choices, v.Str) {
+			return fmt.Errorf("must be one of %s", strings.Join(choices, ", "))
+		}
+	case ArgLayout:
+		if v.Kind != KindString || (v.Str != "next" && v.Str != "prev" && !layoutIndexRe.MatchString(v.Str)) {
+			return fmt.Errorf(`must be "next", "prev" or a layout index`)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package config
+
+import "math"
+
+// AnimationKind says what drives an animation
+type AnimationKind int
+
+const (
+	AnimationSpring AnimationKind = iota
+	AnimationEasing
+)
+
+func (k AnimationKind) String() string {
+	if k == AnimationEasing {
+		return "Easing"
+	}
+	return "Spring"
+}
+
+// Easing curves niri knows, in the order the editor cycles through them
+var EasingCurves = []string{"linear", "ease-out-quad", "ease-out-cubic", "ease-out-expo", "cubic-bezier"}
+
+// Spring holds the parameters of a spring animation
+type Spring struct {
+	DampingRatio float64
+	Stiffness    float64 // written as an integer, niri takes a u32
+	Epsilon      float64
+}
+
+// Easing holds the parameters of a fixed-duration animation
+type Easing struct {
+	DurationMs int
+	Curve      string
+	Bezier     [4]float64 // x1 y1 x2 y2 of a cubic-bezier curve
+}
+
+// AnimationParams is how one animation is set up. Both Spring and Easing
+// are always filled in, Kind says which one is used.
+type AnimationParams struct {
+	Off    bool
+	Kind   AnimationKind
+	Spring Spring
+	Easing Easing
+}
+
+// Animation is one animation in the animations block, such as
+// workspace-switch
+type Animation struct {
+	Name string
+	AnimationParams
+	CustomShader string // GLSL source, dedented; "" for niri's built-in effect
+
+	saved       AnimationParams // values as last loaded or saved
+	savedShader string
+}
+
+// Niri's own defaults, used for animations the config doesn't set
+var (
+	defaultSpring = Spring{DampingRatio: 1, Stiffness: 800, Epsilon: 0.0001}
+	defaultEasing = Easing{DurationMs: 250, Curve: "ease-out-cubic", Bezier: [4]float64{0.25, 0.1, 0.25, 1}}
+)
+
+// animationDefaults lists every animation niri has with its default
+// parameters, in the order niri documents them
+var animationDefaults = []struct {
+	name   string
+	params AnimationParams
+}{
+	{"workspace-switch", springParams(1, 1000, 0.0001)},
+	{"window-open", easingParams(150, "ease-out-expo")},
+	{"window-close", easingParams(150, "ease-out-quad")},
+	{"horizontal-view-movement", springParams(1, 800, 0.0001)},
+	{"window-movement", springParams(1, 800, 0.0001)},
+	{"window-resize", springParams(1, 800, 0.0001)},
+	{"config-notification-open-close", springParams(0.6, 1000, 0.001)},
+	{"screenshot-ui-open", easingParams(200, "ease-out-quad")},
+	{"overview-open-close", springParams(1, 900, 0.0001)},
+}
+
+func springParams(dampingRatio, stiffness, epsilon float64) AnimationParams {
+	return AnimationParams{
+		Kind:   AnimationSpring,
+		Spring: Spring{DampingRatio: dampingRatio, Stiffness: stiffness, Epsilon: epsilon},
+		Easing: defaultEasing,
+	}
+}
+
+func easingParams(durationMs int, curve string) AnimationParams {
+	easing := defaultEasing
+	easing.DurationMs, easing.Curve = durationMs, curve
+	return AnimationParams{Kind: AnimationEasing, Spring: defaultSpring, Easing: easing}
+}
+
+// DefaultAnimations returns every animation with niri's defaults
+func DefaultAnimations() []*Animation {
+	animations := make([]*Animation, len(animationDefaults))
+	for i, d := range animationDefaults {
+		animations[i] = &Animation{Name: d.name, AnimationParams: d.params, saved: d.params}
+	}
+	return animations
+}
+
+// DefaultAnimationParams returns niri's default for an animation
+func DefaultAnimationParams(name string) AnimationParams {
+	for _, d := range animationDefaults {
+		if d.name == name {
+			return d.params
+		}
+	}
+	return springParams(defaultSpring.DampingRatio, defaultSpring.Stiffness, defaultSpring.Epsilon)
+}
+
+// Animation returns the named animation, or nil
+func (c *NiriConfig) Animation(name string) *Animation {
+	for _, a := range c.Animations {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// AnimationPreset is a named set of animation settings. Presets scale
+// niri's defaults: springs get stiffer or softer, easings shorter or
+// longer.
+type AnimationPreset struct {
+	Name        string
+	Description string
+	Off         bool
+
+	stiffness float64 // factor applied to spring stiffness
+	duration  float64 // factor applied to easing durations
+}
+
+var animationPresets = []AnimationPreset{
+	{Name: "Default", Description: "niri's own animations", stiffness: 1, duration: 1},
+	{Name: "Smooth", Description: "slower, gentler motion", stiffness: 0.5, duration: 1.6},
+	{Name: "Snappy", Description: "quick and crisp", stiffness: 1.8, duration: 0.6},
+	{Name: "None", Description: "animations off", Off: true},
+}
+
+// AnimationPresets returns the built-in presets
+func AnimationPresets() []AnimationPreset {
+	return animationPresets
+}
+
+// params returns the preset's parameters for an animation
+func (p AnimationPreset) params(name string) AnimationParams {
+	params := DefaultAnimationParams(name)
+	if params.Kind == AnimationSpring {
+		params.Spring.Stiffness = math.Round(params.Spring.Stiffness * p.stiffness)
+	} else {
+		params.Easing.DurationMs = int(math.Round(float64(params.Easing.DurationMs) * p.duration))
+	}
+	return params
+}
+
+// ApplyAnimationPreset sets every animation from a preset. The None
+// preset only turns animations off, so the fine tuning survives turning
+// them back on.
+func (c *NiriConfig) ApplyAnimationPreset(p AnimationPreset) {
+	c.AnimationsOff = p.Off
+	if p.Off {
+		return
+	}
+	c.AnimationSlowdown = 1
+	for _, a := range c.Animations {
+		a.AnimationParams = p.params(a.Name)
+	}
+}
+
+// ActiveAnimationPreset returns the name of the preset the current
+// settings match, or "" if they are tuned by hand
+func (c *NiriConfig) ActiveAnimationPreset() string {
+	for _, p := range animationPresets {
+		if p.Off {
+			if c.AnimationsOff {
+				return p.Name
+			}
+			continue
+		}
+		if c.AnimationsOff || c.AnimationSlowdown != 1 {
+			continue
+		}
+		matches := true
+		for _, a := range c.Animations {
+			if a.AnimationParams != p.params(a.Name) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// loadAnimations reads the animations blocks. Later blocks override
+// earlier ones, as in niri.
+func (c *NiriConfig) loadAnimations(t *configTree) {
+	for _, block := range t.NodesNamed("animations") {
+		if flagState(block, "off") == FlagPresent {
+			c.AnimationsOff = true
+		}
+		if val, ok := block.FloatArg("slowdown"); ok {
+			c.AnimationSlowdown = val
+		}
+		for _, a := range c.Animations {
+			for _, n := range block.ChildrenNamed(a.Name) {
+				a.load(n)
+			}
+		}
+	}
+	for _, a := range c.Animations {
+		a.saved = a.AnimationParams
+		a.savedShader = a.CustomShader
+	}
+}
+
+// load reads an animation's block
+func (a *Animation) load(n *Node) {
+	if flagState(n, "off") == FlagPresent {
+		a.Off = true
+	}
+
+	if spring := n.Child("spring"); spring != nil {
+		a.Kind = AnimationSpring
+		if v, ok := spring.Prop("damping-ratio"); ok {
+			a.Spring.DampingRatio, _ = v.AsFloat()
+		}
+		if v, ok := spring.Prop("stiffness"); ok {
+			a.Spring.Stiffness, _ = v.AsFloat()
+		}
+		if v, ok := spring.Prop("epsilon"); ok {
+			a.Spring.Epsilon, _ = v.AsFloat()
+		}
+	}
+
+	if n.HasChild("duration-ms") || n.HasChild("curve") {
+		a.Kind = AnimationEasing
+		if val, ok := n.IntArg("duration-ms"); ok {
+			a.Easing.DurationMs = val
+		}
+		if curve := n.Child("curve"); curve != nil {
+			args := curve.Args()
+			if len(args) > 0 {
+				a.Easing.Curve, _ = args[0].AsString()
+			}
+			for i := 0; i < 4 && i+1 < len(args); i++ {
+				a.Easing.Bezier[i], _ = args[i+1].AsFloat()
+			}
+		}
+	}
+
+	if shader := n.Child("custom-shader"); shader != nil {
+		if v, ok := shader.Arg(0); ok {
+			src, _ := v.AsString()
+			a.CustomShader = dedentShader(src)
+		}
+	}
+}
+
+// writeAnimations applies the animation settings that differ from the
+// saved state
+func (c *NiriConfig) writeAnimations(t *configTree) {
+	base := c.base
+
+	if c.AnimationsOff != base.AnimationsOff {
+		t.setFlag(c.AnimationsOff, "off", "animations")
+	}
+	if c.AnimationSlowdown != base.AnimationSlowdown {
+		t.ensure("animations", "slowdown").SetArgs(FloatValue(c.AnimationSlowdown))
+	}
+
+	for _, a := range c.Animations {
+		if a.AnimationParams == a.saved && a.CustomShader == a.savedShader {
+			continue
+		}
+		n := t.ensure("animations", a.Name)
+		if a.Off != a.saved.Off {
+			setFlag(n, "off", a.Off)
+		}
+		if a.Kind != a.saved.Kind || a.Spring != a.saved.Spring || a.Easing != a.saved.Easing {
+			a.writeCurve(n)
+		}
+		if a.CustomShader != a.savedShader {
+			a.writeShader(n)
+		}
+		a.saved = a.AnimationParams
+		a.savedShader = a.CustomShader
+	}
+}
+
+// writeCurve writes the spring or easing of an animation, dropping the
+// nodes of the other kind
+func (a *Animation) writeCurve(n *Node) {
+	if a.Kind == AnimationSpring {
+		for _, c := range append(n.ChildrenNamed("duration-ms"), n.ChildrenNamed("curve")...) {
+			n.RemoveChild(c)
+		}
+		spring := n.EnsureChild("spring")
+		spring.SetProp("damping-ratio", FloatValue(a.Spring.DampingRatio))
+		spring.SetProp("stiffness", IntValue(int(math.Round(a.Spring.Stiffness))))
+		spring.SetProp("epsilon", FloatValue(a.Spring.Epsilon))
+		return
+	}
+
+	for _, c := range n.ChildrenNamed("spring") {
+		n.RemoveChild(c)
+	}
+	n.EnsureChild("duration-ms").SetArgs(IntValue(a.Easing.DurationMs))
+	curve := []Value{StringValue(a.Easing.Curve)}
+	if a.Easing.Curve == "cubic-bezier" {
+		for _, p := range a.Easing.Bezier {
+			curve = append(curve, FloatValue(p))
+		}
+	}
+	n.EnsureChild("curve").SetArgs(curve...)
+}
@@ -0,0 +1,364 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// KeyChord is the trigger of a bind: modifiers and a key joined with
+// '+', e.g. Mod+Shift+Slash, XF86AudioMute or Mod+WheelScrollDown
+type KeyChord struct {
+	Modifiers []string // as written, e.g. "Mod", "Ctrl", "Super"
+	Key       string   // xkb keysym name or a mouse/wheel/touchpad trigger
+}
+
+// modifierNames maps every modifier spelling niri accepts to its
+// canonical name
+var modifierNames = map[string]string{
+	"mod":              "Mod",
+	"ctrl":             "Ctrl",
+	"control":          "Ctrl",
+	"shift":            "Shift",
+	"alt":              "Alt",
+	"super":            "Super",
+	"win":              "Super",
+	"iso_level3_shift": "ISO_Level3_Shift",
+	"mod5":             "ISO_Level3_Shift",
+	"iso_level5_shift": "ISO_Level5_Shift",
+}
+
+// pointerTriggers are the non-keyboard keys a bind can use
+var pointerTriggers = []string{
+	"MouseLeft", "MouseRight", "MouseMiddle", "MouseBack", "MouseForward",
+	"WheelScrollDown", "WheelScrollUp", "WheelScrollLeft", "WheelScrollRight",
+	"TouchpadScrollDown", "TouchpadScrollUp", "TouchpadScrollLeft", "TouchpadScrollRight",
+}
+
+// ParseKeyChord splits a chord into modifiers and key. It accepts
+// anything; use Validate to check the modifiers.
+func ParseKeyChord(s string) KeyChord {
+	parts := strings.Split(s, "+")
+	// A trailing '+' means the key itself is the plus sign
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+		parts[len(parts)-1] += "+"
+	}
+	return KeyChord{
+		Modifiers: parts[:len(parts)-1],
+		Key:       parts[len(parts)-1],
+	}
+}
+
+func (k KeyChord) String() string {
+	return strings.Join(append(slices.Clone(k.Modifiers), k.Key), "+")
+}
+
+// Validate checks that the chord has a key and only known modifiers
+func (k KeyChord) Validate() error {
+	if k.Key == "" {
+		return fmt.Errorf("chord %q has no key", k.String())
+	}
+	for _, m := range k.Modifiers {
+		if !IsModifier(m) {
+			return fmt.Errorf("unknown modifier %q in %q", m, k.String())
+		}
+	}
+	if !k.IsPointer() && !IsKeysym(k.Key) {
+		return fmt.Errorf("unknown key %q in %q", k.Key, k.String())
+	}
+	return nil
+}
+
+// IsPointer reports whether the chord is triggered by a mouse button,
+// scroll wheel or touchpad scroll rather than a key
+func (k KeyChord) IsPointer() bool {
+	return slices.ContainsFunc(pointerTriggers, func(t string) bool {
+		return strings.EqualFold(t, k.Key)
+	})
+}
+
+// IsModifier reports whether niri accepts name as a modifier
+func IsModifier(name string) bool {
+	_, ok := modifierNames[strings.ToLower(name)]
+	return ok
+}
+
+// Modifiers returns the modifier names a chord can use, in the order
+// niri's own config writes them
+func Modifiers() []string {
+	return []string{"Mod", "Super", "Ctrl", "Alt", "Shift", "ISO_Level3_Shift", "ISO_Level5_Shift"}
+}
+
+// Prop is a named property value
+type Prop struct {
+	Key   string
+	Value Value
+}
+
+// Bind is one entry of the binds block
+type Bind struct {
+	Chord       KeyChord
+	Action      string
+	Args        []Value
+	ActionProps []Prop // e.g. focus=false on move-window-to-workspace
+
+	AllowWhenLocked     bool
+	AllowInhibiting     bool // niri default: true
+	Repeat              bool // niri default: true
+	CooldownMs          int  // 0 means no cooldown
+	HotkeyOverlayTitle  string
+	HotkeyOverlayHidden bool // hotkey-overlay-title=null
+
+	// Comments are the line comments directly above the bind
+	Comments []string
+
+	node  *Node  // nil for binds added since loading
+	block *Node  // binds block the node lives in
+	file  string // file defining the block
+}
+
+// Location returns where the bind is defined as file:line:col, or ""
+// for binds that haven't been saved yet
+func (b *Bind) Location() string {
+	if b.node == nil || b.file == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s", b.file, b.node.Pos)
+}
+
+// NewBind creates a bind with niri's default properties
+func NewBind(chord KeyChord, action string, args ...Value) *Bind {
+	return &Bind{
+		Chord:           chord,
+		Action:          action,
+		Args:            args,
+		AllowInhibiting: true,
+		Repeat:          true,
+	}
+}
+
+// ActionString formats the action and its arguments as written in KDL,
+// e.g. `spawn "alacritty"`
+func (b *Bind) ActionString() string {
+	if args := b.ArgsString(); args != "" {
+		return b.Action + " " + args
+	}
+	return b.Action
+}
+
+// ArgsString formats the action's arguments and properties as written
+// in KDL
+func (b *Bind) ArgsString() string {
+	var parts []string
+	for _, arg := range b.Args {
+		parts = append(parts, arg.String())
+	}
+	for _, p := range b.ActionProps {
+		parts = append(parts, formatIdent(p.Key)+"="+p.Value.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseArgs parses KDL node entries such as `"foot" "-e" focus=false`
+// into arguments and properties
+func ParseArgs(s string) ([]Value, []Prop, error) {
+	doc, err := ParseKDL([]byte("action " + s))
+	if err != nil {
+		if perr, ok := err.(*ParseError); ok {
+			return nil, nil, fmt.Errorf("invalid arguments: %s", perr.Msg)
+		}
+		return nil, nil, err
+	}
+	if len(doc.Nodes) != 1 || len(doc.Nodes[0].Children) > 0 {
+		return nil, nil, fmt.Errorf("invalid arguments: expected values only")
+	}
+
+	var args []Value
+	var props []Prop
+	for _, e := range doc.Nodes[0].Entries {
+		if e.Key == "" {
+			args = append(args, e.Value)
+		} else {
+			props = append(props, Prop{Key: e.Key, Value: e.Value})
+		}
+	}
+	return args, props, nil
+}
+
+// parseBind reads a bind from its node in a binds block
+func parseBind(node, block *Node) *Bind {
+	b := NewBind(ParseKeyChord(node.Name), "")
+	b.node, b.block = node, block
+	b.Comments = node.Comments()
+
+	if v, ok := node.Prop("allow-when-locked"); ok {
+		b.AllowWhenLocked = v.Bool
+	}
+	if v, ok := node.Prop("allow-inhibiting"); ok {
+		b.AllowInhibiting = v.Bool
+	}
+	if v, ok := node.Prop("repeat"); ok {
+		b.Repeat = v.Bool
+	}
+	if v, ok := node.Prop("cooldown-ms"); ok {
+		b.CooldownMs, _ = v.AsInt()
+	}
+	if v, ok := node.Prop("hotkey-overlay-title"); ok {
+		b.HotkeyOverlayTitle = v.Str
+		b.HotkeyOverlayHidden = v.Kind == KindNull
+	}
+
+	if len(node.Children) > 0 {
+		action := node.Children[0]
+		b.Action = action.Name
+		for _, e := range action.Entries {
+			if e.Key == "" {
+				b.Args = append(b.Args, e.Value)
+			} else {
+				b.ActionProps = append(b.ActionProps, Prop{Key: e.Key, Value: e.Value})
+			}
+		}
+	}
+	return b
+}
+
+// AddBind adds a bind; it is written to the last binds block on save
+func (c *NiriConfig) AddBind(b *Bind) {
+	c.Binds = append(c.Binds, b)
+}
+
+// RemoveBind removes a bind; its node is deleted on save
+func (c *NiriConfig) RemoveBind(b *Bind) {
+	i := slices.Index(c.Binds, b)
+	if i < 0 {
+		return
+	}
+	c.Binds = slices.Delete(c.Binds, i, i+1)
+	if b.node != nil {
+		c.removedBinds = append(c.removedBinds, b)
+	}
+}
+
+// loadBinds collects the binds from every binds block, in the order
+// niri reads them
+func (c *NiriConfig) loadBinds(t *configTree) {
+	c.Binds = nil
+	c.removedBinds = nil
+	for _, block := range t.NodesNamed("binds") {
+		var file string
+		if f := t.fileOf(block); f != nil {
+			file = f.Path
+		}
+		for _, node := range block.Children {
+			b := parseBind(node, block)
+			b.file = file
+			c.Binds = append(c.Binds, b)
+		}
+	}
+
+	c.ModKey = "Super"
+	if keys := t.lookup("input", "mod-key"); len(keys) > 0 {
+		if v, ok := keys[len(keys)-1].Arg(0); ok && v.Kind == KindString {
+			c.ModKey = v.Str
+		}
+	}
+}
+
+// writeBinds syncs every bind to its node, touching only what changed
+func (c *NiriConfig) writeBinds(t *configTree) {
+	for _, b := range c.removedBinds {
+		b.block.RemoveChild(b.node)
+	}
+	c.removedBinds = nil
+
+	for _, b := range c.Binds {
+		if b.node == nil {
+			b.block = t.ensure("binds")
+			if f := t.fileOf(b.block); f != nil {
+				b.file = f.Path
+			}
+			b.node = NewNode(b.Chord.String())
+			b.block.AppendChild(b.node)
+			b.node.AppendInlineChild(NewNode(b.Action))
+		}
+		b.sync()
+	}
+}
+
+// sync writes the bind's fields to its node
+func (b *Bind) sync() {
+	n := b.node
+	if chord := b.Chord.String(); n.Name != chord {
+		n.Name = chord
+	}
+
+	syncBoolProp(n, "allow-when-locked", b.AllowWhenLocked, false)
+	syncBoolProp(n, "allow-inhibiting", b.AllowInhibiting, true)
+	syncBoolProp(n, "repeat", b.Repeat, true)
+
+	if b.CooldownMs > 0 {
+		if v, ok := n.Prop("cooldown-ms"); !ok || v.Int != int64(b.CooldownMs) {
+			n.SetProp("cooldown-ms", IntValue(b.CooldownMs))
+		}
+	} else {
+		n.RemoveProp("cooldown-ms")
+	}
+
+	v, ok := n.Prop("hotkey-overlay-title")
+	switch {
+	case b.HotkeyOverlayHidden:
+		if !ok || v.Kind != KindNull {
+			n.SetProp("hotkey-overlay-title", Value{Kind: KindNull})
+		}
+	case b.HotkeyOverlayTitle != "":
+		if !ok || v.Str != b.HotkeyOverlayTitle {
+			n.SetProp("hotkey-overlay-title", StringValue(b.HotkeyOverlayTitle))
+		}
+	default:
+		n.RemoveProp("hotkey-overlay-title")
+	}
+
+	if len(n.Children) == 0 {
+		n.AppendInlineChild(NewNode(b.Action))
+	}
+	action := n.Children[0]
+	if action.Name != b.Action {
+		action.Name = b.Action
+	}
+	if !slices.Equal(action.Args(), b.Args) {
+		action.SetArgs(b.Args...)
+	}
+	// RemoveProp compacts Entries, so collect the stale keys first
+	var stale []string
+	for _, e := range action.Entries {
+		if e.Key != "" && !slices.ContainsFunc(b.ActionProps, func(p Prop) bool { return p.Key == e.Key }) {
+			stale = append(stale, e.Key)
+		}
+	}
+	for _, key := range stale {
+		action.RemoveProp(key)
+	}
+	for _, p := range b.ActionProps {
+		if v, ok := action.Prop(p.Key); !ok || v != p.Value {
+			action.SetProp(p.Key, p.Value)
+		}
+	}
+
+	if !slices.Equal(n.Comments(), b.Comments) {
+		n.SetComments(b.Comments)
+	}
+}
+
+// syncBoolProp sets a boolean property, leaving it out when it has the
+// default value and wasn't written explicitly
+func syncBoolProp(n *Node, key string, want, def bool) {
+	v, ok := n.Prop(key)
+	switch {
+	case ok && v.Kind == KindBool && v.Bool == want:
+	case want == def:
+		n.RemoveProp(key)
+	default:
+		n.SetProp(key, BoolValue(want))
+	}
+}
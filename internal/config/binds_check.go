@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Severity says how bad a bind problem is
+type Severity int
+
+const (
+	// SeverityWarning problems load but probably don't do what was meant
+	SeverityWarning Severity = iota
+	// SeverityError problems make niri reject the config
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// BindProblem is an issue found in a bind
+type BindProblem struct {
+	Bind     *Bind
+	Severity Severity
+	Msg      string
+}
+
+func (p BindProblem) String() string {
+	if loc := p.Bind.Location(); loc != "" {
+		return fmt.Sprintf("%s: %s: %s", loc, p.Severity, p.Msg)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Bind.Chord, p.Severity, p.Msg)
+}
+
+// CheckBinds reports duplicate and shadowed chords, unknown actions,
+// arguments that don't fit the action and spawn binds whose program
+// can't be found
+func (c *NiriConfig) CheckBinds() []BindProblem {
+	var problems []BindProblem
+	add := func(b *Bind, sev Severity, format string, args ...any) {
+		problems = append(problems, BindProblem{Bind: b, Severity: sev, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	written := map[string]*Bind{}  // chords as niri parses them
+	resolved := map[string]*Bind{} // chords with Mod resolved to ModKey
+	for _, b := range c.Binds {
+		if err := b.Chord.Validate(); err != nil {
+			add(b, SeverityError, "%v", err)
+			continue
+		}
+
+		if first, ok := written[b.Chord.normalize("")]; ok {
+			add(b, SeverityError, "duplicate keybind %s%s", b.Chord, where(first))
+		} else if first, ok := resolved[b.Chord.normalize(c.ModKey)]; ok {
+			add(b, SeverityWarning, "%s is the same chord as %s when Mod is %s%s",
+				b.Chord, first.Chord, c.ModKey, where(first))
+		}
+		if _, ok := written[b.Chord.normalize("")]; !ok {
+			written[b.Chord.normalize("")] = b
+		}
+		if _, ok := resolved[b.Chord.normalize(c.ModKey)]; !ok {
+			resolved[b.Chord.normalize(c.ModKey)] = b
+		}
+
+		if b.Action == "" {
+			add(b, SeverityError, "%s has no action", b.Chord)
+			continue
+		}
+		action, ok := LookupAction(b.Action)
+		if !ok {
+			add(b, SeverityError, "unknown action %q", b.Action)
+			continue
+		}
+		if err := action.CheckArgs(b.Args, b.ActionProps); err != nil {
+			add(b, SeverityError, "%v", err)
+			continue
+		}
+		if b.Action == "spawn" {
+			if prog, ok := spawnProgram(b); ok && !programExists(prog) {
+				add(b, SeverityWarning, "%q not found on PATH", prog)
+			}
+		}
+	}
+	return problems
+}
+
+// ProblemsFor returns the problems reported for one bind
+func ProblemsFor(problems []BindProblem, b *Bind) []BindProblem {
+	var out []BindProblem
+	for _, p := range problems {
+		if p.Bind == b {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// where formats a bind's location for messages, if it has one
+func where(b *Bind) string {
+	if loc := b.Location(); loc != "" {
+		return ", already bound at " + loc
+	}
+	return ""
+}
+
+// normalize returns a comparison key for the chord: canonical modifier
+// names in a fixed order and the key folded to lower case, as xkb
+// matches keysym names case-insensitively. With a non-empty modKey, Mod
+// is replaced by that key.
+func (k KeyChord) normalize(modKey string) string {
+	var mods []string
+	for _, m := range k.Modifiers {
+		name := modifierNames[strings.ToLower(m)]
+		if name == "Mod" && modKey != "" {
+			if mod, ok := modifierNames[strings.ToLower(modKey)]; ok {
+				name = mod
+			}
+		}
+		if !slices.Contains(mods, name) {
+			mods = append(mods, name)
+		}
+	}
+	slices.Sort(mods)
+	return strings.Join(append(mods, strings.ToLower(k.Key)), "+")
+}
+
+// spawnProgram returns the program a spawn bind runs
+func spawnProgram(b *Bind) (string, bool) {
+	if len(b.Args) == 0 || b.Args[0].Kind != KindString {
+		return "", false
+	}
+	return b.Args[0].Str, b.Args[0].Str != ""
+}
+
+// programExists reports whether niri could run prog: a path to an
+// executable, or a name found on PATH
+func programExists(prog string) bool {
+	if rest, ok := strings.CutPrefix(prog, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		prog = filepath.Join(home, rest)
+	}
+	_, err := exec.LookPath(prog)
+	return err == nil
+}
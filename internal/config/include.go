@@ -0,0 +1,264 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ConfigFile is one file of a niri config: the main file or a file
+// pulled in with an `include` node
+type ConfigFile struct {
+	Path string
+	Doc  *Document
+
+	saved string // content on disk, used to skip unchanged files
+}
+
+// configTree is a main config file together with everything it
+// includes. Its top-level nodes are seen as one list in include order,
+// which is the order niri applies them in.
+type configTree struct {
+	files    []*ConfigFile          // main file first
+	byPath   map[string]*ConfigFile // keyed by absolute path
+	includes map[*Node]*ConfigFile  // include node -> included file
+}
+
+// loadTree reads a config file and, recursively, the files it includes
+func loadTree(path string) (*configTree, error) {
+	t := &configTree{
+		byPath:   map[string]*ConfigFile{},
+		includes: map[*Node]*ConfigFile{},
+	}
+	if _, err := t.load(path, nil); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// load parses one file and follows its includes. stack holds the files
+// currently being loaded, to detect include cycles.
+func (t *configTree) load(path string, stack []string) (*ConfigFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range stack {
+		if p == abs {
+			cycle := append(append([]string{}, stack[i:]...), abs)
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if f, ok := t.byPath[abs]; ok {
+		return f, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseKDL(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+
+	f := &ConfigFile{Path: path, Doc: doc, saved: string(content)}
+	t.files = append(t.files, f)
+	t.byPath[abs] = f
+
+	stack = append(stack, abs)
+	for _, node := range doc.NodesNamed("include") {
+		target, ok := node.Arg(0)
+		if !ok || target.Kind != KindString {
+			return nil, fmt.Errorf("%s:%s: include needs a path argument", path, node.Pos)
+		}
+		incPath := resolveInclude(path, target.Str)
+
+		inc, err := t.load(incPath, stack)
+		if err != nil {
+			optional, _ := node.Prop("optional")
+			if optional.Bool && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("%s:%s: include %q: %w", path, node.Pos, target.Str, err)
+		}
+		t.includes[node] = inc
+	}
+	return f, nil
+}
+
+// resolveInclude resolves an include path relative to the including file
+func resolveInclude(from, target string) string {
+	if strings.HasPrefix(target, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		return filepath.Join(homeDir, target[2:])
+	}
+	if filepath.IsAbs(target) {
+		return target
+	}
+	return filepath.Join(filepath.Dir(from), target)
+}
+
+// clone returns a deep copy of the tree, recording in nodes which copy
+// stands for which original node
+func (t *configTree) clone(nodes map[*Node]*Node) *configTree {
+	c := &configTree{
+		byPath:   map[string]*ConfigFile{},
+		includes: map[*Node]*ConfigFile{},
+	}
+	files := map[*ConfigFile]*ConfigFile{}
+	for _, f := range t.files {
+		cf := &ConfigFile{Path: f.Path, Doc: f.Doc.clone(nodes), saved: f.saved}
+		files[f] = cf
+		c.files = append(c.files, cf)
+	}
+	for path, f := range t.byPath {
+		c.byPath[path] = files[f]
+	}
+	for n, f := range t.includes {
+		c.includes[nodes[n]] = files[f]
+	}
+	return c
+}
+
+// main returns the file the tree was loaded from
+func (t *configTree) main() *ConfigFile {
+	return t.files[0]
+}
+
+// fileOf returns the file defining a top-level node
+func (t *configTree) fileOf(n *Node) *ConfigFile {
+	for _, f := range t.files {
+		if slices.Contains(f.Doc.Nodes, n) {
+			return f
+		}
+	}
+	return nil
+}
+
+// nodes returns every top-level node with includes expanded in place
+func (t *configTree) nodes() []*Node {
+	var out []*Node
+	var walk func(f *ConfigFile, seen map[*ConfigFile]bool)
+	walk = func(f *ConfigFile, seen map[*ConfigFile]bool) {
+		seen[f] = true
+		for _, n := range f.Doc.Nodes {
+			if inc, ok := t.includes[n]; ok {
+				if !seen[inc] {
+					walk(inc, seen)
+				}
+				continue
+			}
+			out = append(out, n)
+		}
+		delete(seen, f)
+	}
+	walk(t.main(), map[*ConfigFile]bool{})
+	return out
+}
+
+// NodesNamed returns every top-level node with the given name, across files
+func (t *configTree) NodesNamed(name string) []*Node {
+	return allNamed(t.nodes(), name)
+}
+
+// Node returns the last top-level node with the given name, across files
+func (t *configTree) Node(name string) *Node {
+	return lastNamed(t.nodes(), name)
+}
+
+// lookup returns every node at a path such as ("layout", "border", "width"),
+// in the order niri applies them
+func (t *configTree) lookup(path ...string) []*Node {
+	matches := t.NodesNamed(path[0])
+	for _, name := range path[1:] {
+		var next []*Node
+		for _, n := range matches {
+			next = append(next, n.ChildrenNamed(name)...)
+		}
+		matches = next
+	}
+	return matches
+}
+
+// ensure returns the node at path that takes effect, wherever it is
+// defined. Missing nodes are created under the deepest existing parent,
+// or at the end of the main file.
+func (t *configTree) ensure(path ...string) *Node {
+	for depth := len(path); depth >= 1; depth-- {
+		matches := t.lookup(path[:depth]...)
+		if len(matches) == 0 {
+			continue
+		}
+		n := matches[len(matches)-1]
+		for _, name := range path[depth:] {
+			n = n.EnsureChild(name)
+		}
+		return n
+	}
+
+	n := NewNode(path[0])
+	t.main().Doc.AppendNode(n)
+	for _, name := range path[1:] {
+		n = n.EnsureChild(name)
+	}
+	return n
+}
+
+// flagState reports a flag across every block at the parent path: it is
+// present if any block sets it
+func (t *configTree) flagState(name string, parent ...string) FlagState {
+	state := FlagAbsent
+	for _, block := range t.lookup(parent...) {
+		switch flagState(block, name) {
+		case FlagPresent:
+			return FlagPresent
+		case FlagCommented:
+			state = FlagCommented
+		}
+	}
+	return state
+}
+
+// setFlag turns a flag on or off across every block at the parent path
+func (t *configTree) setFlag(on bool, name string, parent ...string) {
+	blocks := t.lookup(parent...)
+	if !on {
+		for _, block := range blocks {
+			setFlag(block, name, false)
+		}
+		return
+	}
+
+	for _, block := range blocks {
+		if block.HasChild(name) {
+			return
+		}
+	}
+	// Prefer restoring a commented-out copy in the block that has one
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if flagState(blocks[i], name) == FlagCommented {
+			setFlag(blocks[i], name, true)
+			return
+		}
+	}
+	setFlag(t.ensure(parent...), name, true)
+}
+
+// save writes every file whose content changed
+func (t *configTree) save() error {
+	for _, f := range t.files {
+		out := f.Doc.String()
+		if out == f.saved {
+			continue
+		}
+		if err := os.WriteFile(f.Path, []byte(out), 0644); err != nil {
+			return err
+		}
+		f.saved = out
+	}
+	return nil
+}
@@ -0,0 +1,987 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValueKind identifies the type of a KDL value
+type ValueKind int
+
+const (
+	KindNull ValueKind = iota
+	KindString
+	KindInt
+	KindFloat
+	KindBool
+)
+
+// Value is a single KDL argument or property value
+type Value struct {
+	Kind  ValueKind
+	Type  string // optional type annotation, e.g. (u8)
+	Str   string
+	Int   int64
+	Float float64
+	Bool  bool
+	Raw   bool // string was written as a raw string
+}
+
+// AsInt returns the value as an int if it is numeric
+func (v Value) AsInt() (int, bool) {
+	switch v.Kind {
+	case KindInt:
+		return int(v.Int), true
+	case KindFloat:
+		return int(v.Float), true
+	}
+	return 0, false
+}
+
+// AsFloat returns the value as a float if it is numeric
+func (v Value) AsFloat() (float64, bool) {
+	switch v.Kind {
+	case KindInt:
+		return float64(v.Int), true
+	case KindFloat:
+		return v.Float, true
+	}
+	return 0, false
+}
+
+// AsString returns the value as a string if it is one
+func (v Value) AsString() (string, bool) {
+	if v.Kind == KindString {
+		return v.Str, true
+	}
+	return "", false
+}
+
+// AsBool returns the value as a bool if it is one
+func (v Value) AsBool() (bool, bool) {
+	if v.Kind == KindBool {
+		return v.Bool, true
+	}
+	return false, false
+}
+
+// Pos is a location in KDL source. Line and Column are 1-based,
+// Column counts characters, Offset counts bytes.
+type Pos struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Entry is an argument (Key == "") or a property of a node
+type Entry struct {
+	Key   string
+	Value Value
+	Pos   Pos
+
+	// Source text, used to write the entry back unchanged
+	leading  string
+	raw      string
+	rawKey   string
+	rawValue Value
+}
+
+// Node is a KDL node with its entries and children.
+//
+// Besides the parsed values a node keeps the exact source text around
+// it (whitespace, comments, terminators), so a document can be written
+// back byte for byte. Only the parts that were modified get reformatted.
+type Node struct {
+	Name     string
+	Type     string // optional type annotation
+	Entries  []*Entry
+	Children []*Node
+	Pos      Pos
+
+	leading          string // trivia before the node, including comments
+	rawHead          string // type annotation and name as written
+	rawName          string
+	rawType          string
+	beforeChildren   string // trivia between the last entry and '{'
+	hasBlock         bool
+	childrenTrailing string // trivia between the last child and '}'
+	trailing         string // trivia up to and including the terminator
+}
+
+// Document is a parsed KDL document
+type Document struct {
+	Nodes []*Node
+
+	trailing string // trivia after the last node
+}
+
+// Node returns the last top-level node with the given name, or nil
+func (d *Document) Node(name string) *Node {
+	return lastNamed(d.Nodes, name)
+}
+
+// NodesNamed returns every top-level node with the given name
+func (d *Document) NodesNamed(name string) []*Node {
+	return allNamed(d.Nodes, name)
+}
+
+// Args returns the node's positional arguments in order
+func (n *Node) Args() []Value {
+	var args []Value
+	for _, e := range n.Entries {
+		if e.Key == "" {
+			args = append(args, e.Value)
+		}
+	}
+	return args
+}
+
+// Arg returns the i-th positional argument
+func (n *Node) Arg(i int) (Value, bool) {
+	for _, e := range n.Entries {
+		if e.Key != "" {
+			continue
+		}
+		if i == 0 {
+			return e.Value, true
+		}
+		i--
+	}
+	return Value{}, false
+}
+
+// Prop returns the value of a property. When a property is repeated
+// the last occurrence wins, as in the KDL spec.
+func (n *Node) Prop(key string) (Value, bool) {
+	var val Value
+	found := false
+	for _, e := range n.Entries {
+		if e.Key == key {
+			val = e.Value
+			found = true
+		}
+	}
+	return val, found
+}
+
+// Child returns the last child with the given name, or nil
+func (n *Node) Child(name string) *Node {
+	if n == nil {
+		return nil
+	}
+	return lastNamed(n.Children, name)
+}
+
+// ChildrenNamed returns every child with the given name
+func (n *Node) ChildrenNamed(name string) []*Node {
+	if n == nil {
+		return nil
+	}
+	return allNamed(n.Children, name)
+}
+
+// HasChild reports whether the node has a child with the given name
+func (n *Node) HasChild(name string) bool {
+	return n.Child(name) != nil
+}
+
+// IntArg returns the first argument of the named child as an int
+func (n *Node) IntArg(child string) (int, bool) {
+	c := n.Child(child)
+	if c == nil {
+		return 0, false
+	}
+	v, ok := c.Arg(0)
+	if !ok {
+		return 0, false
+	}
+	return v.AsInt()
+}
+
+// FloatArg returns the first argument of the named child as a float
+func (n *Node) FloatArg(child string) (float64, bool) {
+	c := n.Child(child)
+	if c == nil {
+		return 0, false
+	}
+	v, ok := c.Arg(0)
+	if !ok {
+		return 0, false
+	}
+	return v.AsFloat()
+}
+
+// StringArg returns the first argument of the named child as a string
+func (n *Node) StringArg(child string) (string, bool) {
+	c := n.Child(child)
+	if c == nil {
+		return "", false
+	}
+	v, ok := c.Arg(0)
+	if !ok {
+		return "", false
+	}
+	return v.AsString()
+}
+
+func lastNamed(nodes []*Node, name string) *Node {
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if nodes[i].Name == name {
+			return nodes[i]
+		}
+	}
+	return nil
+}
+
+func allNamed(nodes []*Node, name string) []*Node {
+	var out []*Node
+	for _, n := range nodes {
+		if n.Name == name {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// ParseError describes a KDL syntax error
+type ParseError struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// ParseKDL parses a KDL v1 document
+func ParseKDL(src []byte) (*Document, error) {
+	p := &parser{src: src}
+	p.indexLines()
+
+	// Skip a leading byte order mark
+	if bytes.HasPrefix(src, []byte("\uFEFF")) {
+		p.off = 3
+	}
+
+	nodes, trailing, err := p.parseNodes(0)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Nodes: nodes, trailing: trailing}, nil
+}
+
+// parser is a recursive descent KDL parser working on raw bytes
+type parser struct {
+	src        []byte
+	off        int
+	lineStarts []int
+}
+
+func (p *parser) indexLines() {
+	p.lineStarts = []int{0}
+	for i := 0; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\n':
+			p.lineStarts = append(p.lineStarts, i+1)
+		case '\r':
+			if i+1 < len(p.src) && p.src[i+1] == '\n' {
+				i++
+			}
+			p.lineStarts = append(p.lineStarts, i+1)
+		}
+	}
+}
+
+// position converts a byte offset into a line/column position
+func (p *parser) position(off int) Pos {
+	lo, hi := 0, len(p.lineStarts)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if p.lineStarts[mid] <= off {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	start := p.lineStarts[lo]
+	return Pos{
+		Line:   lo + 1,
+		Column: utf8.RuneCount(p.src[start:off]) + 1,
+		Offset: off,
+	}
+}
+
+func (p *parser) errorf(off int, format string, args ...any) error {
+	return &ParseError{Pos: p.position(off), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.off >= len(p.src)
+}
+
+func (p *parser) peekRune() (rune, int) {
+	if p.eof() {
+		return utf8.RuneError, 0
+	}
+	return utf8.DecodeRune(p.src[p.off:])
+}
+
+// text returns the source from start up to the current offset
+func (p *parser) text(start int) string {
+	return string(p.src[start:p.off])
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return bytes.HasPrefix(p.src[p.off:], []byte(s))
+}
+
+// parseNodes parses nodes until EOF (depth 0) or a closing brace. It
+// also returns the trivia between the last node and the end of the list.
+func (p *parser) parseNodes(depth int) ([]*Node, string, error) {
+	var nodes []*Node
+	pend := p.off
+	if depth == 0 {
+		// Keep a byte order mark as part of the first node's trivia
+		pend = 0
+	}
+	for {
+		if err := p.skipLineSpace(); err != nil {
+			return nil, "", err
+		}
+		if p.eof() {
+			if depth > 0 {
+				return nil, "", p.errorf(p.off, "unexpected end of file, expected '}'")
+			}
+			return nodes, p.text(pend), nil
+		}
+		if p.src[p.off] == '}' {
+			if depth == 0 {
+				return nil, "", p.errorf(p.off, "unexpected '}'")
+			}
+			return nodes, p.text(pend), nil
+		}
+
+		discard := false
+		if p.hasPrefix("/-") {
+			p.off += 2
+			if _, err := p.skipNodeSpace(); err != nil {
+				return nil, "", err
+			}
+			discard = true
+		}
+
+		start := p.off
+		node, err := p.parseNode(depth)
+		if err != nil {
+			return nil, "", err
+		}
+		if discard {
+			// Slashdashed nodes stay in the source as trivia
+			continue
+		}
+		node.leading = string(p.src[pend:start])
+		nodes = append(nodes, node)
+		pend = p.off
+	}
+}
+
+// parseNode parses a single node including its terminator
+func (p *parser) parseNode(depth int) (*Node, error) {
+	start := p.off
+	node := &Node{Pos: p.position(p.off)}
+
+	if !p.eof() && p.src[p.off] == '(' {
+		typ, err := p.parseTypeAnnotation()
+		if err != nil {
+			return nil, err
+		}
+		node.Type = typ
+	}
+
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	node.Name = name
+	node.rawHead = p.text(start)
+	node.rawName, node.rawType = node.Name, node.Type
+
+	// pend marks the start of trivia not yet attached to an element
+	pend := p.off
+	for {
+		hadSpace, err := p.skipNodeSpace()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.eof() {
+			node.trailing = p.text(pend)
+			return node, nil
+		}
+
+		switch c := p.src[p.off]; {
+		case c == ';':
+			p.off++
+			node.trailing = p.text(pend)
+			return node, nil
+		case c == '}':
+			if depth == 0 {
+				return nil, p.errorf(p.off, "unexpected '}'")
+			}
+			node.trailing = p.text(pend)
+			return node, nil
+		case p.atNewline():
+			p.consumeNewline()
+			node.trailing = p.text(pend)
+			return node, nil
+		case p.hasPrefix("//"):
+			p.skipLineComment()
+			node.trailing = p.text(pend)
+			return node, nil
+		case c == '{':
+			node.beforeChildren = p.text(pend)
+			children, trailing, err := p.parseChildren(depth)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = children
+			node.childrenTrailing = trailing
+			node.hasBlock = true
+			pend = p.off
+			if err := p.parseTerminator(depth); err != nil {
+				return nil, err
+			}
+			node.trailing = p.text(pend)
+			return node, nil
+		}
+
+		if !hadSpace {
+			return nil, p.errorf(p.off, "expected whitespace before entry")
+		}
+
+		if p.hasPrefix("/-") {
+			p.off += 2
+			if _, err := p.skipNodeSpace(); err != nil {
+				return nil, err
+			}
+			if !p.eof() && p.src[p.off] == '{' {
+				if _, _, err := p.parseChildren(depth); err != nil {
+					return nil, err
+				}
+				// Only more slashdashed children blocks may follow
+				continue
+			}
+			if _, err := p.parseEntry(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		entryStart := p.off
+		entry, err := p.parseEntry()
+		if err != nil {
+			return nil, err
+		}
+		entry.leading = string(p.src[pend:entryStart])
+		entry.raw = p.text(entryStart)
+		entry.rawKey, entry.rawValue = entry.Key, entry.Value
+		node.Entries = append(node.Entries, entry)
+		pend = p.off
+	}
+}
+
+// parseChildren parses a brace-delimited children block
+func (p *parser) parseChildren(depth int) ([]*Node, string, error) {
+	open := p.off
+	p.off++ // '{'
+	children, trailing, err := p.parseNodes(depth + 1)
+	if err != nil {
+		return nil, "", err
+	}
+	if p.eof() || p.src[p.off] != '}' {
+		return nil, "", p.errorf(open, "unclosed '{'")
+	}
+	p.off++ // '}'
+	return children, trailing, nil
+}
+
+// parseTerminator consumes whatever may follow a children block
+func (p *parser) parseTerminator(depth int) error {
+	for {
+		if _, err := p.skipNodeSpace(); err != nil {
+			return err
+		}
+		if p.eof() {
+			return nil
+		}
+		switch {
+		case p.src[p.off] == ';':
+			p.off++
+			return nil
+		case p.src[p.off] == '}' && depth > 0:
+			return nil
+		case p.atNewline():
+			p.consumeNewline()
+			return nil
+		case p.hasPrefix("//"):
+			p.skipLineComment()
+			return nil
+		case p.hasPrefix("/-"):
+			p.off += 2
+			if _, err := p.skipNodeSpace(); err != nil {
+				return err
+			}
+			if p.eof() || p.src[p.off] != '{' {
+				return p.errorf(p.off, "expected children block after '/-'")
+			}
+			if _, _, err := p.parseChildren(depth); err != nil {
+				return err
+			}
+		default:
+			return p.errorf(p.off, "unexpected %q after children block", p.src[p.off])
+		}
+	}
+}
+
+// parseEntry parses an argument or a key=value property
+func (p *parser) parseEntry() (*Entry, error) {
+	entry := &Entry{Pos: p.position(p.off)}
+
+	// A type annotation can only precede a value
+	if p.src[p.off] == '(' {
+		val, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		entry.Value = val
+		return entry, nil
+	}
+
+	// Property keys are identifiers or strings followed by '='
+	start := p.off
+	if p.src[p.off] == '"' || p.isRawStringStart() || p.isIdentStart() {
+		key, err := p.parseName()
+		if err == nil && !p.eof() && p.src[p.off] == '=' {
+			p.off++
+			val, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			entry.Key = key
+			entry.Value = val
+			return entry, nil
+		}
+		p.off = start
+	}
+
+	val, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	entry.Value = val
+	return entry, nil
+}
+
+// parseValue parses a (possibly type-annotated) value
+func (p *parser) parseValue() (Value, error) {
+	var val Value
+	if !p.eof() && p.src[p.off] == '(' {
+		typ, err := p.parseTypeAnnotation()
+		if err != nil {
+			return val, err
+		}
+		val.Type = typ
+	}
+	if p.eof() {
+		return val, p.errorf(p.off, "expected value")
+	}
+
+	start := p.off
+	switch c := p.src[p.off]; {
+	case c == '"':
+		s, err := p.parseEscapedString()
+		if err != nil {
+			return val, err
+		}
+		val.Kind, val.Str = KindString, s
+		return val, nil
+	case p.isRawStringStart():
+		s, err := p.parseRawString()
+		if err != nil {
+			return val, err
+		}
+		val.Kind, val.Str, val.Raw = KindString, s, true
+		return val, nil
+	case p.isNumberStart():
+		return p.parseNumber(val)
+	}
+
+	ident := p.scanIdent()
+	switch ident {
+	case "true", "false":
+		val.Kind, val.Bool = KindBool, ident == "true"
+		return val, nil
+	case "null":
+		val.Kind = KindNull
+		return val, nil
+	case "":
+		return val, p.errorf(start, "unexpected %q", p.src[start])
+	}
+	return val, p.errorf(start, "bare identifier %q is not a valid value; quote it", ident)
+}
+
+// parseName parses a node name or property key
+func (p *parser) parseName() (string, error) {
+	if p.eof() {
+		return "", p.errorf(p.off, "expected node name")
+	}
+	if p.src[p.off] == '"' {
+		return p.parseEscapedString()
+	}
+	if p.isRawStringStart() {
+		return p.parseRawString()
+	}
+	start := p.off
+	if !p.isIdentStart() {
+		return "", p.errorf(start, "expected identifier, found %q", p.src[start])
+	}
+	ident := p.scanIdent()
+	return ident, nil
+}
+
+func (p *parser) parseTypeAnnotation() (string, error) {
+	open := p.off
+	p.off++ // '('
+	name, err := p.parseName()
+	if err != nil {
+		return "", err
+	}
+	if p.eof() || p.src[p.off] != ')' {
+		return "", p.errorf(open, "unclosed type annotation")
+	}
+	p.off++
+	return name, nil
+}
+
+// isIdentChar reports whether r may appear in a bare identifier
+func isIdentChar(r rune) bool {
+	if r <= 0x20 || r == 0x7f || isUnicodeSpace(r) || isNewlineRune(r) {
+		return false
+	}
+	return !strings.ContainsRune(`\/(){}<>;[]=,"`, r)
+}
+
+func (p *parser) isIdentStart() bool {
+	r, _ := p.peekRune()
+	if !isIdentChar(r) || (r >= '0' && r <= '9') {
+		return false
+	}
+	return !p.isNumberStart()
+}
+
+func (p *parser) scanIdent() string {
+	start := p.off
+	for !p.eof() {
+		r, size := p.peekRune()
+		if !isIdentChar(r) {
+			break
+		}
+		p.off += size
+	}
+	return string(p.src[start:p.off])
+}
+
+func (p *parser) isRawStringStart() bool {
+	if p.eof() || p.src[p.off] != 'r' {
+		return false
+	}
+	i := p.off + 1
+	for i < len(p.src) && p.src[i] == '#' {
+		i++
+	}
+	return i < len(p.src) && p.src[i] == '"'
+}
+
+func (p *parser) isNumberStart() bool {
+	i := p.off
+	if i < len(p.src) && (p.src[i] == '+' || p.src[i] == '-') {
+		i++
+	}
+	return i < len(p.src) && p.src[i] >= '0' && p.src[i] <= '9'
+}
+
+func (p *parser) parseEscapedString() (string, error) {
+	open := p.off
+	p.off++ // opening quote
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(open, "unterminated string")
+		}
+		c := p.src[p.off]
+		switch c {
+		case '"':
+			p.off++
+			return b.String(), nil
+		case '\\':
+			r, err := p.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			r, size := p.peekRune()
+			b.WriteRune(r)
+			p.off += size
+		}
+	}
+}
+
+func (p *parser) parseEscape() (rune, error) {
+	start := p.off
+	p.off++ // backslash
+	if p.eof() {
+		return 0, p.errorf(start, "unterminated escape")
+	}
+	c := p.src[p.off]
+	p.off++
+	switch c {
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case '\\':
+		return '\\', nil
+	case '/':
+		return '/', nil
+	case '"':
+		return '"', nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'u':
+		if p.eof() || p.src[p.off] != '{' {
+			return 0, p.errorf(start, "invalid unicode escape")
+		}
+		end := bytes.IndexByte(p.src[p.off:], '}')
+		if end < 2 || end > 7 {
+			return 0, p.errorf(start, "invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.src[p.off+1:p.off+end]), 16, 32)
+		if err != nil || code > utf8.MaxRune {
+			return 0, p.errorf(start, "invalid unicode escape")
+		}
+		p.off += end + 1
+		return rune(code), nil
+	}
+	return 0, p.errorf(start, "invalid escape '\\%c'", c)
+}
+
+func (p *parser) parseRawString() (string, error) {
+	open := p.off
+	p.off++ // 'r'
+	hashes := 0
+	for p.src[p.off] == '#' {
+		hashes++
+		p.off++
+	}
+	p.off++ // opening quote
+	closing := `"` + strings.Repeat("#", hashes)
+	end := bytes.Index(p.src[p.off:], []byte(closing))
+	if end < 0 {
+		return "", p.errorf(open, "unterminated raw string")
+	}
+	s := string(p.src[p.off : p.off+end])
+	p.off += end + len(closing)
+	return s, nil
+}
+
+func (p *parser) parseNumber(val Value) (Value, error) {
+	start := p.off
+	for !p.eof() {
+		r, size := p.peekRune()
+		if !isIdentChar(r) {
+			break
+		}
+		p.off += size
+	}
+	text := string(p.src[start:p.off])
+
+	sign := ""
+	digits := text
+	if digits[0] == '+' || digits[0] == '-' {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if sign == "+" {
+		sign = ""
+	}
+
+	base := 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x':
+			base = 16
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+		if base != 10 {
+			digits = digits[2:]
+		}
+	}
+	if strings.HasPrefix(digits, "_") {
+		return val, p.errorf(start, "invalid number %q", text)
+	}
+	clean := strings.ReplaceAll(digits, "_", "")
+
+	if base == 10 && strings.ContainsAny(clean, ".eE") {
+		f, err := strconv.ParseFloat(sign+clean, 64)
+		if err != nil || math.IsInf(f, 0) {
+			return val, p.errorf(start, "invalid number %q", text)
+		}
+		val.Kind, val.Float = KindFloat, f
+		return val, nil
+	}
+
+	n, err := strconv.ParseInt(sign+clean, base, 64)
+	if err != nil {
+		return val, p.errorf(start, "invalid number %q", text)
+	}
+	val.Kind, val.Int = KindInt, n
+	return val, nil
+}
+
+// skipLineSpace skips whitespace, newlines and comments between nodes
+func (p *parser) skipLineSpace() error {
+	for !p.eof() {
+		switch {
+		case p.atNewline():
+			p.consumeNewline()
+		case p.hasPrefix("//"):
+			p.skipLineComment()
+		case p.hasPrefix("/*"):
+			if err := p.skipBlockComment(); err != nil {
+				return err
+			}
+		default:
+			r, size := p.peekRune()
+			if !isUnicodeSpace(r) {
+				return nil
+			}
+			p.off += size
+		}
+	}
+	return nil
+}
+
+// skipNodeSpace skips whitespace, block comments and line continuations
+// within a node. It reports whether anything was skipped.
+func (p *parser) skipNodeSpace() (bool, error) {
+	start := p.off
+	for !p.eof() {
+		switch {
+		case p.hasPrefix("/*"):
+			if err := p.skipBlockComment(); err != nil {
+				return false, err
+			}
+		case p.src[p.off] == '\\':
+			save := p.off
+			p.off++
+			for !p.eof() {
+				r, size := p.peekRune()
+				if !isUnicodeSpace(r) {
+					break
+				}
+				p.off += size
+			}
+			if p.hasPrefix("//") {
+				p.skipLineComment()
+			} else if p.atNewline() {
+				p.consumeNewline()
+			} else if !p.eof() {
+				p.off = save
+				return p.off > start, nil
+			}
+		default:
+			r, size := p.peekRune()
+			if !isUnicodeSpace(r) {
+				return p.off > start, nil
+			}
+			p.off += size
+		}
+	}
+	return p.off > start, nil
+}
+
+func (p *parser) skipLineComment() {
+	for !p.eof() && !p.atNewline() {
+		_, size := p.peekRune()
+		p.off += size
+	}
+	p.consumeNewline()
+}
+
+func (p *parser) skipBlockComment() error {
+	open := p.off
+	depth := 0
+	for !p.eof() {
+		switch {
+		case p.hasPrefix("/*"):
+			depth++
+			p.off += 2
+		case p.hasPrefix("*/"):
+			depth--
+			p.off += 2
+			if depth == 0 {
+				return nil
+			}
+		default:
+			p.off++
+		}
+	}
+	return p.errorf(open, "unterminated block comment")
+}
+
+func (p *parser) atNewline() bool {
+	r, _ := p.peekRune()
+	return isNewlineRune(r)
+}
+
+func (p *parser) consumeNewline() {
+	if p.hasPrefix("\r\n") {
+		p.off += 2
+		return
+	}
+	if p.atNewline() {
+		_, size := p.peekRune()
+		p.off += size
+	}
+}
+
+func isNewlineRune(r rune) bool {
+	switch r {
+	case '\n', '\r', '\u0085', '\u000C', '\u2028', '\u2029':
+		return true
+	}
+	return false
+}
+
+func isUnicodeSpace(r rune) bool {
+	switch r {
+	case '\t', ' ', '\u00A0', '\u1680', '\u202F', '\u205F', '\u3000', '\uFEFF':
+		return true
+	}
+	return r >= '\u2000' && r <= '\u200A'
+}
@@ -0,0 +1,740 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// indentUnit is used when a new block has no siblings to copy from
+const indentUnit = "    "
+
+// StringValue returns a KDL string value
+func StringValue(s string) Value {
+	return Value{Kind: KindString, Str: s}
+}
+
+// StringArgs formats strings as KDL string arguments separated by
+// spaces, as in "foot" "-e" "btop"
+func StringArgs(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = StringValue(arg).String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// IntValue returns a KDL integer value
+func IntValue(i int) Value {
+	return Value{Kind: KindInt, Int: int64(i)}
+}
+
+// FloatValue returns a KDL float value
+func FloatValue(f float64) Value {
+	return Value{Kind: KindFloat, Float: f}
+}
+
+// BoolValue returns a KDL boolean value
+func BoolValue(b bool) Value {
+	return Value{Kind: KindBool, Bool: b}
+}
+
+// NewNode creates a detached node with the given arguments. Add it to a
+// document or parent before adding children so they get indented right.
+func NewNode(name string, args ...Value) *Node {
+	n := &Node{Name: name}
+	n.SetArgs(args...)
+	return n
+}
+
+// String formats the value as KDL source
+func (v Value) String() string {
+	var b strings.Builder
+	if v.Type != "" {
+		b.WriteString("(" + formatIdent(v.Type) + ")")
+	}
+	switch v.Kind {
+	case KindString:
+		if v.Raw {
+			b.WriteString(formatRawString(v.Str))
+		} else {
+			b.WriteString(quoteString(v.Str))
+		}
+	case KindInt:
+		b.WriteString(strconv.FormatInt(v.Int, 10))
+	case KindFloat:
+		s := strconv.FormatFloat(v.Float, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		b.WriteString(s)
+	case KindBool:
+		b.WriteString(strconv.FormatBool(v.Bool))
+	default:
+		b.WriteString("null")
+	}
+	return b.String()
+}
+
+// String formats the document, reproducing unmodified parts verbatim
+func (d *Document) String() string {
+	var b strings.Builder
+	for _, n := range d.Nodes {
+		n.write(&b)
+	}
+	b.WriteString(d.trailing)
+	return b.String()
+}
+
+// String formats the node without the trivia around it
+func (n *Node) String() string {
+	var b strings.Builder
+	n.writeBody(&b)
+	return b.String()
+}
+
+func (n *Node) write(b *strings.Builder) {
+	b.WriteString(n.leading)
+	n.writeBody(b)
+	b.WriteString(n.trailing)
+}
+
+func (n *Node) writeBody(b *strings.Builder) {
+	if n.rawHead != "" && n.Name == n.rawName && n.Type == n.rawType {
+		b.WriteString(n.rawHead)
+	} else {
+		if n.Type != "" {
+			b.WriteString("(" + formatIdent(n.Type) + ")")
+		}
+		b.WriteString(formatIdent(n.Name))
+	}
+
+	for _, e := range n.Entries {
+		b.WriteString(e.leading)
+		if e.raw != "" && e.Key == e.rawKey && e.Value == e.rawValue {
+			b.WriteString(e.raw)
+			continue
+		}
+		if e.Key != "" {
+			b.WriteString(formatIdent(e.Key) + "=")
+		}
+		b.WriteString(e.Value.String())
+	}
+
+	if n.hasBlock || len(n.Children) > 0 {
+		b.WriteString(n.beforeChildren)
+		b.WriteString("{")
+		for _, c := range n.Children {
+			c.write(b)
+		}
+		b.WriteString(n.childrenTrailing)
+		b.WriteString("}")
+	}
+}
+
+// SetArgs replaces the node's arguments, keeping its properties
+func (n *Node) SetArgs(vals ...Value) {
+	var entries []*Entry
+	insertAt := 0
+	i := 0
+	for _, e := range n.Entries {
+		if e.Key != "" {
+			entries = append(entries, e)
+			continue
+		}
+		if i < len(vals) {
+			e.Value = vals[i]
+			entries = append(entries, e)
+			insertAt = len(entries)
+			i++
+		}
+	}
+
+	var added []*Entry
+	for _, v := range vals[i:] {
+		added = append(added, &Entry{Value: v, leading: " "})
+	}
+	n.Entries = append(entries[:insertAt], append(added, entries[insertAt:]...)...)
+}
+
+// SetArg sets the i-th argument, appending it if i is one past the end
+func (n *Node) SetArg(i int, v Value) {
+	args := n.Args()
+	switch {
+	case i < len(args):
+		args[i] = v
+	case i == len(args):
+		args = append(args, v)
+	default:
+		return
+	}
+	n.SetArgs(args...)
+}
+
+// SetProp sets a property, updating the last occurrence if it exists
+func (n *Node) SetProp(key string, v Value) {
+	for i := len(n.Entries) - 1; i >= 0; i-- {
+		if n.Entries[i].Key == key {
+			n.Entries[i].Value = v
+			return
+		}
+	}
+	n.Entries = append(n.Entries, &Entry{Key: key, Value: v, leading: " "})
+}
+
+// RemoveProp removes every occurrence of a property
+func (n *Node) RemoveProp(key string) {
+	entries := n.Entries[:0]
+	for _, e := range n.Entries {
+		if e.Key != key {
+			entries = append(entries, e)
+		}
+	}
+	n.Entries = entries
+}
+
+// EnsureChild returns the last child with the given name, creating it
+// at the end of the block if there is none
+func (n *Node) EnsureChild(name string) *Node {
+	if c := n.Child(name); c != nil {
+		return c
+	}
+	c := NewNode(name)
+	n.AppendChild(c)
+	return c
+}
+
+// AppendChild adds a child at the end of the node's block
+func (n *Node) AppendChild(c *Node) {
+	n.InsertChild(len(n.Children), c)
+}
+
+// InsertChild inserts a child at index i, matching the indentation and
+// layout (one node per line or `{ a; b; }`) of the existing block
+func (n *Node) InsertChild(i int, c *Node) {
+	if !n.isMultiline() && len(n.Children) == 0 {
+		// A node inside a one-line block keeps its own block on that line
+		if n.isInline() {
+			n.AppendInlineChild(c)
+			return
+		}
+		// Open a fresh multi-line block
+		if !n.hasBlock && n.beforeChildren == "" {
+			n.beforeChildren = " "
+		}
+		n.hasBlock = true
+		n.childrenTrailing = "\n" + n.indent()
+	}
+	n.Children = insertNode(n.Children, &n.childrenTrailing, i, c, n.childIndent(), true, n.isMultiline())
+}
+
+// RemoveChild removes a child along with the comments directly above it
+func (n *Node) RemoveChild(c *Node) bool {
+	for i, child := range n.Children {
+		if child == c {
+			n.Children = removeNode(n.Children, &n.childrenTrailing, i)
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureNode returns the last top-level node with the given name,
+// creating it at the end of the document if there is none
+func (d *Document) EnsureNode(name string) *Node {
+	if n := d.Node(name); n != nil {
+		return n
+	}
+	n := NewNode(name)
+	d.AppendNode(n)
+	return n
+}
+
+// AppendNode adds a top-level node at the end of the document,
+// separated from the previous node by a blank line
+func (d *Document) AppendNode(n *Node) {
+	d.InsertNode(len(d.Nodes), n)
+	if len(d.Nodes) > 1 {
+		n.leading = "\n" + n.leading
+	}
+}
+
+// InsertNode inserts a top-level node at index i
+func (d *Document) InsertNode(i int, n *Node) {
+	d.Nodes = insertNode(d.Nodes, &d.trailing, i, n, "", false, true)
+}
+
+// RemoveNode removes a top-level node along with the comments directly
+// above it
+func (d *Document) RemoveNode(n *Node) bool {
+	for i, node := range d.Nodes {
+		if node == n {
+			d.Nodes = removeNode(d.Nodes, &d.trailing, i)
+			return true
+		}
+	}
+	return false
+}
+
+// clone returns a deep copy of the document, recording in nodes which
+// copy stands for which original node
+func (d *Document) clone(nodes map[*Node]*Node) *Document {
+	c := &Document{trailing: d.trailing}
+	for _, n := range d.Nodes {
+		c.Nodes = append(c.Nodes, n.clone(nodes))
+	}
+	return c
+}
+
+// clone returns a deep copy of the node and its children
+func (n *Node) clone(nodes map[*Node]*Node) *Node {
+	c := *n
+	c.Entries = make([]*Entry, len(n.Entries))
+	for i, e := range n.Entries {
+		entry := *e
+		c.Entries[i] = &entry
+	}
+	c.Children = nil
+	for _, child := range n.Children {
+		c.Children = append(c.Children, child.clone(nodes))
+	}
+	nodes[n] = &c
+	return &c
+}
+
+// indent returns the whitespace at the start of the node's line
+func (n *Node) indent() string {
+	line := n.leading
+	if i := strings.LastIndexAny(line, "\r\n"); i >= 0 {
+		line = line[i+1:]
+	}
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// childIndent returns the indentation used for the node's children
+func (n *Node) childIndent() string {
+	for _, c := range n.Children {
+		if strings.ContainsAny(c.leading, "\r\n") {
+			return c.indent()
+		}
+	}
+	if strings.Contains(n.indent(), "\t") {
+		return n.indent() + "\t"
+	}
+	return n.indent() + indentUnit
+}
+
+// isInline reports whether the node shares its line with its siblings,
+// as in `layout { gaps 5; }`
+func (n *Node) isInline() bool {
+	return n.leading != "" && !strings.ContainsAny(n.leading+n.trailing, "\r\n")
+}
+
+// isMultiline reports whether the node's children sit on their own lines
+func (n *Node) isMultiline() bool {
+	if strings.ContainsAny(n.childrenTrailing, "\r\n") {
+		return true
+	}
+	for _, c := range n.Children {
+		if strings.ContainsAny(c.leading+c.trailing, "\r\n") {
+			return true
+		}
+	}
+	return false
+}
+
+// insertNode inserts node into a list of siblings at index i and picks
+// the whitespace around it. trailing is the trivia after the last
+// sibling; inBlock is set when the list directly follows a '{'.
+func insertNode(nodes []*Node, trailing *string, i int, node *Node, indent string, inBlock, multiline bool) []*Node {
+	if i < 0 || i > len(nodes) {
+		i = len(nodes)
+	}
+
+	if !multiline {
+		node.leading = " "
+		node.trailing = ";"
+		if i == len(nodes) {
+			last := nodes[len(nodes)-1]
+			if !hasTerminator(last.trailing) {
+				*trailing = last.trailing + *trailing
+				last.trailing = ";"
+			}
+		}
+	} else {
+		// needNewline is set when the preceding text doesn't end a line
+		var needNewline bool
+		if i == 0 {
+			needNewline = inBlock
+		} else {
+			needNewline = !endsWithNewline(nodes[i-1].trailing)
+		}
+
+		node.trailing = "\n"
+		if i == len(nodes) {
+			// Append after any comments at the end of the block
+			head := ""
+			if j := strings.LastIndex(*trailing, "\n"); j >= 0 {
+				head, *trailing = (*trailing)[:j+1], (*trailing)[j+1:]
+			} else if needNewline {
+				head = "\n"
+			}
+			node.leading = head + indent
+		} else {
+			next := nodes[i]
+			head := ""
+			if needNewline {
+				if j := strings.Index(next.leading, "\n"); j >= 0 {
+					head, next.leading = next.leading[:j+1], next.leading[j+1:]
+				} else {
+					head, next.leading = "\n", indent
+				}
+			}
+			node.leading = head + indent
+		}
+	}
+
+	nodes = append(nodes, nil)
+	copy(nodes[i+1:], nodes[i:])
+	nodes[i] = node
+	return nodes
+}
+
+// removeNode removes the node at index i. Comments directly above it go
+// with it; any other trivia before it is kept.
+func removeNode(nodes []*Node, trailing *string, i int) []*Node {
+	node := nodes[i]
+	detached, _ := splitAttachedComments(node.leading)
+	if i > 0 && !endsWithNewline(nodes[i-1].trailing) && endsWithNewline(node.trailing) {
+		detached += "\n"
+	}
+
+	if i+1 < len(nodes) {
+		nodes[i+1].leading = detached + nodes[i+1].leading
+	} else {
+		*trailing = detached + *trailing
+	}
+	return append(nodes[:i], nodes[i+1:]...)
+}
+
+// splitAttachedComments splits leading trivia into the part that belongs
+// to the previous content and the line comments directly above the node
+// (plus its indentation)
+func splitAttachedComments(leading string) (detached, attached string) {
+	lines := strings.SplitAfter(leading, "\n")
+	k := len(lines) - 1
+	for k > 0 && strings.HasPrefix(strings.TrimSpace(lines[k-1]), "//") {
+		k--
+	}
+	return strings.Join(lines[:k], ""), strings.Join(lines[k:], "")
+}
+
+func hasTerminator(s string) bool {
+	return strings.ContainsAny(s, ";\r\n")
+}
+
+func endsWithNewline(s string) bool {
+	return strings.HasSuffix(s, "\n") || strings.HasSuffix(s, "\r")
+}
+
+// formatIdent writes a node name or property key, quoting it if needed
+func formatIdent(s string) string {
+	if isBareIdent(s) {
+		return s
+	}
+	return quoteString(s)
+}
+
+func isBareIdent(s string) bool {
+	switch s {
+	case "", "true", "false", "null":
+		return false
+	}
+	for _, r := range s {
+		if !isIdentChar(r) {
+			return false
+		}
+	}
+	p := &parser{src: []byte(s)}
+	return !p.isNumberStart() && !(s[0] >= '0' && s[0] <= '9')
+}
+
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// formatRawString writes s as a raw string with as many '#' as needed
+func formatRawString(s string) string {
+	hashes := ""
+	for strings.Contains(s, `"`+hashes) {
+		hashes += "#"
+	}
+	return "r" + hashes + `"` + s + `"` + hashes
+}
+
+// CommentedNode is a node found commented out in the trivia of a block,
+// written either as `// node ...` lines or as `/-node`. It is only valid
+// until the block is next modified.
+type CommentedNode struct {
+	Node *Node
+
+	index      int // sibling whose leading trivia holds it, or len(siblings)
+	start, end int // byte range within that trivia
+}
+
+// CommentedChildren returns the children that are commented out
+func (n *Node) CommentedChildren() []*CommentedNode {
+	return findCommented(n.Children, n.childrenTrailing)
+}
+
+// CommentedNodes returns the top-level nodes that are commented out
+func (d *Document) CommentedNodes() []*CommentedNode {
+	return findCommented(d.Nodes, d.trailing)
+}
+
+// UncommentChild restores a commented-out child in place
+func (n *Node) UncommentChild(cn *CommentedNode) *Node {
+	n.Children = uncommentNode(n.Children, &n.childrenTrailing, cn)
+	return cn.Node
+}
+
+// UncommentNode restores a commented-out top-level node in place
+func (d *Document) UncommentNode(cn *CommentedNode) *Node {
+	d.Nodes = uncommentNode(d.Nodes, &d.trailing, cn)
+	return cn.Node
+}
+
+// CommentOutChild turns a child into a comment, keeping its text so it
+// can be restored with UncommentChild
+func (n *Node) CommentOutChild(c *Node) bool {
+	for i, child := range n.Children {
+		if child == c {
+			n.Children = commentOutNode(n.Children, &n.childrenTrailing, i, !n.isMultiline())
+			return true
+		}
+	}
+	return false
+}
+
+// CommentOutNode turns a top-level node into a comment
+func (d *Document) CommentOutNode(n *Node) bool {
+	for i, node := range d.Nodes {
+		if node == n {
+			d.Nodes = commentOutNode(d.Nodes, &d.trailing, i, false)
+			return true
+		}
+	}
+	return false
+}
+
+// findCommented scans the trivia of a block for commented-out nodes
+func findCommented(nodes []*Node, trailing string) []*CommentedNode {
+	var found []*CommentedNode
+	for i := 0; i <= len(nodes); i++ {
+		trivia := trailing
+		if i < len(nodes) {
+			trivia = nodes[i].leading
+		}
+		for _, cn := range scanCommented(trivia) {
+			cn.index = i
+			found = append(found, cn)
+		}
+	}
+	return found
+}
+
+// maxCommentedLines bounds how far a commented node may span
+const maxCommentedLines = 64
+
+// scanCommented finds commented-out nodes in a piece of trivia. A
+// comment counts as a node when its text parses as exactly one KDL node.
+func scanCommented(trivia string) []*CommentedNode {
+	lines := strings.SplitAfter(trivia, "\n")
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + len(line)
+	}
+
+	var found []*CommentedNode
+	for k := 0; k < len(lines); k++ {
+		text, ok := uncommentLine(lines[k], true)
+		if !ok {
+			continue
+		}
+		slashdash := strings.HasPrefix(strings.TrimLeft(lines[k], " \t"), "/-")
+		for m := k; m < len(lines) && m-k < maxCommentedLines; m++ {
+			if m > k {
+				if slashdash {
+					break
+				}
+				next, ok := uncommentLine(lines[m], false)
+				if !ok {
+					break
+				}
+				text += next
+			}
+			doc, err := ParseKDL([]byte(text))
+			if err != nil {
+				// Only an unclosed block is worth continuing on the next line
+				if strings.Count(text, "{") > strings.Count(text, "}") {
+					continue
+				}
+				break
+			}
+			if len(doc.Nodes) != 1 || strings.TrimSpace(doc.trailing) != "" {
+				break
+			}
+			// Whitespace after the node stays behind in the trivia
+			end := offsets[m+1] - len(doc.trailing)
+			found = append(found, &CommentedNode{Node: doc.Nodes[0], start: offsets[k], end: end})
+			k = m
+			break
+		}
+	}
+	return found
+}
+
+// uncommentLine strips a `//` (or, for the first line, `/-`) marker from
+// a line while keeping its indentation
+func uncommentLine(line string, first bool) (string, bool) {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	switch {
+	case strings.HasPrefix(body, "//"):
+		body = strings.TrimPrefix(body[2:], " ")
+	case first && strings.HasPrefix(body, "/-"):
+		body = body[2:]
+	default:
+		return "", false
+	}
+	if strings.TrimSpace(body) == "" {
+		return "", false
+	}
+	return indent + body, true
+}
+
+// uncommentNode splices a commented node back into a list of siblings
+func uncommentNode(nodes []*Node, trailing *string, cn *CommentedNode) []*Node {
+	owner := trailing
+	if cn.index < len(nodes) {
+		owner = &nodes[cn.index].leading
+	}
+	trivia := *owner
+	if cn.end > len(trivia) {
+		return nodes
+	}
+
+	node := cn.Node
+	node.leading = trivia[:cn.start] + node.leading
+	*owner = trivia[cn.end:]
+
+	nodes = append(nodes, nil)
+	copy(nodes[cn.index+1:], nodes[cn.index:])
+	nodes[cn.index] = node
+	return nodes
+}
+
+// commentOutNode replaces the node at index i with its commented text.
+// Nodes in single-line blocks are slashdashed instead.
+func commentOutNode(nodes []*Node, trailing *string, i int, inline bool) []*Node {
+	node := nodes[i]
+
+	var text string
+	if inline {
+		text = node.leading + "/-" + node.String() + node.trailing
+	} else {
+		indent := node.indent()
+		lines := strings.Split(node.String(), "\n")
+		for j, line := range lines {
+			switch {
+			case j == 0:
+				lines[j] = "// " + line
+			case strings.HasPrefix(line, indent):
+				lines[j] = indent + "// " + line[len(indent):]
+			default:
+				lines[j] = "// " + line
+			}
+		}
+		text = node.leading + strings.Join(lines, "\n") + node.trailing
+		if !hasTerminator(node.trailing) {
+			text += "\n" + indent
+		}
+	}
+
+	if i+1 < len(nodes) {
+		nodes[i+1].leading = text + nodes[i+1].leading
+	} else {
+		*trailing = text + *trailing
+	}
+	return append(nodes[:i], nodes[i+1:]...)
+}
+
+// AppendInlineChild adds a child to a single-line block, as in
+// `Mod+T { spawn "foot"; }`. Blocks that already have children keep
+// their layout.
+func (n *Node) AppendInlineChild(c *Node) {
+	if len(n.Children) > 0 || n.isMultiline() {
+		n.AppendChild(c)
+		return
+	}
+	if n.beforeChildren == "" {
+		n.beforeChildren = " "
+	}
+	n.hasBlock = true
+	c.leading, c.trailing = " ", ";"
+	n.Children = []*Node{c}
+	n.childrenTrailing = " "
+}
+
+// Comments returns the text of the line comments directly above the node
+func (n *Node) Comments() []string {
+	_, attached := splitAttachedComments(n.leading)
+	var comments []string
+	for _, line := range strings.SplitAfter(attached, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "//") {
+			comments = append(comments, strings.TrimPrefix(line[2:], " "))
+		}
+	}
+	return comments
+}
+
+// SetComments replaces the line comments directly above the node
+func (n *Node) SetComments(comments []string) {
+	detached, _ := splitAttachedComments(n.leading)
+	indent := n.indent()
+	var b strings.Builder
+	b.WriteString(detached)
+	for _, c := range comments {
+		b.WriteString(indent + "// " + c + "\n")
+	}
+	b.WriteString(indent)
+	n.leading = b.String()
+}
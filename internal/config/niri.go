@@ -1,24 +1,14 @@
 package config
 
-import (
-	"bufio"
-	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
-	"strconv"
-	"strings"
-)
-
 // NiriConfig holds the parsed Niri configuration
 type NiriConfig struct {
 	Path string
 
 	// Layout settings
-	Gaps         int
-	BorderWidth  int
+	Gaps           int
+	BorderWidth    int
 	FocusRingWidth int
-	CornerRadius int
+	CornerRadius   int
 
 	// Shadow settings
 	ShadowEnabled  bool
@@ -26,8 +16,31 @@ type NiriConfig struct {
 	ShadowSpread   int
 
 	// Behavior settings
-	FocusFollowsMouse       bool
+	FocusFollowsMouse         bool
 	WorkspaceAutoBackAndForth bool
+
+	// Animation settings
+	AnimationsOff     bool
+	AnimationSlowdown float64      // 1 is normal speed, higher is slower
+	Animations        []*Animation // every animation niri has, in its order
+
+	// Key bindings from every binds block
+	Binds        []*Bind
+	removedBinds []*Bind
+	ModKey       string // key Mod stands for, from input mod-key
+
+	// Programs started with niri, in order
+	Startup        []*StartupEntry
+	removedStartup []*StartupEntry
+
+	// Monitors with an output block, in config order
+	Outputs []*Output
+
+	// Window rules added since loading, in the order they were added
+	addedRules []*WindowRule
+
+	tree *configTree // parsed files, kept for lossless saving
+	base *NiriConfig // values as last loaded or saved
 }
 
 // DefaultNiriConfig returns a config with default values
@@ -42,239 +55,288 @@ func DefaultNiriConfig() *NiriConfig {
 		ShadowSpread:              10,
 		FocusFollowsMouse:         true,
 		WorkspaceAutoBackAndForth: true,
+		AnimationSlowdown:         1,
+		Animations:                DefaultAnimations(),
+		ModKey:                    "Super",
 	}
 }
 
-// GetConfigPath returns the default Niri config path
+// GetConfigPath returns the Niri config path niri itself would use
 func GetConfigPath() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".config", "niri", "config.kdl")
+	return ResolveConfigPath("").Path
 }
 
-// LoadNiriConfig loads and parses the Niri configuration
+// LoadNiriConfig loads and parses the Niri configuration, following
+// include directives into other files
 func LoadNiriConfig(path string) (*NiriConfig, error) {
 	config := DefaultNiriConfig()
 	config.Path = path
 
-	file, err := os.Open(path)
+	tree, err := loadTree(path)
 	if err != nil {
 		return config, err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	inLayout := false
-	inShadow := false
-	inInput := false
-	inBorder := false
-	inFocusRing := false
-	braceDepth := 0
+	config.apply(tree)
+	config.tree = tree
+	config.snapshot()
+	return config, nil
+}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+// Files returns the paths of the main config file and every file it
+// includes
+func (c *NiriConfig) Files() []string {
+	if c.tree == nil {
+		return []string{c.Path}
+	}
+	var paths []string
+	for _, f := range c.tree.files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
 
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "//") {
-			continue
-		}
+// apply fills the config from the parsed config files
+func (c *NiriConfig) apply(t *configTree) {
+	c.FocusFollowsMouse = t.flagState("focus-follows-mouse", "input") == FlagPresent
+	c.WorkspaceAutoBackAndForth = t.flagState("workspace-auto-back-and-forth", "input") == FlagPresent
 
-		// Track brace depth
-		braceDepth += strings.Count(line, "{") - strings.Count(line, "}")
+	// Shadows are off unless the shadow block has an `on` flag
+	c.ShadowEnabled = false
 
-		// Track sections
-		if strings.HasPrefix(line, "layout") && strings.Contains(line, "{") {
-			inLayout = true
-			continue
-		}
-		if strings.HasPrefix(line, "input") && strings.Contains(line, "{") {
-			inInput = true
-			continue
-		}
-		if inLayout && strings.HasPrefix(line, "shadow") && strings.Contains(line, "{") {
-			inShadow = true
-			continue
+	for _, layout := range t.NodesNamed("layout") {
+		if val, ok := layout.IntArg("gaps"); ok {
+			c.Gaps = val
 		}
-		if inLayout && strings.HasPrefix(line, "border") && strings.Contains(line, "{") {
-			inBorder = true
-			continue
+		if val, ok := layout.Child("border").IntArg("width"); ok {
+			c.BorderWidth = val
 		}
-		if inLayout && strings.HasPrefix(line, "focus-ring") && strings.Contains(line, "{") {
-			inFocusRing = true
-			continue
+		if val, ok := layout.Child("focus-ring").IntArg("width"); ok {
+			c.FocusRingWidth = val
 		}
-
-		// Exit sections on closing brace
-		if line == "}" {
-			if inShadow {
-				inShadow = false
-			} else if inBorder {
-				inBorder = false
-			} else if inFocusRing {
-				inFocusRing = false
-			} else if inLayout && braceDepth == 0 {
-				inLayout = false
-			} else if inInput && braceDepth == 0 {
-				inInput = false
+		if shadow := layout.Child("shadow"); shadow != nil {
+			c.ShadowEnabled = flagState(shadow, "on") == FlagPresent && !shadow.HasChild("off")
+			if val, ok := shadow.IntArg("softness"); ok {
+				c.ShadowSoftness = val
 			}
-			continue
-		}
-
-		// Parse input section
-		if inInput {
-			if line == "focus-follows-mouse" {
-				config.FocusFollowsMouse = true
-			}
-			if line == "workspace-auto-back-and-forth" {
-				config.WorkspaceAutoBackAndForth = true
+			if val, ok := shadow.IntArg("spread"); ok {
+				c.ShadowSpread = val
 			}
 		}
+	}
 
-		// Parse layout section
-		if inLayout && !inShadow && !inBorder && !inFocusRing {
-			if val := parseIntValue(line, "gaps"); val != nil {
-				config.Gaps = *val
-			}
+	// Corner radius lives in window rules; the last rule setting it wins
+	for _, rule := range t.NodesNamed("window-rule") {
+		if val, ok := rule.IntArg("geometry-corner-radius"); ok {
+			c.CornerRadius = val
 		}
+	}
 
-		// Parse shadow section
-		if inShadow {
-			if line == "on" {
-				config.ShadowEnabled = true
-			}
-			if line == "off" {
-				config.ShadowEnabled = false
-			}
-			if val := parseIntValue(line, "softness"); val != nil {
-				config.ShadowSoftness = *val
-			}
-			if val := parseIntValue(line, "spread"); val != nil {
-				config.ShadowSpread = *val
-			}
-		}
+	c.loadAnimations(t)
+	c.loadBinds(t)
+	c.loadStartup(t)
+	c.loadOutputs(t)
+}
 
-		// Parse border section
-		if inBorder {
-			if val := parseIntValue(line, "width"); val != nil {
-				config.BorderWidth = *val
-			}
+// SaveNiriConfig saves the configuration back to its files.
+// Only settings that changed since loading are written, each to the file
+// that defines it. Existing nodes are updated in place, missing ones are
+// inserted, and everything else (comments, blank lines, indentation) is
+// left untouched.
+func SaveNiriConfig(config *NiriConfig) error {
+	if config.tree == nil {
+		tree, err := loadTree(config.Path)
+		if err != nil {
+			return err
 		}
+		config.tree = tree
+		base := DefaultNiriConfig()
+		base.apply(tree)
+		config.base = base
+	}
 
-		// Parse focus-ring section
-		if inFocusRing {
-			if val := parseIntValue(line, "width"); val != nil {
-				config.FocusRingWidth = *val
-			}
-		}
+	if err := config.checkShaders(); err != nil {
+		return err
+	}
+	if err := config.checkOutputs(); err != nil {
+		return err
+	}
+	// Let niri check the result before the config takes it as saved, so
+	// a rejected save leaves nothing behind
+	if err := config.dryRun().validate(); err != nil {
+		return err
+	}
+	config.write(config.tree)
+	if err := config.tree.save(); err != nil {
+		return err
+	}
+	config.snapshot()
+	return nil
+}
 
-		// Parse window-rule for corner-radius
-		if strings.Contains(line, "geometry-corner-radius") {
-			if val := parseIntValue(line, "geometry-corner-radius"); val != nil {
-				config.CornerRadius = *val
-			}
-		}
+// dryRun writes the pending changes into a copy of the config files and
+// returns it, leaving the files and every saved state untouched
+func (c *NiriConfig) dryRun() *configTree {
+	nodes := map[*Node]*Node{}
+	t := c.tree.clone(nodes)
+
+	d := *c
+	d.tree = t
+	d.Animations = make([]*Animation, len(c.Animations))
+	for i, a := range c.Animations {
+		copied := *a
+		d.Animations[i] = &copied
+	}
+	d.Binds = cloneBinds(c.Binds, nodes)
+	d.removedBinds = cloneBinds(c.removedBinds, nodes)
+	d.Startup = make([]*StartupEntry, len(c.Startup))
+	for i, e := range c.Startup {
+		copied := *e
+		d.Startup[i] = &copied
 	}
+	d.Outputs = make([]*Output, len(c.Outputs))
+	for i, o := range c.Outputs {
+		copied := *o
+		copied.node = nodes[o.node]
+		d.Outputs[i] = &copied
+	}
+	d.addedRules = make([]*WindowRule, len(c.addedRules))
+	for i, r := range c.addedRules {
+		copied := *r
+		copied.node = nodes[r.node]
+		d.addedRules[i] = &copied
+	}
+
+	d.write(t)
+	return t
+}
+
+// cloneBinds copies binds, pointing them at the cloned nodes
+func cloneBinds(binds []*Bind, nodes map[*Node]*Node) []*Bind {
+	out := make([]*Bind, len(binds))
+	for i, b := range binds {
+		copied := *b
+		copied.node, copied.block = nodes[b.node], nodes[b.block]
+		out[i] = &copied
+	}
+	return out
+}
 
-	return config, scanner.Err()
+// snapshot records the current values as the saved state
+func (c *NiriConfig) snapshot() {
+	base := *c
+	base.tree, base.base = nil, nil
+	c.base = &base
 }
 
-// parseIntValue extracts an integer value from a KDL property line
-func parseIntValue(line, key string) *int {
-	// Match patterns like "gaps 10" or "width 2"
-	pattern := regexp.MustCompile(fmt.Sprintf(`^\s*%s\s+(\d+)`, regexp.QuoteMeta(key)))
-	matches := pattern.FindStringSubmatch(line)
-	if len(matches) >= 2 {
-		val, err := strconv.Atoi(matches[1])
-		if err == nil {
-			return &val
+// write applies the settings that differ from the saved state
+func (c *NiriConfig) write(t *configTree) {
+	base := c.base
+
+	if c.Gaps != base.Gaps {
+		t.ensure("layout", "gaps").SetArgs(IntValue(c.Gaps))
+	}
+	if c.BorderWidth != base.BorderWidth {
+		t.ensure("layout", "border", "width").SetArgs(IntValue(c.BorderWidth))
+	}
+	if c.FocusRingWidth != base.FocusRingWidth {
+		t.ensure("layout", "focus-ring", "width").SetArgs(IntValue(c.FocusRingWidth))
+	}
+
+	if c.ShadowEnabled != base.ShadowEnabled {
+		t.setFlag(c.ShadowEnabled, "on", "layout", "shadow")
+		if c.ShadowEnabled {
+			t.setFlag(false, "off", "layout", "shadow")
 		}
 	}
-	return nil
+	if c.ShadowSoftness != base.ShadowSoftness {
+		t.ensure("layout", "shadow", "softness").SetArgs(IntValue(c.ShadowSoftness))
+	}
+	if c.ShadowSpread != base.ShadowSpread {
+		t.ensure("layout", "shadow", "spread").SetArgs(IntValue(c.ShadowSpread))
+	}
+
+	if c.CornerRadius != base.CornerRadius {
+		c.writeCornerRadius(t)
+	}
+
+	if c.FocusFollowsMouse != base.FocusFollowsMouse {
+		t.setFlag(c.FocusFollowsMouse, "focus-follows-mouse", "input")
+	}
+	if c.WorkspaceAutoBackAndForth != base.WorkspaceAutoBackAndForth {
+		t.setFlag(c.WorkspaceAutoBackAndForth, "workspace-auto-back-and-forth", "input")
+	}
+
+	c.writeAnimations(t)
+	c.writeBinds(t)
+	c.writeStartup(t)
+	c.writeOutputs(t)
+	c.writeWindowRules(t)
 }
 
-// SaveNiriConfig saves the configuration back to the file
-// This performs surgical updates to preserve comments and formatting
-func SaveNiriConfig(config *NiriConfig) error {
-	content, err := os.ReadFile(config.Path)
-	if err != nil {
-		return err
+// writeCornerRadius updates the window rule that sets the corner radius,
+// adding a catch-all rule if none does
+func (c *NiriConfig) writeCornerRadius(t *configTree) {
+	if radii := t.lookup("window-rule", "geometry-corner-radius"); len(radii) > 0 {
+		radii[len(radii)-1].SetArgs(IntValue(c.CornerRadius))
+		return
 	}
 
-	lines := strings.Split(string(content), "\n")
-	inLayout := false
-	inShadow := false
-	inBorder := false
-	inFocusRing := false
+	rule := NewNode("window-rule")
+	t.main().Doc.AppendNode(rule)
+	rule.AppendChild(NewNode("geometry-corner-radius", IntValue(c.CornerRadius)))
+}
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
+// FlagState describes how a flag node such as focus-follows-mouse
+// appears in a block
+type FlagState int
 
-		// Track sections
-		if strings.HasPrefix(trimmed, "layout") && strings.Contains(trimmed, "{") {
-			inLayout = true
-			continue
-		}
-		if inLayout && strings.HasPrefix(trimmed, "shadow") && strings.Contains(trimmed, "{") {
-			inShadow = true
-			continue
-		}
-		if inLayout && strings.HasPrefix(trimmed, "border") && strings.Contains(trimmed, "{") {
-			inBorder = true
-			continue
-		}
-		if inLayout && strings.HasPrefix(trimmed, "focus-ring") && strings.Contains(trimmed, "{") {
-			inFocusRing = true
-			continue
-		}
+const (
+	FlagAbsent FlagState = iota
+	FlagPresent
+	FlagCommented
+)
 
-		// Handle closing braces
-		if trimmed == "}" {
-			if inShadow {
-				inShadow = false
-			} else if inBorder {
-				inBorder = false
-			} else if inFocusRing {
-				inFocusRing = false
-			} else if inLayout {
-				inLayout = false
-			}
-			continue
+// flagState reports whether a flag child is present, commented out or
+// missing. A nil parent means the whole block is missing.
+func flagState(parent *Node, name string) FlagState {
+	if parent == nil {
+		return FlagAbsent
+	}
+	if parent.HasChild(name) {
+		return FlagPresent
+	}
+	for _, cn := range parent.CommentedChildren() {
+		if cn.Node.Name == name {
+			return FlagCommented
 		}
+	}
+	return FlagAbsent
+}
 
-		// Update values
-		if inLayout && !inShadow && !inBorder && !inFocusRing {
-			lines[i] = updateIntValue(line, "gaps", config.Gaps)
-		}
-		if inShadow {
-			lines[i] = updateIntValue(line, "softness", config.ShadowSoftness)
-			lines[i] = updateIntValue(lines[i], "spread", config.ShadowSpread)
-			// Handle on/off
-			if trimmed == "on" || trimmed == "off" {
-				indent := line[:len(line)-len(trimmed)]
-				if config.ShadowEnabled {
-					lines[i] = indent + "on"
-				} else {
-					lines[i] = indent + "off"
-				}
-			}
-		}
-		if inBorder {
-			lines[i] = updateIntValue(line, "width", config.BorderWidth)
-		}
-		if inFocusRing {
-			lines[i] = updateIntValue(line, "width", config.FocusRingWidth)
+// setFlag turns a flag child on or off. Turning a flag off comments it
+// out, keeping any properties it had; turning it on restores the last
+// commented-out copy if there is one and adds the node otherwise.
+func setFlag(parent *Node, name string, on bool) {
+	if !on {
+		for _, c := range parent.ChildrenNamed(name) {
+			parent.CommentOutChild(c)
 		}
+		return
+	}
+	if parent.HasChild(name) {
+		return
+	}
 
-		// Update corner radius in window-rule
-		if strings.Contains(trimmed, "geometry-corner-radius") {
-			lines[i] = updateIntValue(line, "geometry-corner-radius", config.CornerRadius)
+	var last *CommentedNode
+	for _, cn := range parent.CommentedChildren() {
+		if cn.Node.Name == name {
+			last = cn
 		}
 	}
-
-	return os.WriteFile(config.Path, []byte(strings.Join(lines, "\n")), 0644)
-}
-
-// updateIntValue updates an integer value in a line while preserving formatting
-func updateIntValue(line, key string, value int) string {
-	pattern := regexp.MustCompile(fmt.Sprintf(`(\s*%s\s+)\d+`, regexp.QuoteMeta(key)))
-	return pattern.ReplaceAllString(line, fmt.Sprintf("${1}%d", value))
+	if last != nil {
+		parent.UncommentChild(last)
+		return
+	}
+	parent.AppendChild(NewNode(name))
 }
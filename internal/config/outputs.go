@@ -0,0 +1,312 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OutputTransforms are the transforms niri accepts, in the order the
+// editor cycles through them
+var OutputTransforms = []string{
+	"normal", "90", "180", "270",
+	"flipped", "flipped-90", "flipped-180", "flipped-270",
+}
+
+// Output is an output block, configuring one monitor by its connector
+// name (eDP-1) or its make, model and serial
+type Output struct {
+	Name string
+	outputSettings
+
+	node  *Node          // the output block, nil until first saved
+	file  string         // file defining the block
+	saved outputSettings // values as last loaded or saved
+}
+
+// outputSettings is the part of an output written to the config
+type outputSettings struct {
+	Off       bool
+	Mode      string  // WIDTHxHEIGHT or WIDTHxHEIGHT@REFRESH, "" for niri's pick
+	Scale     float64 // 0 to let niri pick
+	Transform string  // "" for normal
+
+	Positioned bool // whether position is set; niri places the output otherwise
+	X, Y       int  // logical position
+
+	VariableRefreshRate bool
+	VRROnDemand         bool // only enable VRR when a window rule asks for it
+	FocusAtStartup      bool
+	BackgroundColor     string
+}
+
+// File returns the path of the file defining the output, or "" if it
+// hasn't been saved yet
+func (o *Output) File() string {
+	return o.file
+}
+
+// OutputMode is a parsed output mode
+type OutputMode struct {
+	Width, Height int
+	Refresh       float64 // Hz, 0 if the mode doesn't say
+}
+
+var outputModePattern = regexp.MustCompile(`^(\d+)x(\d+)(?:@(\d+(?:\.\d+)?))?$`)
+
+// ParseOutputMode parses a mode such as 1920x1080 or 2560x1440@143.912
+func ParseOutputMode(s string) (OutputMode, error) {
+	m := outputModePattern.FindStringSubmatch(s)
+	if m == nil {
+		return OutputMode{}, fmt.Errorf("mode %q must look like 1920x1080 or 1920x1080@60", s)
+	}
+	var mode OutputMode
+	mode.Width, _ = strconv.Atoi(m[1])
+	mode.Height, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		mode.Refresh, _ = strconv.ParseFloat(m[3], 64)
+	}
+	if mode.Width == 0 || mode.Height == 0 || (m[3] != "" && mode.Refresh == 0) {
+		return OutputMode{}, fmt.Errorf("mode %q has a zero size or refresh rate", s)
+	}
+	return mode, nil
+}
+
+// assumedMode stands in for the mode of outputs that don't set one
+var assumedMode = OutputMode{Width: 1920, Height: 1080}
+
+// LogicalSize returns the size the output takes up in niri's layout: its
+// mode turned by the transform and divided by the scale. Without a mode
+// or scale set, niri picks them from the monitor; 1920x1080 at scale 1
+// is assumed and known is false.
+func (o *Output) LogicalSize() (w, h int, known bool) {
+	mode, err := ParseOutputMode(o.Mode)
+	known = err == nil && o.Scale > 0
+	if err != nil {
+		mode = assumedMode
+	}
+	scale := o.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+
+	w, h = mode.Width, mode.Height
+	switch o.Transform {
+	case "90", "270", "flipped-90", "flipped-270":
+		w, h = h, w
+	}
+	return int(math.Round(float64(w) / scale)), int(math.Round(float64(h) / scale)), known
+}
+
+var outputColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// Validate checks the output's settings the way niri would
+func (o *Output) Validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return fmt.Errorf("output has no name")
+	}
+	if o.Mode != "" {
+		if _, err := ParseOutputMode(o.Mode); err != nil {
+			return err
+		}
+	}
+	if o.Scale < 0 || o.Scale > 10 {
+		return fmt.Errorf("scale %g must be above 0 and at most 10", o.Scale)
+	}
+	if o.Transform != "" && !slices.Contains(OutputTransforms, o.Transform) {
+		return fmt.Errorf("unknown transform %q", o.Transform)
+	}
+	if strings.HasPrefix(o.BackgroundColor, "#") && !outputColorPattern.MatchString(o.BackgroundColor) {
+		return fmt.Errorf("background color %q isn't a hex color", o.BackgroundColor)
+	}
+	return nil
+}
+
+// checkOutputs validates every output before saving
+func (c *NiriConfig) checkOutputs() error {
+	for _, o := range c.Outputs {
+		if err := o.Validate(); err != nil {
+			return fmt.Errorf("output %s: %w", o.Name, err)
+		}
+	}
+	return nil
+}
+
+// Output returns the output configured for a name, or nil. Names match
+// without regard to case, as in niri.
+func (c *NiriConfig) Output(name string) *Output {
+	for _, o := range c.Outputs {
+		if strings.EqualFold(o.Name, name) {
+			return o
+		}
+	}
+	return nil
+}
+
+// AddOutput adds an output block for a monitor that has none
+func (c *NiriConfig) AddOutput(name string) (*Output, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("output has no name")
+	}
+	if c.Output(name) != nil {
+		return nil, fmt.Errorf("output %s is already configured", name)
+	}
+	o := &Output{Name: name}
+	c.Outputs = append(c.Outputs, o)
+	return o, nil
+}
+
+// loadOutputs reads the output blocks. A later block for the same
+// output overrides an earlier one.
+func (c *NiriConfig) loadOutputs(t *configTree) {
+	c.Outputs = nil
+	for _, n := range t.NodesNamed("output") {
+		v, _ := n.Arg(0)
+		name, ok := v.AsString()
+		if !ok || name == "" {
+			continue
+		}
+		o := c.Output(name)
+		if o == nil {
+			o = &Output{Name: name}
+			c.Outputs = append(c.Outputs, o)
+		}
+		o.node = n
+		if f := t.fileOf(n); f != nil {
+			o.file = f.Path
+		}
+		o.load(n)
+		o.saved = o.outputSettings
+	}
+}
+
+// load reads an output block
+func (o *Output) load(n *Node) {
+	o.Off = flagState(n, "off") == FlagPresent
+	if val, ok := n.StringArg("mode"); ok {
+		o.Mode = val
+	}
+	if val, ok := n.FloatArg("scale"); ok {
+		o.Scale = val
+	}
+	if val, ok := n.StringArg("transform"); ok {
+		o.Transform = val
+	}
+	if pos := n.Child("position"); pos != nil {
+		o.Positioned = true
+		if v, ok := pos.Prop("x"); ok {
+			o.X, _ = v.AsInt()
+		}
+		if v, ok := pos.Prop("y"); ok {
+			o.Y, _ = v.AsInt()
+		}
+	}
+	if vrr := n.Child("variable-refresh-rate"); vrr != nil {
+		o.VariableRefreshRate = true
+		if v, ok := vrr.Prop("on-demand"); ok {
+			o.VRROnDemand, _ = v.AsBool()
+		}
+	}
+	o.FocusAtStartup = n.HasChild("focus-at-startup")
+	if val, ok := n.StringArg("background-color"); ok {
+		o.BackgroundColor = val
+	}
+}
+
+// writeOutputs writes the outputs that changed, adding blocks for new
+// ones after the last existing output block
+func (c *NiriConfig) writeOutputs(t *configTree) {
+	for _, o := range c.Outputs {
+		if o.node == nil {
+			o.node = NewNode("output", StringValue(o.Name))
+			insertAfterLast(t, "output", o.node)
+			if f := t.fileOf(o.node); f != nil {
+				o.file = f.Path
+			}
+		} else if o.outputSettings == o.saved {
+			continue
+		}
+		o.write(o.node)
+		o.saved = o.outputSettings
+	}
+}
+
+// insertAfterLast adds a top-level node after the last node with the
+// given name, or at the end of the main file if there is none
+func insertAfterLast(t *configTree, name string, n *Node) {
+	if last := t.Node(name); last != nil {
+		f := t.fileOf(last)
+		f.Doc.InsertNode(slices.Index(f.Doc.Nodes, last)+1, n)
+		n.leading = "\n" + n.leading
+		return
+	}
+	t.main().Doc.AppendNode(n)
+}
+
+// write syncs an output block with the settings that differ from the
+// saved ones
+func (o *Output) write(n *Node) {
+	s := o.saved
+	if o.Off != s.Off {
+		setFlag(n, "off", o.Off)
+	}
+	if o.Mode != s.Mode {
+		writeStringChild(n, "mode", o.Mode)
+	}
+	if o.Scale != s.Scale {
+		if o.Scale > 0 {
+			n.EnsureChild("scale").SetArgs(FloatValue(o.Scale))
+		} else {
+			removeChildren(n, "scale")
+		}
+	}
+	if o.Transform != s.Transform {
+		writeStringChild(n, "transform", o.Transform)
+	}
+	if o.Positioned != s.Positioned || o.X != s.X || o.Y != s.Y {
+		if o.Positioned {
+			pos := n.EnsureChild("position")
+			pos.SetProp("x", IntValue(o.X))
+			pos.SetProp("y", IntValue(o.Y))
+		} else {
+			removeChildren(n, "position")
+		}
+	}
+	if o.VariableRefreshRate != s.VariableRefreshRate || o.VRROnDemand != s.VRROnDemand {
+		setFlag(n, "variable-refresh-rate", o.VariableRefreshRate)
+		if vrr := n.Child("variable-refresh-rate"); vrr != nil {
+			if o.VRROnDemand {
+				vrr.SetProp("on-demand", BoolValue(true))
+			} else {
+				vrr.RemoveProp("on-demand")
+			}
+		}
+	}
+	if o.FocusAtStartup != s.FocusAtStartup {
+		setFlag(n, "focus-at-startup", o.FocusAtStartup)
+	}
+	if o.BackgroundColor != s.BackgroundColor {
+		writeStringChild(n, "background-color", o.BackgroundColor)
+	}
+}
+
+// writeStringChild sets a child's string argument, removing the child
+// when the value is empty
+func writeStringChild(n *Node, name, value string) {
+	if value == "" {
+		removeChildren(n, name)
+		return
+	}
+	n.EnsureChild(name).SetArgs(StringValue(value))
+}
+
+// removeChildren removes every child with the given name
+func removeChildren(n *Node, name string) {
+	for _, c := range n.ChildrenNamed(name) {
+		n.RemoveChild(c)
+	}
+}
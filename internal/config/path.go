@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// SystemConfigPath is niri's system-wide fallback config
+const SystemConfigPath = "/etc/niri/config.kdl"
+
+// ConfigSource identifies where the active config path came from
+type ConfigSource int
+
+const (
+	SourceFlag   ConfigSource = iota // --config command-line flag
+	SourceEnv                        // $NIRI_CONFIG
+	SourceUser                       // $XDG_CONFIG_HOME/niri/config.kdl
+	SourceSystem                     // /etc/niri/config.kdl
+)
+
+func (s ConfigSource) String() string {
+	switch s {
+	case SourceFlag:
+		return "--config"
+	case SourceEnv:
+		return "$NIRI_CONFIG"
+	case SourceSystem:
+		return "system"
+	default:
+		return "user"
+	}
+}
+
+// ConfigLocation is a resolved config path and where it came from
+type ConfigLocation struct {
+	Path   string
+	Source ConfigSource
+}
+
+// ResolveConfigPath finds the config file the same way niri does: an
+// explicit path (override, then $NIRI_CONFIG) wins; otherwise the user
+// config under $XDG_CONFIG_HOME is used if it exists, then
+// /etc/niri/config.kdl. When neither exists the user path is returned,
+// since that is where niri creates its default config.
+func ResolveConfigPath(override string) ConfigLocation {
+	if override != "" {
+		return ConfigLocation{Path: override, Source: SourceFlag}
+	}
+	if env := os.Getenv("NIRI_CONFIG"); env != "" {
+		return ConfigLocation{Path: env, Source: SourceEnv}
+	}
+
+	user := filepath.Join(userConfigDir(), "niri", "config.kdl")
+	if _, err := os.Stat(user); err == nil {
+		return ConfigLocation{Path: user, Source: SourceUser}
+	}
+	if _, err := os.Stat(SystemConfigPath); err == nil {
+		return ConfigLocation{Path: SystemConfigPath, Source: SourceSystem}
+	}
+	return ConfigLocation{Path: user, Source: SourceUser}
+}
+
+// userConfigDir returns $XDG_CONFIG_HOME, falling back to ~/.config
+func userConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config")
+}
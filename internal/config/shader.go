@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// shaderEntryPoints maps the animations that take a custom-shader to the
+// function niri calls in it
+var shaderEntryPoints = map[string]string{
+	"window-open":   "open_color",
+	"window-close":  "close_color",
+	"window-resize": "resize_color",
+}
+
+// shaderTemplates are starting points for new shaders, doing what niri
+// does without one
+var shaderTemplates = map[string]string{
+	"window-open": `vec4 open_color(vec3 coords_geo, vec3 size_geo) {
+    vec3 coords_tex = niri_geo_to_tex * coords_geo;
+    vec4 color = texture2D(niri_tex, coords_tex.st);
+    return color * niri_clamped_progress;
+}
+`,
+	"window-close": `vec4 close_color(vec3 coords_geo, vec3 size_geo) {
+    vec3 coords_tex = niri_geo_to_tex * coords_geo;
+    vec4 color = texture2D(niri_tex, coords_tex.st);
+    return color * (1.0 - niri_clamped_progress);
+}
+`,
+	"window-resize": `vec4 resize_color(vec3 coords_curr_geo, vec3 size_curr_geo) {
+    vec3 coords_tex_next = niri_geo_to_tex_next * coords_curr_geo;
+    return texture2D(niri_tex_next, coords_tex_next.st);
+}
+`,
+}
+
+// ShaderEntryPoint returns the function a custom shader for the
+// animation must define, or "" if the animation takes no shader
+func (a *Animation) ShaderEntryPoint() string {
+	return shaderEntryPoints[a.Name]
+}
+
+// ShaderSource returns the animation's shader, or a template to start a
+// new one from
+func (a *Animation) ShaderSource() string {
+	if a.CustomShader != "" {
+		return a.CustomShader
+	}
+	return shaderTemplates[a.Name]
+}
+
+// SetCustomShader sets the shader from edited source. Source that is
+// only whitespace removes the shader.
+func (a *Animation) SetCustomShader(src string) {
+	a.CustomShader = dedentShader(src)
+}
+
+// shaderComments matches GLSL comments
+var shaderComments = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+
+// CheckShader runs basic checks on shader source for the animation: the
+// entry point niri calls is defined and brackets balance. It can't tell
+// whether the GLSL compiles.
+func (a *Animation) CheckShader(src string) error {
+	entry := a.ShaderEntryPoint()
+	if entry == "" {
+		return fmt.Errorf("%s doesn't take a custom shader", a.Name)
+	}
+	code := shaderComments.ReplaceAllString(src, "")
+
+	definition := regexp.MustCompile(`\bvec4\s+` + entry + `\s*\(`)
+	if !definition.MatchString(code) {
+		return fmt.Errorf("%s shader must define vec4 %s(...)", a.Name, entry)
+	}
+	for _, pair := range []string{"()", "{}", "[]"} {
+		if strings.Count(code, pair[:1]) != strings.Count(code, pair[1:]) {
+			return fmt.Errorf("%s shader has unbalanced %s", a.Name, pair)
+		}
+	}
+	return nil
+}
+
+// checkShaders checks the shaders changed since the last save
+func (c *NiriConfig) checkShaders() error {
+	for _, a := range c.Animations {
+		if a.CustomShader != "" && a.CustomShader != a.savedShader {
+			if err := a.CheckShader(a.CustomShader); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeShader writes the custom-shader node as a raw string indented
+// under it, or removes the node if the shader was cleared
+func (a *Animation) writeShader(n *Node) {
+	if a.CustomShader == "" {
+		for _, c := range n.ChildrenNamed("custom-shader") {
+			n.RemoveChild(c)
+		}
+		return
+	}
+
+	shader := n.EnsureChild("custom-shader")
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, line := range strings.Split(strings.TrimSuffix(a.CustomShader, "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			b.WriteString(shader.childIndent() + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(shader.indent())
+	shader.SetArgs(Value{Kind: KindString, Str: b.String(), Raw: true})
+}
+
+// dedentShader strips the blank first and last lines of a shader raw
+// string and the indentation its lines share
+func dedentShader(src string) string {
+	lines := strings.Split(src, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	prefix, first := "", true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(strings.TrimPrefix(line, prefix), " \t")
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,416 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Node names of startup entries
+const (
+	spawnAtStartup   = "spawn-at-startup"
+	spawnShAtStartup = "spawn-sh-at-startup"
+)
+
+// StartupEntry is a spawn-at-startup or spawn-sh-at-startup node. Entries
+// that are commented out in the config are kept as disabled entries.
+type StartupEntry struct {
+	Command []string // program and arguments, or one shell command line
+	Shell   bool     // spawn-sh-at-startup, run with sh -c
+	Enabled bool
+
+	slot  int          // position among the startup nodes when loaded, -1 if new
+	saved startupState // values as last loaded or saved
+}
+
+// startupState is the part of an entry that is written to the config
+type startupState struct {
+	command string // NUL-joined, so states compare with ==
+	shell   bool
+	enabled bool
+}
+
+func (e *StartupEntry) state() startupState {
+	return startupState{strings.Join(e.Command, "\x00"), e.Shell, e.Enabled}
+}
+
+// NewStartupEntry creates an enabled entry that runs a program
+func NewStartupEntry(command ...string) *StartupEntry {
+	return &StartupEntry{Command: command, Enabled: true, slot: -1}
+}
+
+// NodeName returns the node the entry is written as
+func (e *StartupEntry) NodeName() string {
+	if e.Shell {
+		return spawnShAtStartup
+	}
+	return spawnAtStartup
+}
+
+// CommandLine formats the command for display, quoting arguments that
+// contain spaces
+func (e *StartupEntry) CommandLine() string {
+	if e.Shell {
+		return strings.Join(e.Command, " ")
+	}
+	parts := make([]string, len(e.Command))
+	for i, arg := range e.Command {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			arg = strconv.Quote(arg)
+		}
+		parts[i] = arg
+	}
+	return strings.Join(parts, " ")
+}
+
+// ArgsString formats the command as KDL arguments, as in
+// `"qs" "-c" "noctalia-shell"`
+func (e *StartupEntry) ArgsString() string {
+	return StringArgs(e.Command)
+}
+
+// Program returns the program the entry runs. For shell entries this is
+// the first word of the command line.
+func (e *StartupEntry) Program() string {
+	if len(e.Command) == 0 {
+		return ""
+	}
+	if e.Shell {
+		fields := strings.Fields(e.Command[0])
+		if len(fields) == 0 {
+			return ""
+		}
+		return fields[0]
+	}
+	return e.Command[0]
+}
+
+// ProgramFound reports whether the program is on PATH or is a path to
+// an executable
+func (e *StartupEntry) ProgramFound() bool {
+	prog := e.Program()
+	return prog != "" && programExists(prog)
+}
+
+// AddStartup appends an entry to the startup list
+func (c *NiriConfig) AddStartup(e *StartupEntry) {
+	c.Startup = append(c.Startup, e)
+}
+
+// RemoveStartup removes an entry; its node goes on the next save
+func (c *NiriConfig) RemoveStartup(e *StartupEntry) {
+	i := slices.Index(c.Startup, e)
+	if i < 0 {
+		return
+	}
+	c.Startup = slices.Delete(c.Startup, i, i+1)
+	if e.slot >= 0 {
+		c.removedStartup = append(c.removedStartup, e)
+	}
+}
+
+// MoveStartup moves an entry delta places up or down the list, reporting
+// whether it moved
+func (c *NiriConfig) MoveStartup(e *StartupEntry, delta int) bool {
+	i := slices.Index(c.Startup, e)
+	j := i + delta
+	if i < 0 || j < 0 || j >= len(c.Startup) {
+		return false
+	}
+	c.Startup = slices.Delete(c.Startup, i, i+1)
+	c.Startup = slices.Insert(c.Startup, j, e)
+	return true
+}
+
+// startupSlot is a startup node in the config, live or commented out
+type startupSlot struct {
+	file      *ConfigFile
+	node      *Node          // live node, nil if commented out
+	commented *CommentedNode // commented-out node, nil if live
+}
+
+// entry returns the node behind the slot, live or not
+func (s startupSlot) entry() *Node {
+	if s.node != nil {
+		return s.node
+	}
+	return s.commented.Node
+}
+
+// matches reports whether the slot already holds the entry as written
+func (s startupSlot) matches(e *StartupEntry) bool {
+	n := s.entry()
+	if n.Name != e.NodeName() || (s.node != nil) != e.Enabled {
+		return false
+	}
+	return slices.Equal(startupCommand(n), e.Command)
+}
+
+// isStartupNode reports whether a node is a startup entry
+func isStartupNode(n *Node) bool {
+	return n.Name == spawnAtStartup || n.Name == spawnShAtStartup
+}
+
+// startupSlots returns every startup node, live or commented out, in the
+// order niri would run them
+func (t *configTree) startupSlots() []startupSlot {
+	var slots []startupSlot
+	var walk func(f *ConfigFile, seen map[*ConfigFile]bool)
+	walk = func(f *ConfigFile, seen map[*ConfigFile]bool) {
+		seen[f] = true
+		commented := f.Doc.CommentedNodes()
+		for i := 0; i <= len(f.Doc.Nodes); i++ {
+			for _, cn := range commented {
+				if cn.index == i && isStartupNode(cn.Node) {
+					slots = append(slots, startupSlot{file: f, commented: cn})
+				}
+			}
+			if i == len(f.Doc.Nodes) {
+				break
+			}
+			n := f.Doc.Nodes[i]
+			if inc, ok := t.includes[n]; ok {
+				if !seen[inc] {
+					walk(inc, seen)
+				}
+				continue
+			}
+			if isStartupNode(n) {
+				slots = append(slots, startupSlot{file: f, node: n})
+			}
+		}
+		delete(seen, f)
+	}
+	walk(t.main(), map[*ConfigFile]bool{})
+	return slots
+}
+
+// startupCommand returns the arguments of a startup node as strings
+func startupCommand(n *Node) []string {
+	var command []string
+	for _, arg := range n.Args() {
+		if s, ok := arg.AsString(); ok {
+			command = append(command, s)
+		} else {
+			command = append(command, arg.String())
+		}
+	}
+	return command
+}
+
+// loadStartup reads the startup entries, including commented-out ones
+func (c *NiriConfig) loadStartup(t *configTree) {
+	c.Startup = nil
+	c.removedStartup = nil
+	for i, s := range t.startupSlots() {
+		n := s.entry()
+		e := &StartupEntry{
+			Command: startupCommand(n),
+			Shell:   n.Name == spawnShAtStartup,
+			Enabled: s.node != nil,
+			slot:    i,
+		}
+		e.saved = e.state()
+		c.Startup = append(c.Startup, e)
+	}
+}
+
+// startupChanged reports whether the startup list differs from the
+// config files
+func (c *NiriConfig) startupChanged() bool {
+	if len(c.removedStartup) > 0 {
+		return true
+	}
+	for i, e := range c.Startup {
+		if e.slot != i || e.state() != e.saved {
+			return true
+		}
+	}
+	return false
+}
+
+// writeStartup writes the startup list back. Removed entries lose their
+// nodes, moved entries take their nodes and the comments above them
+// along, changed entries are rewritten in place, and new entries go
+// after the last startup node.
+func (c *NiriConfig) writeStartup(t *configTree) {
+	if !c.startupChanged() {
+		return
+	}
+
+	removed := map[int]bool{}
+	for _, e := range c.removedStartup {
+		removed[e.slot] = true
+	}
+	slots := t.startupSlots()
+	for i := len(slots) - 1; i >= 0; i-- {
+		if removed[i] {
+			removeStartupSlot(slots[i])
+		}
+	}
+	c.removedStartup = nil
+
+	// Where each loaded entry's node sits now that the removed ones are gone
+	var order []int
+	for _, e := range c.Startup {
+		if e.slot < 0 {
+			continue
+		}
+		at := e.slot
+		for i := range removed {
+			if i < e.slot {
+				at--
+			}
+		}
+		order = append(order, at)
+	}
+
+	// Entries fill the remaining nodes in order. If the list wasn't loaded
+	// from these files, leave their nodes alone and only add new entries.
+	slots = t.startupSlots()
+	loaded := 0
+	for _, e := range c.Startup {
+		if e.slot >= 0 {
+			loaded++
+		}
+	}
+	positional := loaded == len(slots)
+	if positional {
+		reorderStartup(t, order)
+	}
+
+	for i, e := range c.Startup {
+		switch {
+		case positional && i < len(slots):
+			writeStartupSlot(t, i, e)
+		case e.slot < 0 || positional:
+			appendStartup(t, e)
+		}
+	}
+
+	for i, e := range c.Startup {
+		e.slot = i
+		e.saved = e.state()
+	}
+}
+
+// removeStartupSlot deletes a startup node, live or commented out
+func removeStartupSlot(s startupSlot) {
+	n := s.node
+	if n == nil {
+		n = s.file.Doc.UncommentNode(s.commented)
+	}
+	s.file.Doc.RemoveNode(n)
+}
+
+// reorderStartup moves the startup nodes so that the node of slot
+// order[k] ends up k-th. A moved node takes the comments directly above
+// it along; blank lines and other trivia stay where they were.
+func reorderStartup(t *configTree, order []int) {
+	slots := t.startupSlots()
+	if len(order) != len(slots) {
+		return
+	}
+	moves := func(k int) bool { return order[k] != k }
+	moved := false
+	for k := range order {
+		moved = moved || moves(k)
+	}
+	if !moved {
+		return
+	}
+
+	// Open up the commented nodes that take part, last first so the
+	// positions of the earlier ones stay valid
+	nodes := make([]*Node, len(slots))
+	for i := len(slots) - 1; i >= 0; i-- {
+		nodes[i] = slots[i].node
+		if nodes[i] == nil && moves(i) {
+			nodes[i] = slots[i].file.Doc.UncommentNode(slots[i].commented)
+		}
+	}
+
+	type place struct {
+		doc      *Document
+		index    int
+		detached string
+	}
+	places := make([]place, len(slots))
+	attached := make([]string, len(slots))
+	for i, s := range slots {
+		if !moves(i) {
+			continue
+		}
+		doc := s.file.Doc
+		places[i] = place{doc: doc, index: slices.Index(doc.Nodes, nodes[i])}
+		places[i].detached, attached[i] = splitAttachedComments(nodes[i].leading)
+	}
+
+	for k, i := range order {
+		if !moves(k) {
+			continue
+		}
+		n := nodes[i]
+		n.leading = places[k].detached + attached[i]
+		if !hasTerminator(n.trailing) {
+			// The node ended its file; it needs a line of its own now
+			n.trailing += "\n"
+		}
+		places[k].doc.Nodes[places[k].index] = n
+	}
+}
+
+// writeStartupSlot writes an entry into the i-th startup node unless it
+// already holds it
+func writeStartupSlot(t *configTree, i int, e *StartupEntry) {
+	s := t.startupSlots()[i]
+	if s.matches(e) {
+		return
+	}
+
+	n := s.node
+	if n == nil {
+		n = s.file.Doc.UncommentNode(s.commented)
+	}
+	n.Name = e.NodeName()
+	n.SetArgs(startupArgs(e)...)
+	if !e.Enabled {
+		s.file.Doc.CommentOutNode(n)
+	}
+}
+
+// appendStartup adds a node for an entry after the last startup node, or
+// at the end of the main file if there is none
+func appendStartup(t *configTree, e *StartupEntry) {
+	n := NewNode(e.NodeName(), startupArgs(e)...)
+
+	slots := t.startupSlots()
+	if len(slots) == 0 {
+		t.main().Doc.AppendNode(n)
+	} else {
+		last := slots[len(slots)-1]
+		doc := last.file.Doc
+		anchor := last.node
+		if anchor == nil {
+			// Open the commented node up so the new one can follow it
+			anchor = doc.UncommentNode(last.commented)
+		}
+		doc.InsertNode(slices.Index(doc.Nodes, anchor)+1, n)
+		if last.node == nil {
+			doc.CommentOutNode(anchor)
+		}
+	}
+
+	if !e.Enabled {
+		t.fileOf(n).Doc.CommentOutNode(n)
+	}
+}
+
+// startupArgs returns the node arguments for an entry
+func startupArgs(e *StartupEntry) []Value {
+	args := make([]Value, len(e.Command))
+	for i, arg := range e.Command {
+		args[i] = StringValue(arg)
+	}
+	return args
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// contextLines is how many lines around an error ValidationError quotes
+const contextLines = 2
+
+// ValidationError is niri rejecting a config, located in its source
+type ValidationError struct {
+	File    string // empty when niri named no location
+	Line    int
+	Column  int
+	Message string
+	Source  []SourceLine // the lines around Line
+	Output  string       // everything niri printed
+}
+
+// SourceLine is a numbered line of a config file
+type SourceLine struct {
+	Number int
+	Text   string
+}
+
+func (e *ValidationError) Error() string {
+	if e.File == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	// niri's reports mark the location as ╭─[path:line:col]
+	errorLocation = regexp.MustCompile(`[\[(]([^\[\]()]+?):(\d+):(\d+)[\])]`)
+	// and label it with ╰── message, or `-- message without Unicode
+	errorLabel    = regexp.MustCompile("(?:╰──|`--) (.+)$")
+	errorHeadline = regexp.MustCompile(`(?:^|\s)(?:×|x) (.+)$`)
+)
+
+// ValidateConfig runs `niri validate` on a config file. It returns a
+// *ValidationError when niri rejects the file, and nil when it accepts
+// it or niri isn't installed to ask.
+func ValidateConfig(path string) error {
+	return validateStaged(path, nil)
+}
+
+// validateStaged validates the config at path. staged maps files written
+// only for validation to the files they stand in for, so errors point at
+// the real ones.
+func validateStaged(path string, staged map[string]string) error {
+	if _, err := exec.LookPath("niri"); err != nil {
+		return nil
+	}
+	cmd := exec.Command("niri", "validate", "-c", path)
+	cmd.Env = append(os.Environ(), "NO_COLOR=1")
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) {
+		return fmt.Errorf("running niri validate: %w", err)
+	}
+	return parseValidation(ansiEscape.ReplaceAllString(out.String(), ""), staged)
+}
+
+// parseValidation reads the location and message out of niri's report
+func parseValidation(output string, staged map[string]string) *ValidationError {
+	e := &ValidationError{Output: strings.TrimSpace(output)}
+	var headline string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if m := errorLocation.FindStringSubmatch(line); m != nil && e.File == "" {
+			e.File = m[1]
+			e.Line, _ = strconv.Atoi(m[2])
+			e.Column, _ = strconv.Atoi(m[3])
+		}
+		if m := errorLabel.FindStringSubmatch(line); m != nil && e.Message == "" {
+			e.Message = strings.TrimSpace(m[1])
+		}
+		if m := errorHeadline.FindStringSubmatch(line); m != nil {
+			headline = strings.TrimSpace(m[1])
+		}
+	}
+	if e.Message == "" {
+		e.Message = headline
+	}
+	if e.Message == "" {
+		lines := strings.Split(e.Output, "\n")
+		e.Message = strings.TrimSpace(lines[len(lines)-1])
+	}
+
+	if e.File != "" {
+		// Quote the file niri read, then name the file it stands in for
+		if content, err := os.ReadFile(e.File); err == nil {
+			e.Source = sourceContext(string(content), e.Line)
+		}
+		if real, ok := staged[e.File]; ok {
+			e.File = real
+		}
+	}
+	return e
+}
+
+// sourceContext returns the lines of content around a line
+func sourceContext(content string, line int) []SourceLine {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var out []SourceLine
+	for n := max(1, line-contextLines); n <= min(len(lines), line+contextLines); n++ {
+		out = append(out, SourceLine{Number: n, Text: strings.TrimRight(lines[n-1], "\r")})
+	}
+	return out
+}
+
+// validate has niri check the tree as it would be saved. Every file is
+// written next to the one it stands in for, so relative paths resolve
+// the same, with include nodes pointing at the written copies.
+func (t *configTree) validate() error {
+	changed := false
+	for _, f := range t.files {
+		if f.Doc.String() != f.saved {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	copies := map[*ConfigFile]string{}
+	staged := map[string]string{}
+	defer func() {
+		for path := range staged {
+			os.Remove(path)
+		}
+	}()
+	for _, f := range t.files {
+		dir, base := filepath.Split(f.Path)
+		tmp, err := os.CreateTemp(dir, "."+base+".nirimatic-*")
+		if err != nil {
+			// Can't write next to the config, so can't check it
+			return nil
+		}
+		tmp.Close()
+		copies[f] = tmp.Name()
+		staged[tmp.Name()] = f.Path
+	}
+
+	for _, f := range t.files {
+		content := t.renderStaged(f, copies)
+		if err := os.WriteFile(copies[f], []byte(content), 0644); err != nil {
+			return nil
+		}
+	}
+	return validateStaged(copies[t.main()], staged)
+}
+
+// renderStaged renders a file with its includes pointing at staged copies
+func (t *configTree) renderStaged(f *ConfigFile, copies map[*ConfigFile]string) string {
+	type swap struct {
+		entry *Entry
+		value Value
+	}
+	var swaps []swap
+	for _, n := range f.Doc.NodesNamed("include") {
+		inc, ok := t.includes[n]
+		if !ok {
+			continue
+		}
+		for _, e := range n.Entries {
+			if e.Key == "" {
+				swaps = append(swaps, swap{e, e.Value})
+				e.Value = StringValue(copies[inc])
+				break
+			}
+		}
+	}
+	out := f.Doc.String()
+	for _, s := range swaps {
+		s.entry.Value = s.value
+	}
+	return out
+}
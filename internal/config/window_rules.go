@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BlockOutTargets are the captures a window rule can hide a window from
+var BlockOutTargets = []string{"screencast", "screen-capture"}
+
+// WindowRule is a window-rule block added by nirimatic. Existing rules
+// are left as they are written.
+type WindowRule struct {
+	AppID string // regex the app id must match; empty to not match on it
+	Title string // regex the title must match; empty to not match on it
+
+	OpenFloating       *bool   // nil to leave it to niri
+	DefaultColumnWidth string  // 50% or 1200; empty to leave it to niri
+	Opacity            float64 // 0 to leave windows opaque
+	BlockOutFrom       string  // one of BlockOutTargets, or empty
+	OpenOnWorkspace    string  // a named workspace, or empty
+
+	node *Node // nil until written
+}
+
+// ExactRegex returns a regex matching exactly s
+func ExactRegex(s string) string {
+	return "^" + regexp.QuoteMeta(s) + "$"
+}
+
+// Validate checks that niri will accept the rule
+func (r *WindowRule) Validate() error {
+	if r.AppID == "" && r.Title == "" {
+		return fmt.Errorf("a rule needs an app id or a title to match")
+	}
+	for _, re := range []string{r.AppID, r.Title} {
+		if _, err := regexp.Compile(re); err != nil {
+			return fmt.Errorf("invalid regex %q: %v", re, err)
+		}
+	}
+	if r.DefaultColumnWidth != "" {
+		if _, _, err := parseColumnWidth(r.DefaultColumnWidth); err != nil {
+			return err
+		}
+	}
+	if r.Opacity < 0 || r.Opacity > 1 {
+		return fmt.Errorf("opacity must be between 0 and 1")
+	}
+	if r.BlockOutFrom != "" && !slices.Contains(BlockOutTargets, r.BlockOutFrom) {
+		return fmt.Errorf("block-out-from must be %s", strings.Join(BlockOutTargets, " or "))
+	}
+	return nil
+}
+
+// parseColumnWidth reads a width as 50% or 1200, returning the node niri
+// takes it as, proportion or fixed, and its value
+func parseColumnWidth(s string) (string, Value, error) {
+	if percent, ok := strings.CutSuffix(strings.TrimSpace(s), "%"); ok {
+		p, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return "", Value{}, fmt.Errorf("column width %q: use a percentage up to 100%% or pixels", s)
+		}
+		return "proportion", FloatValue(p / 100), nil
+	}
+	px, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || px <= 0 {
+		return "", Value{}, fmt.Errorf("column width %q: use a percentage like 50%% or pixels like 1200", s)
+	}
+	return "fixed", IntValue(px), nil
+}
+
+// SaveWindowRule adds a rule after the config's other rules and saves
+// the config. If the save fails the rule is dropped again, so a rejected
+// rule doesn't linger and get written by a later save.
+func (c *NiriConfig) SaveWindowRule(r WindowRule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	rule := &r
+	c.addedRules = append(c.addedRules, rule)
+	if err := SaveNiriConfig(c); err != nil {
+		c.addedRules = slices.DeleteFunc(c.addedRules, func(added *WindowRule) bool { return added == rule })
+		if rule.node != nil {
+			// Written to the tree but not to disk
+			if f := c.tree.fileOf(rule.node); f != nil {
+				f.Doc.RemoveNode(rule.node)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// writeWindowRules writes the rules added since loading
+func (c *NiriConfig) writeWindowRules(t *configTree) {
+	for _, r := range c.addedRules {
+		if r.node != nil {
+			continue
+		}
+		r.node = NewNode("window-rule")
+		insertAfterLast(t, "window-rule", r.node)
+		r.write(r.node)
+	}
+}
+
+// write fills in a new rule's block
+func (r *WindowRule) write(n *Node) {
+	match := NewNode("match")
+	n.AppendChild(match)
+	if r.AppID != "" {
+		match.SetProp("app-id", regexValue(r.AppID))
+	}
+	if r.Title != "" {
+		match.SetProp("title", regexValue(r.Title))
+	}
+
+	if r.OpenFloating != nil {
+		n.AppendChild(NewNode("open-floating", BoolValue(*r.OpenFloating)))
+	}
+	if r.DefaultColumnWidth != "" {
+		kind, v, _ := parseColumnWidth(r.DefaultColumnWidth)
+		width := NewNode("default-column-width")
+		n.AppendChild(width)
+		width.AppendChild(NewNode(kind, v))
+	}
+	if r.Opacity > 0 {
+		n.AppendChild(NewNode("opacity", FloatValue(r.Opacity)))
+	}
+	if r.BlockOutFrom != "" {
+		n.AppendChild(NewNode("block-out-from", StringValue(r.BlockOutFrom)))
+	}
+	if r.OpenOnWorkspace != "" {
+		n.AppendChild(NewNode("open-on-workspace", StringValue(r.OpenOnWorkspace)))
+	}
+}
+
+// regexValue writes a regex as a raw string when it has backslashes or
+// quotes, as niri's own config does
+func regexValue(re string) Value {
+	v := StringValue(re)
+	v.Raw = strings.ContainsAny(re, `\"`)
+	return v
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveWindowRuleDropsRuleOnFailedSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.kdl")
+	src := "window-rule {\n    match app-id=\"foot\"\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadNiriConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An output niri would reject makes the save fail
+	bad := &Output{Name: "eDP-1"}
+	bad.Transform = "sideways"
+	c.Outputs = append(c.Outputs, bad)
+
+	rule := WindowRule{AppID: ExactRegex("org.wezfurlong.wezterm")}
+	if err := c.SaveWindowRule(rule); err == nil {
+		t.Fatal("SaveWindowRule succeeded with an invalid output")
+	}
+	if len(c.addedRules) != 0 {
+		t.Fatalf("addedRules has %d rules after a failed save, want 0", len(c.addedRules))
+	}
+	if got, _ := os.ReadFile(path); string(got) != src {
+		t.Fatalf("config changed by a failed save:\n%s", got)
+	}
+
+	// Once the output is fixed, saving again writes the rule once
+	bad.Transform = "90"
+	if err := c.SaveWindowRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(got), "wezterm"); n != 1 {
+		t.Fatalf("rule written %d times, want once:\n%s", n, got)
+	}
+}
@@ -0,0 +1,181 @@
+package niriipc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Action is one of niri's actions, the same ones binds run. Name is the
+// variant, such as FocusWindow, and Fields its named fields.
+type Action struct {
+	Name   string
+	Fields map[string]any
+}
+
+// MarshalJSON encodes the action as {"Name": {fields}}
+func (a Action) MarshalJSON() ([]byte, error) {
+	fields := a.Fields
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	return json.Marshal(map[string]any{a.Name: fields})
+}
+
+// LoadConfigFile makes niri load its config file again
+func LoadConfigFile() Action {
+	return Action{Name: "LoadConfigFile"}
+}
+
+// OutputAction is a change to one output, lasting until the config is
+// next loaded
+type OutputAction struct {
+	name  string
+	value any // nil for actions without fields
+}
+
+// MarshalJSON encodes the action as "Name" or {"Name": {fields}}
+func (a OutputAction) MarshalJSON() ([]byte, error) {
+	if a.value == nil {
+		return json.Marshal(a.name)
+	}
+	return json.Marshal(map[string]any{a.name: a.value})
+}
+
+// String names the action in errors
+func (a OutputAction) String() string {
+	return a.name
+}
+
+// OutputOn turns an output on
+func OutputOn() OutputAction {
+	return OutputAction{name: "On"}
+}
+
+// OutputOff turns an output off
+func OutputOff() OutputAction {
+	return OutputAction{name: "Off"}
+}
+
+// SetOutputMode switches an output to one of its modes
+func SetOutputMode(m Mode) OutputAction {
+	return OutputAction{name: "Mode", value: map[string]any{
+		"mode": map[string]any{"Specific": map[string]any{
+			"width":   m.Width,
+			"height":  m.Height,
+			"refresh": float64(m.RefreshRate) / 1000,
+		}},
+	}}
+}
+
+// SetOutputScale sets an output's scale
+func SetOutputScale(scale float64) OutputAction {
+	return OutputAction{name: "Scale", value: map[string]any{
+		"scale": map[string]any{"Specific": scale},
+	}}
+}
+
+// SetOutputTransform rotates or flips an output. The transform is spelled
+// as in the config, such as flipped-90.
+func SetOutputTransform(transform string) (OutputAction, error) {
+	name, ok := ipcTransform(transform)
+	if !ok {
+		return OutputAction{}, fmt.Errorf("unknown transform %q", transform)
+	}
+	return OutputAction{name: "Transform", value: map[string]any{"transform": name}}, nil
+}
+
+// SetOutputPosition moves an output in the layout
+func SetOutputPosition(x, y int) OutputAction {
+	return OutputAction{name: "Position", value: map[string]any{
+		"position": map[string]any{"Specific": map[string]any{"x": x, "y": y}},
+	}}
+}
+
+// SetOutputVRR turns variable refresh rate on or off
+func SetOutputVRR(on bool) OutputAction {
+	return OutputAction{name: "Vrr", value: map[string]any{
+		"vrr": map[string]any{"vrr": on, "on_demand": false},
+	}}
+}
+
+// FocusWindow focuses a window, switching to its workspace
+func FocusWindow(id uint64) Action {
+	return Action{Name: "FocusWindow", Fields: map[string]any{"id": id}}
+}
+
+// CloseWindow asks a window to close
+func CloseWindow(id uint64) Action {
+	return Action{Name: "CloseWindow", Fields: map[string]any{"id": id}}
+}
+
+// ToggleWindowFloating moves a window between the floating and tiling
+// layouts
+func ToggleWindowFloating(id uint64) Action {
+	return Action{Name: "ToggleWindowFloating", Fields: map[string]any{"id": id}}
+}
+
+// MoveWindowToWorkspace moves a window to a workspace, following it with
+// the focus if focus is set
+func MoveWindowToWorkspace(id, workspace uint64, focus bool) Action {
+	return Action{Name: "MoveWindowToWorkspace", Fields: map[string]any{
+		"window_id": id,
+		"reference": map[string]any{"Id": workspace},
+		"focus":     focus,
+	}}
+}
+
+// MoveWindowToMonitor moves a window to the active workspace of an output
+func MoveWindowToMonitor(id uint64, output string) Action {
+	return Action{Name: "MoveWindowToMonitor", Fields: map[string]any{"id": id, "output": output}}
+}
+
+// SetWindowWidth changes the width of a window's column
+func SetWindowWidth(id uint64, change SizeChange) Action {
+	return Action{Name: "SetWindowWidth", Fields: map[string]any{"id": id, "change": change}}
+}
+
+// SizeChange is a new size, or a change to one, in logical pixels or in
+// percent of the working area
+type SizeChange struct {
+	Percent bool
+	Adjust  bool // change by Amount rather than set to it
+	Amount  float64
+}
+
+// MarshalJSON encodes the change as niri's SizeChange, such as
+// {"SetProportion": 50.0}
+func (c SizeChange) MarshalJSON() ([]byte, error) {
+	name := "Set"
+	if c.Adjust {
+		name = "Adjust"
+	}
+	if c.Percent {
+		return json.Marshal(map[string]any{name + "Proportion": c.Amount})
+	}
+	return json.Marshal(map[string]any{name + "Fixed": int(c.Amount)})
+}
+
+// ParseSizeChange reads a size change as niri's CLI writes them: 1200
+// for pixels, 50% for a proportion, and a leading + or - to adjust
+func ParseSizeChange(s string) (SizeChange, error) {
+	var c SizeChange
+	input := strings.TrimSpace(s)
+	number, percent := strings.CutSuffix(input, "%")
+	c.Percent = percent
+	c.Adjust = strings.HasPrefix(number, "+") || strings.HasPrefix(number, "-")
+
+	amount, err := strconv.ParseFloat(number, 64)
+	switch {
+	case err != nil:
+		return c, fmt.Errorf("size %q: use pixels like 1200, percent like 50%%, or +/- to adjust", input)
+	case !c.Percent && amount != math.Trunc(amount):
+		return c, fmt.Errorf("size %q: pixels must be whole", input)
+	case !c.Adjust && amount <= 0:
+		return c, fmt.Errorf("size %q must be positive", input)
+	}
+	c.Amount = amount
+	return c, nil
+}
@@ -0,0 +1,201 @@
+package niriipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Event is something that happened in niri, sent on the event stream
+type Event interface {
+	eventName() string
+}
+
+// WorkspacesChanged replaces every workspace
+type WorkspacesChanged struct {
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// WorkspaceActivated is a workspace shown on its output, and focused if
+// Focused is set
+type WorkspaceActivated struct {
+	ID      uint64 `json:"id"`
+	Focused bool   `json:"focused"`
+}
+
+// WorkspaceActiveWindowChanged is a workspace's active window changing
+type WorkspaceActiveWindowChanged struct {
+	WorkspaceID    uint64  `json:"workspace_id"`
+	ActiveWindowID *uint64 `json:"active_window_id"`
+}
+
+// WorkspaceUrgencyChanged is a workspace becoming urgent or not
+type WorkspaceUrgencyChanged struct {
+	ID     uint64 `json:"id"`
+	Urgent bool   `json:"urgent"`
+}
+
+// WindowsChanged replaces every window
+type WindowsChanged struct {
+	Windows []Window `json:"windows"`
+}
+
+// WindowOpenedOrChanged is a window opening, or one changing
+type WindowOpenedOrChanged struct {
+	Window Window `json:"window"`
+}
+
+// WindowClosed is a window closing
+type WindowClosed struct {
+	ID uint64 `json:"id"`
+}
+
+// WindowFocusChanged is the focus moving to a window, or to none
+type WindowFocusChanged struct {
+	ID *uint64 `json:"id"`
+}
+
+// WindowUrgencyChanged is a window becoming urgent or not
+type WindowUrgencyChanged struct {
+	ID     uint64 `json:"id"`
+	Urgent bool   `json:"urgent"`
+}
+
+// KeyboardLayoutsChanged replaces the keyboard layouts
+type KeyboardLayoutsChanged struct {
+	KeyboardLayouts KeyboardLayouts `json:"keyboard_layouts"`
+}
+
+// KeyboardLayoutSwitched is another layout becoming active
+type KeyboardLayoutSwitched struct {
+	Idx int `json:"idx"`
+}
+
+// ConfigLoaded is niri loading its config, at startup or after a change.
+// When Failed is set niri kept its previous config.
+type ConfigLoaded struct {
+	Failed bool `json:"failed"`
+}
+
+// UnknownEvent is an event this client doesn't know, such as one added
+// by a newer niri
+type UnknownEvent struct {
+	Name string
+	Data json.RawMessage
+}
+
+func (WorkspacesChanged) eventName() string            { return "WorkspacesChanged" }
+func (WorkspaceActivated) eventName() string           { return "WorkspaceActivated" }
+func (WorkspaceActiveWindowChanged) eventName() string { return "WorkspaceActiveWindowChanged" }
+func (WorkspaceUrgencyChanged) eventName() string      { return "WorkspaceUrgencyChanged" }
+func (WindowsChanged) eventName() string               { return "WindowsChanged" }
+func (WindowOpenedOrChanged) eventName() string        { return "WindowOpenedOrChanged" }
+func (WindowClosed) eventName() string                 { return "WindowClosed" }
+func (WindowFocusChanged) eventName() string           { return "WindowFocusChanged" }
+func (WindowUrgencyChanged) eventName() string         { return "WindowUrgencyChanged" }
+func (KeyboardLayoutsChanged) eventName() string       { return "KeyboardLayoutsChanged" }
+func (KeyboardLayoutSwitched) eventName() string       { return "KeyboardLayoutSwitched" }
+func (ConfigLoaded) eventName() string                 { return "ConfigLoaded" }
+func (e UnknownEvent) eventName() string               { return e.Name }
+
+// decodeEvent decodes the fields of an event of type T
+func decodeEvent[T Event](data json.RawMessage) (Event, error) {
+	var e T
+	err := json.Unmarshal(data, &e)
+	return e, err
+}
+
+// parseEvent decodes one line of the event stream
+func parseEvent(line []byte) (Event, error) {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(line, &tagged); err != nil {
+		return nil, &ProtocolError{Request: "EventStream", Err: err}
+	}
+	if len(tagged) != 1 {
+		return nil, &ProtocolError{Request: "EventStream", Err: fmt.Errorf("expected one event, got %s", line)}
+	}
+
+	var (
+		event Event
+		err   error
+	)
+	for name, data := range tagged {
+		switch name {
+		case "WorkspacesChanged":
+			event, err = decodeEvent[WorkspacesChanged](data)
+		case "WorkspaceActivated":
+			event, err = decodeEvent[WorkspaceActivated](data)
+		case "WorkspaceActiveWindowChanged":
+			event, err = decodeEvent[WorkspaceActiveWindowChanged](data)
+		case "WorkspaceUrgencyChanged":
+			event, err = decodeEvent[WorkspaceUrgencyChanged](data)
+		case "WindowsChanged":
+			event, err = decodeEvent[WindowsChanged](data)
+		case "WindowOpenedOrChanged":
+			event, err = decodeEvent[WindowOpenedOrChanged](data)
+		case "WindowClosed":
+			event, err = decodeEvent[WindowClosed](data)
+		case "WindowFocusChanged":
+			event, err = decodeEvent[WindowFocusChanged](data)
+		case "WindowUrgencyChanged":
+			event, err = decodeEvent[WindowUrgencyChanged](data)
+		case "KeyboardLayoutsChanged":
+			event, err = decodeEvent[KeyboardLayoutsChanged](data)
+		case "KeyboardLayoutSwitched":
+			event, err = decodeEvent[KeyboardLayoutSwitched](data)
+		case "ConfigLoaded":
+			event, err = decodeEvent[ConfigLoaded](data)
+		default:
+			event = UnknownEvent{Name: name, Data: data}
+		}
+	}
+	if err != nil {
+		return nil, &ProtocolError{Request: "EventStream", Err: err}
+	}
+	return event, nil
+}
+
+// EventStream is a connection niri sends its events on. It starts with
+// the full state: the workspaces, windows and keyboard layouts.
+type EventStream struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// EventStream asks niri to send its events on a new connection
+func (c *Client) EventStream() (*EventStream, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	ok, err := roundTrip(conn, r, "EventStream", "EventStream")
+	if err == nil {
+		err = expectHandled("EventStream", ok)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// Events come whenever something happens, so only the request has
+	// a deadline
+	conn.SetDeadline(time.Time{})
+	return &EventStream{conn: conn, r: r}, nil
+}
+
+// Next waits for the next event. It fails once the stream is closed,
+// from either end.
+func (s *EventStream) Next() (Event, error) {
+	line, err := s.r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return parseEvent(line)
+}
+
+// Close closes the stream
+func (s *EventStream) Close() error {
+	return s.conn.Close()
+}
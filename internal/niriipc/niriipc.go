@@ -0,0 +1,251 @@
+// Package niriipc is a client for niri's IPC socket. niri listens on the
+// Unix socket named by $NIRI_SOCKET; each request is one line of JSON
+// and gets one line of JSON back, {"Ok": ...} or {"Err": "..."}.
+package niriipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// SocketEnv is the environment variable niri puts its socket path in
+const SocketEnv = "NIRI_SOCKET"
+
+// DefaultTimeout bounds a request from connecting to reading the reply
+const DefaultTimeout = 5 * time.Second
+
+// ErrNoSocket is returned when $NIRI_SOCKET isn't set, as happens
+// outside a niri session
+var ErrNoSocket = errors.New(SocketEnv + " is not set; is niri running?")
+
+// ConnectError is returned when the socket can't be reached
+type ConnectError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("connecting to niri at %s: %v", e.Path, e.Err)
+}
+
+func (e *ConnectError) Unwrap() error { return e.Err }
+
+// ReplyError is niri refusing a request, with niri's message
+type ReplyError struct {
+	Request string
+	Message string
+}
+
+func (e *ReplyError) Error() string {
+	return fmt.Sprintf("niri %s: %s", e.Request, e.Message)
+}
+
+// ProtocolError is a reply that couldn't be read or understood
+type ProtocolError struct {
+	Request string
+	Err     error
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("niri %s: bad reply: %v", e.Request, e.Err)
+}
+
+func (e *ProtocolError) Unwrap() error { return e.Err }
+
+// Client sends requests to niri. Each request uses its own connection,
+// as `niri msg` does.
+type Client struct {
+	SocketPath string
+	Timeout    time.Duration
+}
+
+// NewClient returns a client for the niri running this session
+func NewClient() (*Client, error) {
+	path := os.Getenv(SocketEnv)
+	if path == "" {
+		return nil, ErrNoSocket
+	}
+	return NewClientAt(path), nil
+}
+
+// NewClientAt returns a client for the socket at path
+func NewClientAt(path string) *Client {
+	return &Client{SocketPath: path, Timeout: DefaultTimeout}
+}
+
+// dial connects to the socket
+func (c *Client) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", c.SocketPath, c.Timeout)
+	if err != nil {
+		return nil, &ConnectError{Path: c.SocketPath, Err: err}
+	}
+	if c.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+	return conn, nil
+}
+
+// reply is the envelope around every reply
+type reply struct {
+	Ok  json.RawMessage `json:"Ok"`
+	Err *string         `json:"Err"`
+}
+
+// Request sends a request and returns the Ok part of the reply. name
+// describes the request in errors.
+func (c *Client) Request(name string, request any) (json.RawMessage, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return roundTrip(conn, bufio.NewReader(conn), name, request)
+}
+
+// roundTrip writes a request to conn and reads its reply from r
+func roundTrip(conn net.Conn, r *bufio.Reader, name string, request any) (json.RawMessage, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, &ProtocolError{Request: name, Err: err}
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, &ConnectError{Path: conn.RemoteAddr().String(), Err: err}
+	}
+
+	line, err := r.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, &ProtocolError{Request: name, Err: err}
+	}
+	var rep reply
+	if err := json.Unmarshal(line, &rep); err != nil {
+		return nil, &ProtocolError{Request: name, Err: err}
+	}
+	if rep.Err != nil {
+		return nil, &ReplyError{Request: name, Message: *rep.Err}
+	}
+	if rep.Ok == nil {
+		return nil, &ProtocolError{Request: name, Err: errors.New("neither Ok nor Err")}
+	}
+	return rep.Ok, nil
+}
+
+// query sends a request whose reply is a single-variant object, such as
+// {"Windows": [...]}, and decodes the variant's value into out
+func (c *Client) query(request, variant string, out any) error {
+	ok, err := c.Request(request, request)
+	if err != nil {
+		return err
+	}
+	return decodeVariant(request, variant, ok, out)
+}
+
+// decodeVariant decodes the value of an externally tagged variant
+func decodeVariant(request, variant string, data json.RawMessage, out any) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return &ProtocolError{Request: request, Err: err}
+	}
+	value, ok := tagged[variant]
+	if !ok {
+		return &ProtocolError{Request: request, Err: fmt.Errorf("expected a %s reply, got %s", variant, data)}
+	}
+	if err := json.Unmarshal(value, out); err != nil {
+		return &ProtocolError{Request: request, Err: err}
+	}
+	return nil
+}
+
+// Version returns the version of the running niri
+func (c *Client) Version() (string, error) {
+	var version string
+	err := c.query("Version", "Version", &version)
+	return version, err
+}
+
+// Outputs returns the connected outputs by name
+func (c *Client) Outputs() (map[string]Output, error) {
+	var outputs map[string]Output
+	err := c.query("Outputs", "Outputs", &outputs)
+	return outputs, err
+}
+
+// Workspaces returns every workspace on every output
+func (c *Client) Workspaces() ([]Workspace, error) {
+	var workspaces []Workspace
+	err := c.query("Workspaces", "Workspaces", &workspaces)
+	return workspaces, err
+}
+
+// Windows returns every open window
+func (c *Client) Windows() ([]Window, error) {
+	var windows []Window
+	err := c.query("Windows", "Windows", &windows)
+	return windows, err
+}
+
+// FocusedWindow returns the focused window, or nil if none is
+func (c *Client) FocusedWindow() (*Window, error) {
+	var window *Window
+	err := c.query("FocusedWindow", "FocusedWindow", &window)
+	return window, err
+}
+
+// FocusedOutput returns the focused output, or nil if none is
+func (c *Client) FocusedOutput() (*Output, error) {
+	var output *Output
+	err := c.query("FocusedOutput", "FocusedOutput", &output)
+	return output, err
+}
+
+// KeyboardLayouts returns the configured layouts and the active one
+func (c *Client) KeyboardLayouts() (KeyboardLayouts, error) {
+	var layouts KeyboardLayouts
+	err := c.query("KeyboardLayouts", "KeyboardLayouts", &layouts)
+	return layouts, err
+}
+
+// Action runs an action, as a bind would
+func (c *Client) Action(a Action) error {
+	ok, err := c.Request(a.Name, map[string]Action{"Action": a})
+	if err != nil {
+		return err
+	}
+	return expectHandled(a.Name, ok)
+}
+
+// ErrOutputMissing is returned when an output change names an output
+// that isn't connected
+var ErrOutputMissing = errors.New("output is not connected")
+
+// ConfigureOutput changes an output until the config is next loaded
+func (c *Client) ConfigureOutput(output string, a OutputAction) error {
+	name := "Output " + output
+	ok, err := c.Request(name, map[string]any{
+		"Output": map[string]any{"output": output, "action": a},
+	})
+	if err != nil {
+		return err
+	}
+	var result string
+	if err := decodeVariant(name, "OutputConfigChanged", ok, &result); err != nil {
+		return err
+	}
+	if result == "OutputWasMissing" {
+		return fmt.Errorf("%s: %w", output, ErrOutputMissing)
+	}
+	return nil
+}
+
+// expectHandled checks for the plain "Handled" reply
+func expectHandled(request string, ok json.RawMessage) error {
+	var handled string
+	if err := json.Unmarshal(ok, &handled); err != nil || handled != "Handled" {
+		return &ProtocolError{Request: request, Err: fmt.Errorf("expected Handled, got %s", ok)}
+	}
+	return nil
+}
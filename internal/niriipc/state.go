@@ -0,0 +1,133 @@
+package niriipc
+
+import (
+	"cmp"
+	"slices"
+)
+
+// State is niri's state as an event stream describes it. A stream's
+// first events fill it in and the rest keep it current.
+type State struct {
+	Workspaces      map[uint64]Workspace
+	Windows         map[uint64]Window
+	KeyboardLayouts *KeyboardLayouts // nil until niri reports them
+}
+
+// NewState returns an empty state
+func NewState() *State {
+	return &State{
+		Workspaces: map[uint64]Workspace{},
+		Windows:    map[uint64]Window{},
+	}
+}
+
+// Apply updates the state with an event
+func (s *State) Apply(e Event) {
+	switch e := e.(type) {
+	case WorkspacesChanged:
+		s.Workspaces = make(map[uint64]Workspace, len(e.Workspaces))
+		for _, ws := range e.Workspaces {
+			s.Workspaces[ws.ID] = ws
+		}
+
+	case WorkspaceActivated:
+		activated, ok := s.Workspaces[e.ID]
+		if !ok {
+			return
+		}
+		for id, ws := range s.Workspaces {
+			if equalOutput(ws.Output, activated.Output) {
+				ws.IsActive = id == e.ID
+			}
+			if e.Focused {
+				ws.IsFocused = id == e.ID
+			}
+			s.Workspaces[id] = ws
+		}
+
+	case WorkspaceActiveWindowChanged:
+		if ws, ok := s.Workspaces[e.WorkspaceID]; ok {
+			ws.ActiveWindowID = e.ActiveWindowID
+			s.Workspaces[e.WorkspaceID] = ws
+		}
+
+	case WorkspaceUrgencyChanged:
+		if ws, ok := s.Workspaces[e.ID]; ok {
+			ws.IsUrgent = e.Urgent
+			s.Workspaces[e.ID] = ws
+		}
+
+	case WindowsChanged:
+		s.Windows = make(map[uint64]Window, len(e.Windows))
+		for _, w := range e.Windows {
+			s.Windows[w.ID] = w
+		}
+
+	case WindowOpenedOrChanged:
+		if e.Window.IsFocused {
+			s.focusWindow(&e.Window.ID)
+		}
+		s.Windows[e.Window.ID] = e.Window
+
+	case WindowClosed:
+		delete(s.Windows, e.ID)
+
+	case WindowFocusChanged:
+		s.focusWindow(e.ID)
+
+	case WindowUrgencyChanged:
+		if w, ok := s.Windows[e.ID]; ok {
+			w.IsUrgent = e.Urgent
+			s.Windows[e.ID] = w
+		}
+
+	case KeyboardLayoutsChanged:
+		layouts := e.KeyboardLayouts
+		s.KeyboardLayouts = &layouts
+
+	case KeyboardLayoutSwitched:
+		if s.KeyboardLayouts != nil {
+			s.KeyboardLayouts.CurrentIdx = e.Idx
+		}
+	}
+}
+
+// focusWindow marks the window with the id as the only focused one, or
+// none for a nil id
+func (s *State) focusWindow(id *uint64) {
+	for wid, w := range s.Windows {
+		w.IsFocused = id != nil && wid == *id
+		s.Windows[wid] = w
+	}
+}
+
+// equalOutput reports whether two workspaces' outputs are the same
+func equalOutput(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// FocusedWindow returns the focused window, or nil if none is
+func (s *State) FocusedWindow() *Window {
+	for _, w := range s.Windows {
+		if w.IsFocused {
+			return &w
+		}
+	}
+	return nil
+}
+
+// ActiveWorkspaces returns the workspace shown on each output, sorted by
+// output name
+func (s *State) ActiveWorkspaces() []Workspace {
+	var active []Workspace
+	for _, ws := range s.Workspaces {
+		if ws.IsActive && ws.Output != nil {
+			active = append(active, ws)
+		}
+	}
+	slices.SortFunc(active, func(a, b Workspace) int { return cmp.Compare(*a.Output, *b.Output) })
+	return active
+}
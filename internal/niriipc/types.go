@@ -0,0 +1,143 @@
+package niriipc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output is a connected output
+type Output struct {
+	Name         string         `json:"name"`
+	Make         string         `json:"make"`
+	Model        string         `json:"model"`
+	Serial       *string        `json:"serial"`
+	PhysicalSize *[2]int        `json:"physical_size"` // millimeters
+	Modes        []Mode         `json:"modes"`
+	CurrentMode  *int           `json:"current_mode"` // index in Modes, nil when off
+	VRRSupported bool           `json:"vrr_supported"`
+	VRREnabled   bool           `json:"vrr_enabled"`
+	Logical      *LogicalOutput `json:"logical"` // nil when off
+}
+
+// Description returns the make and model of the monitor
+func (o Output) Description() string {
+	return strings.TrimSpace(o.Make + " " + o.Model)
+}
+
+// Mode is a mode an output supports
+type Mode struct {
+	Width       int  `json:"width"`
+	Height      int  `json:"height"`
+	RefreshRate int  `json:"refresh_rate"` // millihertz
+	Preferred   bool `json:"is_preferred"`
+}
+
+// String formats the mode as the config takes it, as in 2560x1440@143.912
+func (m Mode) String() string {
+	return fmt.Sprintf("%dx%d@%.3f", m.Width, m.Height, float64(m.RefreshRate)/1000)
+}
+
+// LogicalOutput is where an enabled output sits in the layout
+type LogicalOutput struct {
+	X         int     `json:"x"`
+	Y         int     `json:"y"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Scale     float64 `json:"scale"`
+	Transform string  `json:"transform"` // niri's name, such as Flipped90
+}
+
+// transforms maps niri's IPC transform names to the config's. niri-ipc
+// renames the plain rotations from _90 and so on to bare numbers.
+var transforms = map[string]string{
+	"Normal":     "normal",
+	"90":         "90",
+	"180":        "180",
+	"270":        "270",
+	"Flipped":    "flipped",
+	"Flipped90":  "flipped-90",
+	"Flipped180": "flipped-180",
+	"Flipped270": "flipped-270",
+}
+
+// ConfigTransform returns the config's spelling of an IPC transform name,
+// or "normal" for one it doesn't know
+func ConfigTransform(name string) string {
+	if t, ok := transforms[name]; ok {
+		return t
+	}
+	return "normal"
+}
+
+// ipcTransform returns the IPC name of a transform spelled as in the
+// config
+func ipcTransform(config string) (string, bool) {
+	for name, t := range transforms {
+		if t == config {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Workspace is a workspace on an output
+type Workspace struct {
+	ID             uint64  `json:"id"`
+	Idx            int     `json:"idx"` // position on its output, from 1
+	Name           *string `json:"name"`
+	Output         *string `json:"output"`
+	IsUrgent       bool    `json:"is_urgent"`
+	IsActive       bool    `json:"is_active"`  // shown on its output
+	IsFocused      bool    `json:"is_focused"` // has keyboard focus
+	ActiveWindowID *uint64 `json:"active_window_id"`
+}
+
+// Label returns the workspace's name, or its index when unnamed
+func (w Workspace) Label() string {
+	if w.Name != nil && *w.Name != "" {
+		return *w.Name
+	}
+	return fmt.Sprint(w.Idx)
+}
+
+// Window is an open toplevel window
+type Window struct {
+	ID          uint64  `json:"id"`
+	Title       *string `json:"title"`
+	AppID       *string `json:"app_id"`
+	PID         *int    `json:"pid"`
+	WorkspaceID *uint64 `json:"workspace_id"`
+	IsFocused   bool    `json:"is_focused"`
+	IsFloating  bool    `json:"is_floating"`
+	IsUrgent    bool    `json:"is_urgent"`
+}
+
+// TitleOr returns the window's title, or fallback when it has none
+func (w Window) TitleOr(fallback string) string {
+	if w.Title != nil && *w.Title != "" {
+		return *w.Title
+	}
+	return fallback
+}
+
+// AppIDOr returns the window's app id, or fallback when it has none
+func (w Window) AppIDOr(fallback string) string {
+	if w.AppID != nil && *w.AppID != "" {
+		return *w.AppID
+	}
+	return fallback
+}
+
+// KeyboardLayouts are the configured keyboard layouts
+type KeyboardLayouts struct {
+	Names      []string `json:"names"`
+	CurrentIdx int      `json:"current_idx"`
+}
+
+// Current returns the name of the active layout
+func (k KeyboardLayouts) Current() string {
+	if k.CurrentIdx >= 0 && k.CurrentIdx < len(k.Names) {
+		return k.Names[k.CurrentIdx]
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+// Package outputlayout arranges outputs in niri's logical coordinate
+// space: placing the ones without a position, snapping moved outputs to
+// the edges of the others and finding overlaps and gaps
+package outputlayout
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Rect is an output's logical position and size
+type Rect struct {
+	Name       string
+	X, Y, W, H int
+}
+
+// Right returns the x just past the right edge
+func (r Rect) Right() int { return r.X + r.W }
+
+// Bottom returns the y just past the bottom edge
+func (r Rect) Bottom() int { return r.Y + r.H }
+
+// overlap returns how far two rects overlap on each axis. Negative
+// values are the gap between them.
+func (r Rect) overlap(o Rect) (x, y int) {
+	return min(r.Right(), o.Right()) - max(r.X, o.X), min(r.Bottom(), o.Bottom()) - max(r.Y, o.Y)
+}
+
+// Overlaps reports whether two rects share any area
+func (r Rect) Overlaps(o Rect) bool {
+	x, y := r.overlap(o)
+	return x > 0 && y > 0
+}
+
+// Touches reports whether two rects share a stretch of edge, so the
+// pointer can cross from one to the other
+func (r Rect) Touches(o Rect) bool {
+	x, y := r.overlap(o)
+	return (x == 0 && y > 0) || (y == 0 && x > 0)
+}
+
+// distance returns the gap between two rects, 0 if they touch or overlap
+func (r Rect) distance(o Rect) int {
+	x, y := r.overlap(o)
+	return max(0, -x, -y)
+}
+
+// Place positions the rects whose placed flag is false the way niri
+// does: in name order, each to the right of everything placed so far,
+// top-aligned at y=0
+func Place(rects []Rect, placed []bool) {
+	right, first := 0, true
+	for i, r := range rects {
+		if placed[i] && (first || r.Right() > right) {
+			right, first = r.Right(), false
+		}
+	}
+
+	var auto []int
+	for i := range rects {
+		if !placed[i] {
+			auto = append(auto, i)
+		}
+	}
+	slices.SortFunc(auto, func(a, b int) int { return strings.Compare(rects[a].Name, rects[b].Name) })
+	for _, i := range auto {
+		rects[i].X, rects[i].Y = right, 0
+		right += rects[i].W
+	}
+}
+
+// Move returns where rect i goes when moved one step in a direction
+// (dx, dy of -1, 0 or 1): to the nearest position in that direction that
+// lines an edge up with another rect, or by a quarter of its size if
+// there is none
+func Move(rects []Rect, i, dx, dy int) Rect {
+	r := rects[i]
+	if dx != 0 {
+		r.X = next(r.X, dx, max(1, r.W/4), snapTargets(rects, i, func(o Rect) (int, int) { return o.X, o.W }, r.W))
+	}
+	if dy != 0 {
+		r.Y = next(r.Y, dy, max(1, r.H/4), snapTargets(rects, i, func(o Rect) (int, int) { return o.Y, o.H }, r.H))
+	}
+	return r
+}
+
+// snapTargets lists the coordinates at which rect i lines up with
+// another rect on one axis: edge to edge, or aligned start or end
+func snapTargets(rects []Rect, i int, axis func(Rect) (int, int), size int) []int {
+	var targets []int
+	for j, o := range rects {
+		if j == i {
+			continue
+		}
+		start, length := axis(o)
+		end := start + length
+		targets = append(targets, end, start-size, start, end-size)
+	}
+	return targets
+}
+
+// next returns the nearest target past pos in direction dir, or pos
+// moved by step if there is none
+func next(pos, dir, step int, targets []int) int {
+	best, found := 0, false
+	for _, t := range targets {
+		if (t-pos)*dir <= 0 {
+			continue
+		}
+		if !found || (t-best)*dir < 0 {
+			best, found = t, true
+		}
+	}
+	if found {
+		return best
+	}
+	return pos + dir*step
+}
+
+// Nudge returns rect i moved by exactly dx, dy, for fine adjustments
+func Nudge(rects []Rect, i, dx, dy int) Rect {
+	r := rects[i]
+	r.X += dx
+	r.Y += dy
+	return r
+}
+
+// Check describes what is wrong with an arrangement: outputs that
+// overlap, and groups of outputs separated from the rest by a gap the
+// pointer can't cross
+func Check(rects []Rect) []string {
+	var problems []string
+	for i, a := range rects {
+		for _, b := range rects[i+1:] {
+			if a.Overlaps(b) {
+				x, y := a.overlap(b)
+				problems = append(problems, fmt.Sprintf("%s and %s overlap by %dx%d", a.Name, b.Name, x, y))
+			}
+		}
+	}
+
+	groups := groups(rects)
+	for _, g := range groups[min(1, len(groups)):] {
+		gap := -1
+		var names []string
+		for _, i := range g {
+			names = append(names, rects[i].Name)
+			for _, j := range groups[0] {
+				if d := rects[i].distance(rects[j]); gap < 0 || d < gap {
+					gap = d
+				}
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s not joined to %s (%d px gap)",
+			strings.Join(names, ", "), groupNames(rects, groups[0]), gap))
+	}
+	return problems
+}
+
+// groups splits the rects into sets that touch or overlap each other,
+// the set holding the first rect first
+func groups(rects []Rect) [][]int {
+	seen := make([]bool, len(rects))
+	var out [][]int
+	for start := range rects {
+		if seen[start] {
+			continue
+		}
+		group := []int{start}
+		seen[start] = true
+		for k := 0; k < len(group); k++ {
+			for j := range rects {
+				a, b := rects[group[k]], rects[j]
+				if !seen[j] && (a.Touches(b) || a.Overlaps(b)) {
+					seen[j] = true
+					group = append(group, j)
+				}
+			}
+		}
+		out = append(out, group)
+	}
+	return out
+}
+
+func groupNames(rects []Rect, group []int) string {
+	names := make([]string, len(group))
+	for k, i := range group {
+		names[k] = rects[i].Name
+	}
+	return strings.Join(names, ", ")
+}
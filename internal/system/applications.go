@@ -0,0 +1,131 @@
+package system
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Application is an installed app, read from its .desktop file
+type Application struct {
+	ID          string // desktop file ID, e.g. org.gnome.Nautilus.desktop
+	Name        string // localized name
+	GenericName string // localized kind of app, e.g. Web Browser
+	Exec        []string
+	Icon        string
+	NoDisplay   bool // the app asks to be left out of menus
+	Path        string
+}
+
+// ApplicationDirs returns the directories desktop files are installed
+// in, most important first: $XDG_DATA_HOME/applications, then each of
+// $XDG_DATA_DIRS
+func ApplicationDirs() []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+
+	var dirs []string
+	for _, dir := range append([]string{dataHome}, filepath.SplitList(dataDirs)...) {
+		if dir == "" {
+			continue
+		}
+		dir = filepath.Join(dir, "applications")
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Locale returns the locale messages are shown in, from $LC_ALL,
+// $LC_MESSAGES or $LANG
+func Locale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if l := os.Getenv(env); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+// LoadApplications reads the apps installed in ApplicationDirs, sorted
+// by name. A desktop file ID found in several directories is taken from
+// the first, so user files override system ones. Unreadable files are
+// skipped.
+func LoadApplications() []Application {
+	locale := Locale()
+	seen := map[string]bool{}
+	var apps []Application
+	for _, dir := range ApplicationDirs() {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return nil
+			}
+			id := strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+
+			f, err := ReadDesktopFile(path)
+			if err != nil {
+				return nil
+			}
+			if app, ok := readApplication(f, id, locale); ok {
+				apps = append(apps, app)
+			}
+			return nil
+		})
+	}
+	slices.SortFunc(apps, func(a, b Application) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return apps
+}
+
+// readApplication reads an app from its desktop file. Hidden files,
+// links and directories, and apps without a usable Exec are left out.
+func readApplication(f *DesktopFile, id, locale string) (Application, bool) {
+	if t := f.String("Type"); t != "" && t != "Application" {
+		return Application{}, false
+	}
+	if f.Bool("Hidden") {
+		return Application{}, false
+	}
+	exec, ok := f.Get("Exec")
+	if !ok {
+		return Application{}, false
+	}
+	command, err := SplitExec(exec)
+	if err != nil || len(command) == 0 {
+		return Application{}, false
+	}
+
+	app := Application{
+		ID:          id,
+		Name:        f.LocaleString("Name", locale),
+		GenericName: f.LocaleString("GenericName", locale),
+		Exec:        command,
+		Icon:        f.String("Icon"),
+		NoDisplay:   f.Bool("NoDisplay"),
+		Path:        f.Path,
+	}
+	if app.Name == "" {
+		app.Name = strings.TrimSuffix(id, ".desktop")
+	}
+	return app, true
+}
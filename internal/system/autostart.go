@@ -0,0 +1,114 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AutostartEntry is a .desktop file in the user's autostart directory
+type AutostartEntry struct {
+	Name    string
+	Command []string // Exec, split into arguments
+	Hidden  bool     // Hidden=true turns the entry off
+	file    *DesktopFile
+}
+
+// Path returns the desktop file's path
+func (e *AutostartEntry) Path() string {
+	return e.file.Path
+}
+
+// AutostartDir returns the user's XDG autostart directory
+func AutostartDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "autostart"), nil
+}
+
+// LoadAutostart reads the .desktop files in the user's autostart
+// directory, sorted by file name
+func LoadAutostart() ([]*AutostartEntry, error) {
+	dir, err := AutostartDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.desktop"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+
+	var entries []*AutostartEntry
+	for _, path := range paths {
+		f, err := ReadDesktopFile(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, newAutostartEntry(f))
+	}
+	return entries, nil
+}
+
+// newAutostartEntry reads the fields of an autostart file
+func newAutostartEntry(f *DesktopFile) *AutostartEntry {
+	e := &AutostartEntry{
+		Name:   f.String("Name"),
+		Hidden: f.Bool("Hidden"),
+		file:   f,
+	}
+	if exec, ok := f.Get("Exec"); ok {
+		// A broken Exec leaves the command empty; the entry still shows
+		e.Command, _ = SplitExec(exec)
+	}
+	if e.Name == "" {
+		e.Name = strings.TrimSuffix(filepath.Base(f.Path), ".desktop")
+	}
+	return e
+}
+
+// SetHidden turns the entry off or back on by setting Hidden= in its file
+func (e *AutostartEntry) SetHidden(hidden bool) error {
+	e.file.Set("Hidden", strconv.FormatBool(hidden))
+	if err := e.file.Save(); err != nil {
+		return err
+	}
+	e.Hidden = hidden
+	return nil
+}
+
+// CreateAutostart writes an autostart file running command. name is the
+// file name without .desktop.
+func CreateAutostart(name string, command []string) (*AutostartEntry, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no command to run")
+	}
+	dir, err := AutostartDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, name+".desktop")
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s already exists", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	f := ParseDesktopFile(path, []byte(desktopGroup+"\n"))
+	f.Set("Type", "Application")
+	f.Set("Name", name)
+	f.Set("Exec", JoinExec(command))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := f.Save(); err != nil {
+		return nil, err
+	}
+	return newAutostartEntry(f), nil
+}
@@ -0,0 +1,57 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// drmRoot is where the kernel lists display connectors
+const drmRoot = "/sys/class/drm"
+
+// Connector is a display connector of a graphics card, such as eDP-1 or
+// HDMI-A-1
+type Connector struct {
+	Name      string // as niri names outputs
+	Connected bool   // a monitor is plugged in
+}
+
+// connectorDir matches connector directories such as card1-eDP-1
+var connectorDir = regexp.MustCompile(`^card\d+-(.+)$`)
+
+// LoadConnectors lists the display connectors of every card, connected
+// ones first, then by name
+func LoadConnectors() ([]Connector, error) {
+	dirs, err := os.ReadDir(drmRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var connectors []Connector
+	for _, d := range dirs {
+		m := connectorDir.FindStringSubmatch(d.Name())
+		if m == nil {
+			continue
+		}
+		status, err := os.ReadFile(filepath.Join(drmRoot, d.Name(), "status"))
+		if err != nil {
+			continue
+		}
+		connectors = append(connectors, Connector{
+			Name:      m[1],
+			Connected: strings.TrimSpace(string(status)) == "connected",
+		})
+	}
+	slices.SortFunc(connectors, func(a, b Connector) int {
+		if a.Connected != b.Connected {
+			if a.Connected {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return connectors, nil
+}
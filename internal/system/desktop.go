@@ -0,0 +1,239 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// desktopGroup is the group of a .desktop file holding the entry's keys
+const desktopGroup = "[Desktop Entry]"
+
+// DesktopFile is a .desktop file. Lines are kept as read, so setting a
+// key rewrites only that line.
+type DesktopFile struct {
+	Path  string
+	lines []string
+}
+
+// ReadDesktopFile reads a .desktop file
+func ReadDesktopFile(path string) (*DesktopFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDesktopFile(path, data), nil
+}
+
+// ParseDesktopFile parses the content of a .desktop file
+func ParseDesktopFile(path string, data []byte) *DesktopFile {
+	text := strings.TrimSuffix(string(data), "\n")
+	return &DesktopFile{Path: path, lines: strings.Split(text, "\n")}
+}
+
+// entryLines returns the range of lines in the [Desktop Entry] group,
+// header excluded. start is -1 if the group is missing.
+func (f *DesktopFile) entryLines() (start, end int) {
+	start = -1
+	for i, line := range f.lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if line == desktopGroup {
+			start = i + 1
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, len(f.lines)
+}
+
+// find returns the index of the line setting key, or -1
+func (f *DesktopFile) find(key string) int {
+	start, end := f.entryLines()
+	for i := start; i >= 0 && i < end; i++ {
+		k, _, ok := strings.Cut(f.lines[i], "=")
+		if ok && strings.TrimSpace(k) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the raw value of a key in the [Desktop Entry] group
+func (f *DesktopFile) Get(key string) (string, bool) {
+	i := f.find(key)
+	if i < 0 {
+		return "", false
+	}
+	_, value, _ := strings.Cut(f.lines[i], "=")
+	return strings.TrimSpace(value), true
+}
+
+// String returns the unescaped value of a string key
+func (f *DesktopFile) String(key string) string {
+	value, _ := f.Get(key)
+	return unescapeValue(value)
+}
+
+// LocaleString returns the value of a localestring key for locale, such
+// as de_DE.UTF-8, falling back to the unlocalized value
+func (f *DesktopFile) LocaleString(key, locale string) string {
+	for _, l := range localeVariants(locale) {
+		if value, ok := f.Get(key + "[" + l + "]"); ok {
+			return unescapeValue(value)
+		}
+	}
+	return f.String(key)
+}
+
+// localeVariants lists the keys a locale matches, most specific first:
+// lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER, lang
+func localeVariants(locale string) []string {
+	locale, modifier, _ := strings.Cut(locale, "@")
+	locale, _, _ = strings.Cut(locale, ".")
+	lang, country, _ := strings.Cut(locale, "_")
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return nil
+	}
+
+	var variants []string
+	if country != "" && modifier != "" {
+		variants = append(variants, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		variants = append(variants, lang+"_"+country)
+	}
+	if modifier != "" {
+		variants = append(variants, lang+"@"+modifier)
+	}
+	return append(variants, lang)
+}
+
+// Bool returns the value of a boolean key, false if it is missing
+func (f *DesktopFile) Bool(key string) bool {
+	value, _ := f.Get(key)
+	return value == "true"
+}
+
+// Set sets a key in the [Desktop Entry] group, replacing its line or
+// adding one at the end of the group
+func (f *DesktopFile) Set(key, value string) {
+	line := key + "=" + value
+	if i := f.find(key); i >= 0 {
+		f.lines[i] = line
+		return
+	}
+
+	start, end := f.entryLines()
+	if start < 0 {
+		f.lines = append([]string{desktopGroup, line}, f.lines...)
+		return
+	}
+	// Keep blank lines that separate the next group after the new key
+	for end > start && strings.TrimSpace(f.lines[end-1]) == "" {
+		end--
+	}
+	f.lines = append(f.lines[:end], append([]string{line}, f.lines[end:]...)...)
+}
+
+// Bytes returns the file content
+func (f *DesktopFile) Bytes() []byte {
+	return []byte(strings.Join(f.lines, "\n") + "\n")
+}
+
+// Save writes the file back to its path
+func (f *DesktopFile) Save() error {
+	return os.WriteFile(f.Path, f.Bytes(), 0o644)
+}
+
+// unescapeValue undoes the escapes allowed in string values
+func unescapeValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// SplitExec splits an Exec value into the program and its arguments.
+// Field codes such as %U are dropped, since nothing is being opened.
+func SplitExec(exec string) ([]string, error) {
+	exec = unescapeValue(exec)
+
+	var args []string
+	var arg strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(exec):
+			i++
+			arg.WriteByte(exec[i])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case c == '%' && i+1 < len(exec):
+			i++
+			if exec[i] == '%' {
+				arg.WriteByte('%')
+				inArg = true
+			}
+			// Any other field code expands to nothing here
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in Exec %q", exec)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// JoinExec formats a command as an Exec value, quoting arguments that
+// need it
+func JoinExec(command []string) string {
+	parts := make([]string, len(command))
+	for i, arg := range command {
+		arg = strings.ReplaceAll(arg, "%", "%%")
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+			arg = `"` + strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", "$", `\\$`).Replace(arg) + `"`
+		}
+		parts[i] = arg
+	}
+	return strings.Join(parts, " ")
+}
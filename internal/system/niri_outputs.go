@@ -0,0 +1,124 @@
+package system
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/edellingham/nirimatic/internal/niriipc"
+)
+
+// LiveSettings are the settings of an output that can change live
+type LiveSettings struct {
+	On        bool
+	Mode      niriipc.Mode
+	Scale     float64
+	Transform string // as the config spells it, such as flipped-90
+	X, Y      int
+	VRR       bool
+}
+
+// OutputSettings returns an output's current settings
+func OutputSettings(o niriipc.Output) LiveSettings {
+	s := LiveSettings{Transform: "normal", Scale: 1, VRR: o.VRREnabled}
+	if o.CurrentMode != nil && *o.CurrentMode < len(o.Modes) {
+		s.Mode = o.Modes[*o.CurrentMode]
+	}
+	if l := o.Logical; l != nil {
+		s.On = true
+		s.Scale = l.Scale
+		s.X, s.Y = l.X, l.Y
+		s.Transform = niriipc.ConfigTransform(l.Transform)
+	}
+	return s
+}
+
+// OutputControl reads and changes niri's outputs while it runs. It is an
+// interface so a fake can stand in for niri.
+type OutputControl interface {
+	// Outputs returns the connected outputs, sorted by name
+	Outputs() ([]niriipc.Output, error)
+	// SetOutput changes the settings of an output that differ between
+	// from and to
+	SetOutput(name string, from, to LiveSettings) error
+}
+
+// NiriOutputs controls outputs over niri's IPC socket
+type NiriOutputs struct{}
+
+// NewNiriOutputs returns an OutputControl for the running niri
+func NewNiriOutputs() *NiriOutputs {
+	return &NiriOutputs{}
+}
+
+func (NiriOutputs) Outputs() ([]niriipc.Output, error) {
+	client, err := niriipc.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	byName, err := client.Outputs()
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]niriipc.Output, 0, len(byName))
+	for _, o := range byName {
+		outputs = append(outputs, o)
+	}
+	slices.SortFunc(outputs, func(a, b niriipc.Output) int { return strings.Compare(a.Name, b.Name) })
+	return outputs, nil
+}
+
+func (NiriOutputs) SetOutput(name string, from, to LiveSettings) error {
+	changes, err := outputChanges(from, to)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	client, err := niriipc.NewClient()
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := client.ConfigureOutput(name, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputChanges returns the output actions turning one set of settings
+// into another. An output being turned off takes no other changes.
+func outputChanges(from, to LiveSettings) ([]niriipc.OutputAction, error) {
+	if !to.On {
+		if from.On {
+			return []niriipc.OutputAction{niriipc.OutputOff()}, nil
+		}
+		return nil, nil
+	}
+
+	var changes []niriipc.OutputAction
+	if !from.On {
+		changes = append(changes, niriipc.OutputOn())
+	}
+	if to.Mode != from.Mode && to.Mode.Width > 0 {
+		changes = append(changes, niriipc.SetOutputMode(to.Mode))
+	}
+	if to.Scale != from.Scale {
+		changes = append(changes, niriipc.SetOutputScale(to.Scale))
+	}
+	if to.Transform != from.Transform {
+		transform, err := niriipc.SetOutputTransform(to.Transform)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, transform)
+	}
+	if to.X != from.X || to.Y != from.Y {
+		changes = append(changes, niriipc.SetOutputPosition(to.X, to.Y))
+	}
+	if to.VRR != from.VRR {
+		changes = append(changes, niriipc.SetOutputVRR(to.VRR))
+	}
+	return changes, nil
+}
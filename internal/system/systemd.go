@@ -0,0 +1,347 @@
+// Package system talks to the rest of the desktop session: the systemd
+// user manager, XDG autostart files and the running niri
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SessionTarget is the systemd target session services hang off
+const SessionTarget = "graphical-session.target"
+
+// Systemctl is the part of systemctl the startup screen uses. It is an
+// interface so a fake can stand in for the user's service manager.
+type Systemctl interface {
+	// ListUnitFiles returns the names of the installed service unit files
+	ListUnitFiles() ([]string, error)
+	// Show returns properties of units, one map per unit in order
+	Show(units []string, props ...string) ([]map[string]string, error)
+	Enable(unit string) error
+	Disable(unit string) error
+	Start(unit string) error
+	Stop(unit string) error
+	DaemonReload() error
+}
+
+// UserSystemctl runs `systemctl --user`
+type UserSystemctl struct{}
+
+// NewUserSystemctl returns a Systemctl for the user's service manager
+func NewUserSystemctl() *UserSystemctl {
+	return &UserSystemctl{}
+}
+
+// run runs systemctl --user with args and returns its output. Failures
+// carry systemctl's error message.
+func (UserSystemctl) run(args ...string) ([]byte, error) {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("systemctl %s: %s", args[0], msg)
+		}
+		return out, fmt.Errorf("systemctl %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+func (s UserSystemctl) ListUnitFiles() ([]string, error) {
+	out, err := s.run("list-unit-files", "--type=service", "--no-legend", "--no-pager", "--plain")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			names = append(names, fields[0])
+		}
+	}
+	return names, nil
+}
+
+func (s UserSystemctl) Show(units []string, props ...string) ([]map[string]string, error) {
+	if len(units) == 0 {
+		return nil, nil
+	}
+	args := []string{"show", "--no-pager"}
+	for _, p := range props {
+		args = append(args, "--property="+p)
+	}
+	out, err := s.run(append(args, units...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseShow(out), nil
+}
+
+func (s UserSystemctl) Enable(unit string) error {
+	_, err := s.run("enable", unit)
+	return err
+}
+
+func (s UserSystemctl) Disable(unit string) error {
+	_, err := s.run("disable", unit)
+	return err
+}
+
+func (s UserSystemctl) Start(unit string) error {
+	_, err := s.run("start", unit)
+	return err
+}
+
+func (s UserSystemctl) Stop(unit string) error {
+	_, err := s.run("stop", unit)
+	return err
+}
+
+func (s UserSystemctl) DaemonReload() error {
+	_, err := s.run("daemon-reload")
+	return err
+}
+
+// parseShow splits `systemctl show` output into one map per unit. Units
+// are separated by blank lines.
+func parseShow(out []byte) []map[string]string {
+	var units []map[string]string
+	current := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(current) > 0 {
+				units = append(units, current)
+				current = map[string]string{}
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			current[key] = value
+		}
+	}
+	if len(current) > 0 {
+		units = append(units, current)
+	}
+	return units
+}
+
+// Unit is a systemd user service started with the graphical session
+type Unit struct {
+	Name          string
+	Description   string
+	ActiveState   string // active, inactive, failed, ...
+	UnitFileState string // enabled, disabled, static, ...
+	Path          string // unit file
+	ExecStart     []string
+}
+
+// Active reports whether the unit is running
+func (u Unit) Active() bool {
+	return u.ActiveState == "active" || u.ActiveState == "activating" || u.ActiveState == "reloading"
+}
+
+// Enabled reports whether the unit starts with the session
+func (u Unit) Enabled() bool {
+	return strings.HasPrefix(u.UnitFileState, "enabled") || u.UnitFileState == "linked"
+}
+
+// unitProps are the properties read for each unit
+var unitProps = []string{
+	"Id", "Description", "ActiveState", "UnitFileState", "FragmentPath",
+	"WantedBy", "BindsTo", "PartOf", "ExecStart",
+}
+
+// SessionUnits returns the user services tied to the graphical session:
+// those it wants, binds or is part of, and disabled ones whose unit file
+// would install them into it
+func SessionUnits(sc Systemctl) ([]Unit, error) {
+	names, err := sc.ListUnitFiles()
+	if err != nil {
+		return nil, err
+	}
+	// Template units can't be shown without an instance
+	names = slices.DeleteFunc(names, func(n string) bool { return strings.HasSuffix(n, "@.service") })
+
+	infos, err := sc.Show(names, unitProps...)
+	if err != nil {
+		return nil, err
+	}
+
+	var units []Unit
+	for _, info := range infos {
+		if !inSession(info) {
+			continue
+		}
+		units = append(units, Unit{
+			Name:          info["Id"],
+			Description:   info["Description"],
+			ActiveState:   info["ActiveState"],
+			UnitFileState: info["UnitFileState"],
+			Path:          info["FragmentPath"],
+			ExecStart:     parseExecStart(info["ExecStart"]),
+		})
+	}
+	slices.SortFunc(units, func(a, b Unit) int { return strings.Compare(a.Name, b.Name) })
+	return units, nil
+}
+
+// inSession reports whether a unit hangs off the session target
+func inSession(info map[string]string) bool {
+	for _, prop := range []string{"WantedBy", "BindsTo", "PartOf"} {
+		if slices.Contains(strings.Fields(info[prop]), SessionTarget) {
+			return true
+		}
+	}
+	// Disabled units lose their WantedBy link, so look at what enabling
+	// would do
+	return installsInto(info["FragmentPath"], SessionTarget)
+}
+
+// installsInto reports whether a unit file's [Install] section wants or
+// requires target
+func installsInto(path, target string) bool {
+	if path == "" {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	section := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			section = line
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != "[Install]" {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "WantedBy", "RequiredBy", "UpheldBy":
+			if slices.Contains(strings.Fields(value), target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseExecStart pulls the command line out of systemctl's ExecStart
+// property, which looks like
+// `{ path=/usr/bin/foo ; argv[]=/usr/bin/foo -x ; ignore_errors=no ; ... }`
+func parseExecStart(prop string) []string {
+	_, rest, ok := strings.Cut(prop, "argv[]=")
+	if !ok {
+		return nil
+	}
+	argv, _, _ := strings.Cut(rest, " ;")
+	return strings.Fields(argv)
+}
+
+// SetUnitEnabled enables or disables a unit
+func SetUnitEnabled(sc Systemctl, unit string, enabled bool) error {
+	if enabled {
+		return sc.Enable(unit)
+	}
+	return sc.Disable(unit)
+}
+
+// SetUnitRunning starts or stops a unit
+func SetUnitRunning(sc Systemctl, unit string, running bool) error {
+	if running {
+		return sc.Start(unit)
+	}
+	return sc.Stop(unit)
+}
+
+// UserUnitDir returns the directory user unit files go in
+func UserUnitDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user"), nil
+}
+
+// CreateSessionUnit writes a service running command with the graphical
+// session and enables it. name is the unit name without .service.
+func CreateSessionUnit(sc Systemctl, name string, command []string) (string, error) {
+	if len(command) == 0 {
+		return "", fmt.Errorf("no command to run")
+	}
+	dir, err := UserUnitDir()
+	if err != nil {
+		return "", err
+	}
+	unit := name + ".service"
+	path := filepath.Join(dir, unit)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+
+	// systemd doesn't search the user's PATH, so give it the full path
+	prog, err := exec.LookPath(command[0])
+	if err != nil {
+		return "", fmt.Errorf("%s not found in PATH", command[0])
+	}
+	if prog, err = filepath.Abs(prog); err != nil {
+		return "", err
+	}
+	command = append([]string{prog}, command[1:]...)
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", name)
+	fmt.Fprintf(&b, "PartOf=%s\n", SessionTarget)
+	fmt.Fprintf(&b, "After=%s\n", SessionTarget)
+	fmt.Fprintf(&b, "Requisite=%s\n", SessionTarget)
+	b.WriteString("\n[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", quoteExecStart(command))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("\n[Install]\n")
+	fmt.Fprintf(&b, "WantedBy=%s\n", SessionTarget)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return "", err
+	}
+	// Don't leave a half set up unit behind
+	if err := sc.DaemonReload(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	if err := sc.Enable(unit); err != nil {
+		os.Remove(path)
+		sc.DaemonReload()
+		return "", err
+	}
+	return unit, nil
+}
+
+// quoteExecStart formats a command for ExecStart=, quoting arguments
+// with spaces and escaping the characters systemd expands
+func quoteExecStart(command []string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$")
+	parts := make([]string, len(command))
+	for i, arg := range command {
+		arg = escape.Replace(arg)
+		if arg == "" || strings.ContainsAny(arg, " \t'") {
+			arg = `"` + arg + `"`
+		}
+		parts[i] = arg
+	}
+	return strings.Join(parts, " ")
+}
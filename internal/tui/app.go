@@ -1,16 +1,18 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
-	"path/filepath"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/niriipc"
+	"github.com/edellingham/nirimatic/internal/system"
 	"github.com/edellingham/nirimatic/internal/tui/screens"
 )
 
@@ -23,6 +25,8 @@ const (
 	ScreenAnimations
 	ScreenKeybinds
 	ScreenStartup
+	ScreenOutputs
+	ScreenWindows
 	ScreenBackup
 )
 
@@ -47,14 +51,23 @@ type App struct {
 	// Screen models
 	dashboard    *DashboardModel
 	niriSettings *screens.NiriSettingsModel
-	// animations    *AnimationsModel
-	// keybinds      *KeybindsModel
-	// startup       *StartupModel
+	keybinds     *screens.KeybindsModel
+	startup      *screens.StartupModel
+	animations   *screens.AnimationsModel
+	outputs      *screens.OutputsModel
+	windows      *screens.WindowsModel
 	// backup        *BackupModel
 
+	// niri's event stream, shared by the screens showing live state
+	events *niriEvents
+
+	// Where niri rejected the config, shown until a save or load succeeds
+	configErr *config.ValidationError
+
 	// Config state
-	configPath  string
-	configDirty bool
+	configPath   string
+	configSource config.ConfigSource
+	configDirty  bool
 }
 
 // sidebarItem represents an item in the sidebar
@@ -90,12 +103,8 @@ func (d sidebarDelegate) Render(w io.Writer, m list.Model, index int, item list.
 	fmt.Fprint(w, line)
 }
 
-// NewApp creates a new application instance
-func NewApp() *App {
-	// Get config path
-	homeDir, _ := os.UserHomeDir()
-	configPath := filepath.Join(homeDir, ".config", "niri", "config.kdl")
-
+// NewApp creates a new application instance editing the config at loc
+func NewApp(loc config.ConfigLocation) *App {
 	// Initialize sidebar
 	items := []list.Item{
 		sidebarItem{title: "Dashboard", screen: ScreenDashboard},
@@ -103,6 +112,8 @@ func NewApp() *App {
 		sidebarItem{title: "Animations", screen: ScreenAnimations},
 		sidebarItem{title: "Keybinds", screen: ScreenKeybinds},
 		sidebarItem{title: "Startup Apps", screen: ScreenStartup},
+		sidebarItem{title: "Outputs", screen: ScreenOutputs},
+		sidebarItem{title: "Windows", screen: ScreenWindows},
 		sidebarItem{title: "Backup", screen: ScreenBackup},
 	}
 
@@ -114,17 +125,51 @@ func NewApp() *App {
 	sidebar.SetShowTitle(false)
 	sidebar.Styles.Title = SidebarTitleStyle
 
+	keys := DefaultKeyMap()
+
 	// Initialize screen models
 	dashboard := NewDashboardModel()
-	niriSettings := screens.NewNiriSettingsModel()
+	niriSettings := screens.NewNiriSettingsModel(loc.Path)
+	keybinds := screens.NewKeybindsModel(screens.KeybindsKeys{
+		Filter: keys.Filter,
+		Add:    keys.Add,
+		Edit:   keys.Edit,
+		Delete: keys.Delete,
+		Export: keys.Export,
+	})
+	startup := screens.NewStartupModel(screens.StartupKeys{
+		Add:    keys.Add,
+		Edit:   keys.Edit,
+		Delete: keys.Delete,
+		Toggle: keys.Toggle,
+	}, system.NewUserSystemctl())
+	outputs := screens.NewOutputsModel(screens.OutputsKeys{
+		Add:  keys.Add,
+		Edit: keys.Edit,
+	}, system.NewNiriOutputs())
+	var actions screens.ActionRunner
+	if client, err := niriipc.NewClient(); err == nil {
+		actions = client
+	}
+	windows := screens.NewWindowsModel(screens.WindowsKeys{
+		Filter: keys.Filter,
+		Add:    keys.Add,
+	}, actions)
 
 	return &App{
 		currentScreen: ScreenDashboard,
 		sidebar:       sidebar,
-		keys:          DefaultKeyMap(),
-		configPath:    configPath,
+		keys:          keys,
+		configPath:    loc.Path,
+		configSource:  loc.Source,
 		dashboard:     dashboard,
 		niriSettings:  niriSettings,
+		keybinds:      keybinds,
+		startup:       startup,
+		animations:    screens.NewAnimationsModel(),
+		outputs:       outputs,
+		windows:       windows,
+		events:        newNiriEvents(),
 	}
 }
 
@@ -133,6 +178,12 @@ func (a *App) Init() tea.Cmd {
 	return tea.Batch(
 		a.dashboard.Init(),
 		a.niriSettings.Init(),
+		a.keybinds.Init(),
+		a.startup.Init(),
+		a.animations.Init(),
+		a.outputs.Init(),
+		a.windows.Init(),
+		a.events.start(),
 	)
 }
 
@@ -142,6 +193,11 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Screens taking text input get every key
+		if a.focusContent && a.contentCapturing() {
+			return a, a.updateContent(msg)
+		}
+
 		// Global keys work regardless of focus
 		switch {
 		case key.Matches(msg, a.keys.Quit):
@@ -155,7 +211,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, openNoctaliaSettings()
 
 		case key.Matches(msg, a.keys.Reload):
-			return a, reloadNiriConfig()
+			return a, reloadNiriConfig(a.configPath)
 		}
 
 		// Focus switching
@@ -166,17 +222,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return a, nil
 			}
 			// Route to content screen
-			switch a.currentScreen {
-			case ScreenDashboard:
-				var dashCmd tea.Cmd
-				a.dashboard, dashCmd = a.dashboard.Update(msg)
-				cmds = append(cmds, dashCmd)
-			case ScreenNiriSettings:
-				var settingsCmd tea.Cmd
-				a.niriSettings, settingsCmd = a.niriSettings.Update(msg)
-				cmds = append(cmds, settingsCmd)
-			}
-			return a, tea.Batch(cmds...)
+			return a, a.updateContent(msg)
 		} else {
 			// Sidebar focused: Enter switches to content
 			if key.Matches(msg, a.keys.Enter) {
@@ -207,6 +253,38 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		contentWidth := a.width - 28
 		a.dashboard.SetSize(contentWidth, a.height-6)
 		a.niriSettings.SetSize(contentWidth, a.height-6)
+		a.keybinds.SetSize(contentWidth, a.height-6)
+		a.startup.SetSize(contentWidth, a.height-6)
+		a.animations.SetSize(contentWidth, a.height-6)
+		a.outputs.SetSize(contentWidth, a.height-6)
+		a.windows.SetSize(contentWidth, a.height-6)
+
+	case niriStatusMsg, niriipc.Event:
+		// Keep listening; the screens get the message below
+		cmds = append(cmds, a.events.wait())
+		if status, ok := msg.(niriStatusMsg); ok {
+			a.windows.SetConnected(status.connected)
+		}
+		if loaded, ok := msg.(niriipc.ConfigLoaded); ok {
+			if loaded.Failed {
+				// The event doesn't say why, so ask niri to check the file
+				cmds = append(cmds, checkConfig(a.configPath))
+			} else {
+				a.configErr = nil
+			}
+		}
+
+	case configCheckedMsg:
+		errors.As(msg.err, &a.configErr)
+
+	case niriReloadedMsg:
+		// Reloads that get through are reported by ConfigLoaded
+		errors.As(msg.err, &a.configErr)
+	}
+
+	if saved, err := screens.SaveResult(msg); saved {
+		a.configErr = nil
+		errors.As(err, &a.configErr)
 	}
 
 	// Pass non-key messages to ALL screens so they can process their own messages
@@ -219,9 +297,67 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	a.niriSettings, settingsCmd = a.niriSettings.Update(msg)
 	cmds = append(cmds, settingsCmd)
 
+	var keybindsCmd tea.Cmd
+	a.keybinds, keybindsCmd = a.keybinds.Update(msg)
+	cmds = append(cmds, keybindsCmd)
+
+	var startupCmd tea.Cmd
+	a.startup, startupCmd = a.startup.Update(msg)
+	cmds = append(cmds, startupCmd)
+
+	var animationsCmd tea.Cmd
+	a.animations, animationsCmd = a.animations.Update(msg)
+	cmds = append(cmds, animationsCmd)
+
+	var outputsCmd tea.Cmd
+	a.outputs, outputsCmd = a.outputs.Update(msg)
+	cmds = append(cmds, outputsCmd)
+
+	var windowsCmd tea.Cmd
+	a.windows, windowsCmd = a.windows.Update(msg)
+	cmds = append(cmds, windowsCmd)
+
 	return a, tea.Batch(cmds...)
 }
 
+// updateContent routes a key to the current screen
+func (a *App) updateContent(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch a.currentScreen {
+	case ScreenDashboard:
+		a.dashboard, cmd = a.dashboard.Update(msg)
+	case ScreenNiriSettings:
+		a.niriSettings, cmd = a.niriSettings.Update(msg)
+	case ScreenKeybinds:
+		a.keybinds, cmd = a.keybinds.Update(msg)
+	case ScreenStartup:
+		a.startup, cmd = a.startup.Update(msg)
+	case ScreenAnimations:
+		a.animations, cmd = a.animations.Update(msg)
+	case ScreenOutputs:
+		a.outputs, cmd = a.outputs.Update(msg)
+	case ScreenWindows:
+		a.windows, cmd = a.windows.Update(msg)
+	}
+	return cmd
+}
+
+// contentCapturing reports whether the current screen is taking text
+// input
+func (a *App) contentCapturing() bool {
+	switch a.currentScreen {
+	case ScreenKeybinds:
+		return a.keybinds.Capturing()
+	case ScreenStartup:
+		return a.startup.Capturing()
+	case ScreenOutputs:
+		return a.outputs.Capturing()
+	case ScreenWindows:
+		return a.windows.Capturing()
+	}
+	return false
+}
+
 // View renders the application
 func (a *App) View() string {
 	if !a.ready {
@@ -229,7 +365,8 @@ func (a *App) View() string {
 	}
 
 	// Render header
-	headerText := GradientText("▄▄ nirimatic") + "  v" + Version
+	headerText := GradientText("▄▄ nirimatic") + "  v" + Version +
+		"  " + a.configPath + " (" + a.configSource.String() + ")"
 	header := HeaderStyle.Width(a.width - 4).Render(headerText)
 
 	// Render sidebar with focus indicator
@@ -248,16 +385,23 @@ func (a *App) View() string {
 	case ScreenNiriSettings:
 		content = a.niriSettings.View()
 	case ScreenAnimations:
-		content = "Animations - Coming Soon"
+		content = a.animations.View()
 	case ScreenKeybinds:
-		content = "Keybinds - Coming Soon"
+		content = a.keybinds.View()
 	case ScreenStartup:
-		content = "Startup Apps - Coming Soon"
+		content = a.startup.View()
+	case ScreenOutputs:
+		content = a.outputs.View()
+	case ScreenWindows:
+		content = a.windows.View()
 	case ScreenBackup:
 		content = "Backup - Coming Soon"
 	}
 
 	contentWidth := a.width - 28
+	if a.configErr != nil {
+		content = renderConfigError(a.configErr, contentWidth) + "\n" + content
+	}
 	contentStyle := ContentStyle.Width(contentWidth).Height(a.height - 6)
 	if a.focusContent {
 		contentStyle = contentStyle.BorderForeground(ColorCyan) // Highlight when focused
@@ -269,7 +413,23 @@ func (a *App) View() string {
 
 	// Render footer help based on focus
 	var helpText string
-	if a.focusContent {
+	if a.focusContent && a.currentScreen == ScreenKeybinds {
+		helpText = DimmedStyle.Render("esc") + " back  " + HelpLine(
+			a.keys.Filter, a.keys.Add, a.keys.Edit, a.keys.Delete, a.keys.Save, a.keys.Export, a.keys.Quit,
+		)
+	} else if a.focusContent && a.currentScreen == ScreenStartup {
+		helpText = DimmedStyle.Render("esc") + " back  " + HelpLine(
+			a.keys.Toggle, a.keys.Add, a.keys.Edit, a.keys.Delete, a.keys.Save, a.keys.Quit,
+		)
+	} else if a.focusContent && a.currentScreen == ScreenOutputs {
+		helpText = DimmedStyle.Render("esc") + " back  " + HelpLine(
+			a.keys.Toggle, a.keys.Add, a.keys.Edit, a.keys.Save, a.keys.Quit,
+		)
+	} else if a.focusContent && a.currentScreen == ScreenWindows {
+		helpText = DimmedStyle.Render("esc") + " back  " + HelpLine(
+			a.keys.Up, a.keys.Down, a.keys.Filter, a.keys.Add, a.keys.Quit,
+		)
+	} else if a.focusContent {
 		helpText = DimmedStyle.Render("esc") + " back  " + HelpLine(
 			a.keys.Up, a.keys.Down, a.keys.Enter,
 			a.keys.Noctalia, a.keys.Reload, a.keys.Quit, a.keys.Help,
@@ -296,12 +456,23 @@ func openNoctaliaSettings() tea.Cmd {
 	}
 }
 
-// reloadNiriConfig reloads the Niri configuration
-func reloadNiriConfig() tea.Cmd {
+// niriReloadedMsg is sent when niri was asked to load its config again
+type niriReloadedMsg struct {
+	err error
+}
+
+// reloadNiriConfig has niri check the config at path and, if it passes,
+// load it
+func reloadNiriConfig(path string) tea.Cmd {
 	return func() tea.Msg {
-		cmd := newExecCmd("niri", "msg", "action", "reload-config")
-		_ = cmd.Run()
-		return nil
+		if err := config.ValidateConfig(path); err != nil {
+			return niriReloadedMsg{err: err}
+		}
+		client, err := niriipc.NewClient()
+		if err == nil {
+			err = client.Action(niriipc.LoadConfigFile())
+		}
+		return niriReloadedMsg{err: err}
 	}
 }
 
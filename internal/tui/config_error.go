@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+)
+
+// outputTailLines is how much of niri's report the panel shows when it
+// names no location
+const outputTailLines = 6
+
+// configCheckedMsg carries the result of validating the config after niri
+// failed to load it
+type configCheckedMsg struct {
+	err error
+}
+
+// checkConfig asks niri what is wrong with the config at path
+func checkConfig(path string) tea.Cmd {
+	return func() tea.Msg {
+		return configCheckedMsg{err: config.ValidateConfig(path)}
+	}
+}
+
+// renderConfigError renders the panel showing where niri rejected the
+// config, quoting the lines around the error
+func renderConfigError(e *config.ValidationError, width int) string {
+	var b strings.Builder
+	b.WriteString(ErrorStyle.Render(SymbolCross + " niri rejected the config"))
+	b.WriteString("\n")
+	if e.File != "" {
+		b.WriteString(ValueStyle.Render(fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)))
+		b.WriteString("\n")
+	}
+	b.WriteString(ErrorStyle.Width(max(20, width-6)).Render(e.Message))
+	b.WriteString("\n")
+
+	if len(e.Source) > 0 {
+		b.WriteString("\n")
+		last := e.Source[len(e.Source)-1].Number
+		gutter := len(fmt.Sprint(last))
+		for _, line := range e.Source {
+			text := strings.ReplaceAll(line.Text, "\t", "    ")
+			number := fmt.Sprintf("%*d │ ", gutter, line.Number)
+			if line.Number != e.Line {
+				b.WriteString(DimmedStyle.Render(number + text))
+				b.WriteString("\n")
+				continue
+			}
+			b.WriteString(WarningStyle.Render(number) + ValueStyle.Render(text))
+			b.WriteString("\n")
+			if e.Column > 0 {
+				pad := strings.Repeat(" ", gutter) + " │ " + strings.Repeat(" ", caretOffset(line.Text, e.Column))
+				b.WriteString(WarningStyle.Render(pad + "^"))
+				b.WriteString("\n")
+			}
+		}
+	} else if e.File == "" && e.Output != "" {
+		lines := strings.Split(e.Output, "\n")
+		lines = lines[max(0, len(lines)-outputTailLines):]
+		b.WriteString(DimmedStyle.Render(strings.Join(lines, "\n")))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(DimmedStyle.Render("Fix the config and save or reload again; niri keeps running the last config it accepted."))
+	return CardStyle.BorderForeground(ColorRed).Padding(0, 1).Width(max(20, width-4)).Render(b.String())
+}
+
+// caretOffset returns the display column before a 1-based column of
+// line, counting tabs as four columns
+func caretOffset(line string, column int) int {
+	offset := 0
+	for i, r := range []rune(line) {
+		if i >= column-1 {
+			break
+		}
+		if r == '\t' {
+			offset += 4
+		} else {
+			offset++
+		}
+	}
+	return offset
+}
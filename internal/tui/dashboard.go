@@ -8,12 +8,14 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/niriipc"
 )
 
 // Service represents a monitored service
 type Service struct {
 	Name   string
 	Status string // "running", "stopped", "unknown"
+	Detail string // version, or why the service couldn't be reached
 }
 
 // DashboardModel is the model for the dashboard screen
@@ -22,6 +24,13 @@ type DashboardModel struct {
 	width       int
 	height      int
 	lastRefresh time.Time
+	reloaded    time.Time // when the config was last reloaded
+	reloadErr   error
+
+	// Live from niri's event stream
+	niri         *niriipc.State // nil while disconnected
+	configLoaded time.Time      // when niri last loaded its config
+	configFailed bool
 }
 
 // serviceStatusMsg is sent when service status is updated
@@ -61,10 +70,40 @@ func (m *DashboardModel) SetSize(width, height int) {
 func (m *DashboardModel) Update(msg tea.Msg) (*DashboardModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case serviceStatusMsg:
-		m.services = msg.services
+		for _, svc := range msg.services {
+			m.setService(svc)
+		}
 		m.lastRefresh = time.Now()
 		return m, nil
 
+	case niriStatusMsg:
+		svc := Service{Name: "niri", Status: "running", Detail: msg.version}
+		if msg.connected {
+			m.niri = niriipc.NewState()
+		} else {
+			m.niri = nil
+			svc.Status, svc.Detail = "stopped", msg.err.Error()
+			if msg.retry > 0 {
+				svc.Detail = fmt.Sprintf("retrying in %s: %v", msg.retry, msg.err)
+			}
+		}
+		m.setService(svc)
+		return m, nil
+
+	case niriipc.ConfigLoaded:
+		m.configLoaded, m.configFailed = time.Now(), msg.Failed
+		return m, nil
+
+	case niriipc.Event:
+		if m.niri != nil {
+			m.niri.Apply(msg)
+		}
+		return m, nil
+
+	case niriReloadedMsg:
+		m.reloaded, m.reloadErr = time.Now(), msg.err
+		return m, nil
+
 	case tickMsg:
 		return m, tea.Batch(m.checkServices(), m.tick())
 	}
@@ -90,11 +129,23 @@ func (m *DashboardModel) View() string {
 		status := RenderStatus(svc.Status)
 		name := lipgloss.NewStyle().Width(20).Render(svc.Name)
 		statusText := m.getStatusText(svc.Status)
+		if svc.Detail != "" {
+			statusText += "  " + DimmedStyle.MaxWidth(max(10, m.width-40)).Render(svc.Detail)
+		}
 		b.WriteString(fmt.Sprintf("  %s %s  %s\n", status, name, statusText))
 	}
 
 	b.WriteString("\n")
 
+	if m.niri != nil {
+		b.WriteString(SectionStyle.Render("─────────────────────────────────────────"))
+		b.WriteString("\n\n")
+		b.WriteString(CardTitleStyle.Render("Session"))
+		b.WriteString("\n\n")
+		b.WriteString(m.renderSession())
+		b.WriteString("\n")
+	}
+
 	// Quick Actions section
 	b.WriteString(SectionStyle.Render("─────────────────────────────────────────"))
 	b.WriteString("\n\n")
@@ -116,6 +167,25 @@ func (m *DashboardModel) View() string {
 		b.WriteString(fmt.Sprintf("  %s %s\n", keyStyle, action.desc))
 	}
 
+	switch {
+	case m.configFailed:
+		b.WriteString("\n")
+		b.WriteString(ErrorStyle.Render("niri couldn't load its config at " + m.configLoaded.Format("15:04:05") + "; it kept the previous one"))
+		b.WriteString("\n")
+	case m.reloadErr != nil:
+		b.WriteString("\n")
+		b.WriteString(ErrorStyle.Render(fmt.Sprintf("Reload failed: %v", m.reloadErr)))
+		b.WriteString("\n")
+	case !m.configLoaded.IsZero() && m.configLoaded.After(m.reloaded):
+		b.WriteString("\n")
+		b.WriteString(SuccessStyle.Render("niri loaded its config at " + m.configLoaded.Format("15:04:05")))
+		b.WriteString("\n")
+	case !m.reloaded.IsZero():
+		b.WriteString("\n")
+		b.WriteString(SuccessStyle.Render("Reloaded config at " + m.reloaded.Format("15:04:05")))
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
 
 	// Last refresh time
@@ -127,6 +197,48 @@ func (m *DashboardModel) View() string {
 	return b.String()
 }
 
+// renderSession renders the focused window, the workspace shown on each
+// output and the keyboard layout
+func (m *DashboardModel) renderSession() string {
+	var b strings.Builder
+	label := lipgloss.NewStyle().Width(20)
+	valueWidth := max(10, m.width-24)
+
+	focused := DimmedStyle.Render("none")
+	if w := m.niri.FocusedWindow(); w != nil {
+		text := w.AppIDOr("unknown app")
+		if title := w.TitleOr(""); title != "" {
+			text += " — " + title
+		}
+		focused = ValueStyle.MaxWidth(valueWidth).Render(text)
+	}
+	fmt.Fprintf(&b, "  %s %s\n", label.Render("Focused window"), focused)
+
+	for _, ws := range m.niri.ActiveWorkspaces() {
+		text := "workspace " + ws.Label()
+		if ws.IsFocused {
+			text += " (focused)"
+		}
+		fmt.Fprintf(&b, "  %s %s\n", label.Render(*ws.Output), ValueStyle.Render(text))
+	}
+
+	layout := DimmedStyle.Render("unknown")
+	if k := m.niri.KeyboardLayouts; k != nil && k.Current() != "" {
+		layout = ValueStyle.Render(k.Current())
+	}
+	fmt.Fprintf(&b, "  %s %s\n", label.Render("Keyboard layout"), layout)
+	return b.String()
+}
+
+// setService replaces the service with the same name
+func (m *DashboardModel) setService(svc Service) {
+	for i := range m.services {
+		if m.services[i].Name == svc.Name {
+			m.services[i] = svc
+		}
+	}
+}
+
 // getStatusText returns a human-readable status text
 func (m *DashboardModel) getStatusText(status string) string {
 	switch status {
@@ -139,15 +251,19 @@ func (m *DashboardModel) getStatusText(status string) string {
 	}
 }
 
-// checkServices checks the status of all services
+// polledServices are the services checked on every tick. niri reports
+// itself through its event stream.
+var polledServices = []string{"noctalia-shell", "stasis"}
+
+// checkServices checks the status of the polled services
 func (m *DashboardModel) checkServices() tea.Cmd {
 	return func() tea.Msg {
-		services := make([]Service, len(m.services))
-		for i, svc := range m.services {
-			services[i] = Service{
-				Name:   svc.Name,
-				Status: checkServiceStatus(svc.Name),
-			}
+		var services []Service
+		for _, name := range polledServices {
+			services = append(services, Service{
+				Name:   name,
+				Status: checkServiceStatus(name),
+			})
 		}
 		return serviceStatusMsg{services: services}
 	}
@@ -162,15 +278,6 @@ func (m *DashboardModel) tick() tea.Cmd {
 
 // checkServiceStatus checks if a service is running
 func checkServiceStatus(name string) string {
-	// For niri, check if the compositor is running via niri msg
-	if name == "niri" {
-		cmd := exec.Command("niri", "msg", "version")
-		if err := cmd.Run(); err == nil {
-			return "running"
-		}
-		return "stopped"
-	}
-
 	// For noctalia-shell, check via quickshell IPC
 	if name == "noctalia-shell" {
 		// Check if quickshell is running with noctalia-shell config
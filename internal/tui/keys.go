@@ -21,6 +21,7 @@ type KeyMap struct {
 	Reload     key.Binding
 	Noctalia   key.Binding
 	RestartNiri key.Binding
+	Export     key.Binding
 
 	// Editing
 	Toggle key.Binding
@@ -94,6 +95,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("R"),
 			key.WithHelp("R", "restart niri"),
 		),
+		Export: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "export"),
+		),
 
 		// Editing
 		Toggle: key.NewBinding(
@@ -133,7 +138,7 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 		{k.Enter, k.Back, k.Tab, k.ShiftTab},
 		{k.Save, k.Reload, k.Noctalia, k.RestartNiri},
 		{k.Toggle, k.Add, k.Edit, k.Delete},
-		{k.Filter, k.Help, k.Quit},
+		{k.Filter, k.Export, k.Help, k.Quit},
 	}
 }
 
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/niriipc"
+)
+
+// Backoff between attempts to reconnect to niri's event stream
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// niriStatusMsg is sent when the event stream connects or drops. Each
+// event on the stream follows as its own message, a niriipc.Event.
+type niriStatusMsg struct {
+	connected bool
+	version   string        // niri's version, once connected
+	err       error         // why the stream dropped
+	retry     time.Duration // when it is tried again; zero for never
+}
+
+// niriEvents keeps a connection to niri's event stream open and hands
+// its events to bubbletea one message at a time
+type niriEvents struct {
+	msgs chan tea.Msg
+}
+
+// newNiriEvents creates the stream, not yet connected
+func newNiriEvents() *niriEvents {
+	return &niriEvents{msgs: make(chan tea.Msg, 64)}
+}
+
+// start connects in the background and waits for the first message
+func (e *niriEvents) start() tea.Cmd {
+	go e.run()
+	return e.wait()
+}
+
+// wait returns the next message. The app waits again after each one.
+func (e *niriEvents) wait() tea.Cmd {
+	return func() tea.Msg {
+		return <-e.msgs
+	}
+}
+
+// run connects, forwards events until the stream drops and connects
+// again, backing off while niri can't be reached
+func (e *niriEvents) run() {
+	backoff := minBackoff
+	for {
+		connected, err := e.stream()
+		if errors.Is(err, niriipc.ErrNoSocket) {
+			// Not in a niri session; no point trying again
+			e.msgs <- niriStatusMsg{err: err}
+			return
+		}
+		if connected {
+			backoff = minBackoff
+		}
+		e.msgs <- niriStatusMsg{err: err, retry: backoff}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// stream forwards the events of one connection, reporting whether it
+// connected and why it ended
+func (e *niriEvents) stream() (bool, error) {
+	client, err := niriipc.NewClient()
+	if err != nil {
+		return false, err
+	}
+	version, err := client.Version()
+	if err != nil {
+		return false, err
+	}
+	stream, err := client.EventStream()
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+
+	e.msgs <- niriStatusMsg{connected: true, version: version}
+	for {
+		event, err := stream.Next()
+		var bad *niriipc.ProtocolError
+		if errors.As(err, &bad) {
+			// An event this client can't read; the stream is still fine
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return true, errors.New("niri closed the event stream")
+		}
+		if err != nil {
+			return true, err
+		}
+		e.msgs <- event
+	}
+}
@@ -0,0 +1,140 @@
+package screens
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/animcurve"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// Size of the curve plot in terminal cells
+const (
+	plotWidth  = 48
+	plotHeight = 6
+)
+
+// plotLines is how many lines renderAnimationPlot takes
+const plotLines = plotHeight + 4
+
+// brailleDots maps a dot's column and row within a cell to its bit in a
+// braille character
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// brailleCanvas is a grid of braille cells, two dots wide and four high
+type brailleCanvas struct {
+	width, height int // in dots
+	cells         [][]rune
+}
+
+func newBrailleCanvas(cols, rows int) *brailleCanvas {
+	c := &brailleCanvas{width: cols * 2, height: rows * 4, cells: make([][]rune, rows)}
+	for i := range c.cells {
+		c.cells[i] = make([]rune, cols)
+	}
+	return c
+}
+
+// set turns on the dot at x, y with y growing downwards
+func (c *brailleCanvas) set(x, y int) {
+	if x < 0 || y < 0 || x >= c.width || y >= c.height {
+		return
+	}
+	c.cells[y/4][x/2] |= brailleDots[x%2][y%4]
+}
+
+// row returns one line of the canvas
+func (c *brailleCanvas) row(i int) string {
+	var b strings.Builder
+	for _, dots := range c.cells[i] {
+		b.WriteRune(0x2800 + dots)
+	}
+	return b.String()
+}
+
+// renderAnimationPlot draws an animation's position over time, with a
+// dotted line at the target and its settle time and overshoot
+func renderAnimationPlot(a *config.Animation, slowdown float64) string {
+	curve := animcurve.Simulate(a.AnimationParams, slowdown)
+
+	// Show a little of the rest after the curve settles
+	span := max(curve.Settle+curve.Settle/10, 10*animcurve.Step)
+	if !curve.Settled {
+		span = curve.Settle
+	}
+	top := max(1, 1+curve.Overshoot)
+	bottom := 0.0
+	for _, x := range curve.Points {
+		bottom = min(bottom, x)
+	}
+
+	canvas := newBrailleCanvas(plotWidth, plotHeight)
+	toY := func(x float64) int {
+		return int(math.Round((top - x) / (top - bottom) * float64(canvas.height-1)))
+	}
+
+	target := toY(1)
+	for x := 0; x < canvas.width; x += 3 {
+		canvas.set(x, target)
+	}
+
+	prev := toY(0)
+	for x := 0; x < canvas.width; x++ {
+		t := time.Duration(float64(span) * float64(x) / float64(canvas.width-1))
+		y := toY(curve.At(t))
+		// Join the dots so fast moves don't leave gaps
+		for fill := min(prev, y); fill <= max(prev, y); fill++ {
+			canvas.set(x, fill)
+		}
+		prev = y
+	}
+
+	var b strings.Builder
+	lineStyle := lipgloss.NewStyle().Foreground(styles.ColorCyan)
+	for i := range plotHeight {
+		label := ""
+		switch i {
+		case target / 4:
+			label = "1.0"
+		case plotHeight - 1:
+			label = fmt.Sprintf("%.1f", bottom)
+		}
+		b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  %5s │", label)))
+		b.WriteString(lineStyle.Render(canvas.row(i)))
+		b.WriteString("\n")
+	}
+	b.WriteString(styles.DimmedStyle.Render("        └" + strings.Repeat("─", plotWidth)))
+	b.WriteString("\n")
+	end := formatMs(span)
+	b.WriteString(styles.DimmedStyle.Render("         0" + strings.Repeat(" ", max(1, plotWidth-1-len(end))) + end))
+	b.WriteString("\n")
+
+	stats := fmt.Sprintf("settles in %s", formatMs(curve.Settle))
+	if !curve.Settled {
+		stats = fmt.Sprintf("still moving after %s", formatMs(curve.Settle))
+	}
+	if curve.Overshoot >= 0.001 {
+		stats += fmt.Sprintf(" • overshoots by %.1f%%", curve.Overshoot*100)
+	} else {
+		stats += " • no overshoot"
+	}
+	if slowdown != 1 {
+		stats += fmt.Sprintf(" • %.1f× slowdown", slowdown)
+	}
+	b.WriteString("         ")
+	b.WriteString(styles.ValueStyle.Render(stats))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// formatMs formats a duration in whole milliseconds
+func formatMs(d time.Duration) string {
+	return fmt.Sprintf("%d ms", d.Milliseconds())
+}
@@ -0,0 +1,590 @@
+package screens
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// animRowType is what a line of the animations screen edits
+type animRowType int
+
+const (
+	animRowPresets   animRowType = iota // preset picker
+	animRowEnabled                      // animations { off }
+	animRowSlowdown                     // animations { slowdown }
+	animRowAnimation                    // header of one animation
+	animRowOff                          // the animation's off flag
+	animRowKind                         // spring or easing
+	animRowSlider                       // one float parameter
+	animRowCurve                        // easing curve
+	animRowShader                       // custom-shader, edited in $EDITOR
+)
+
+// animRow is one line of the animations screen
+type animRow struct {
+	kind   animRowType
+	anim   *config.Animation
+	slider *floatSlider
+}
+
+// floatSlider edits a float parameter between min and max
+type floatSlider struct {
+	label    string
+	min, max float64
+	step     float64
+	stops    []float64 // fixed values to step through instead of step, for log-scale values
+	format   string
+	get      func() float64
+	set      func(float64)
+}
+
+// AnimationsModel is the model for the animations screen
+type AnimationsModel struct {
+	config   *config.NiriConfig
+	expanded map[string]bool // animations showing their parameters
+	preset   int             // preset under the cursor on the preset row
+	cursor   int
+	scroll   int
+	width    int
+	height   int
+	dirty    bool
+	message  string
+	err      error
+}
+
+// animationsSavedMsg is sent when the animations screen saved the config
+type animationsSavedMsg struct {
+	err error
+}
+
+// epsilonStops are the values the epsilon slider steps through
+var epsilonStops = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01}
+
+// animationsHeaderLines is how many lines the screen uses above and
+// below the scrolling list
+const animationsHeaderLines = 14
+
+// NewAnimationsModel creates a new animations model
+func NewAnimationsModel() *AnimationsModel {
+	return &AnimationsModel{expanded: map[string]bool{}}
+}
+
+// Init does nothing; the config arrives with the configLoadedMsg the
+// settings screen requests
+func (m *AnimationsModel) Init() tea.Cmd {
+	return nil
+}
+
+// SetSize sets the dimensions
+func (m *AnimationsModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update handles messages
+func (m *AnimationsModel) Update(msg tea.Msg) (*AnimationsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case configLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.config = msg.config
+		m.err = nil
+		m.dirty = false
+		m.syncPreset()
+		m.cursor = min(m.cursor, len(m.rows())-1)
+		return m, nil
+
+	case configSavedMsg, keybindsSavedMsg, startupSavedMsg, outputsSavedMsg, windowRuleSavedMsg:
+		// Saving from another screen writes the animations too
+		if savedErr(msg) == nil {
+			m.dirty = false
+		}
+		return m, nil
+
+	case shaderEditedMsg:
+		if m.config != nil {
+			m.applyShader(msg)
+		}
+		return m, nil
+
+	case animationsSavedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error saving: %v", msg.err)
+		} else {
+			m.message = "Animations saved!"
+			m.dirty = false
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.config == nil {
+			return m, nil
+		}
+		return m, m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// handleKey handles a key press
+func (m *AnimationsModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	rows := m.rows()
+	row := rows[m.cursor]
+
+	switch {
+	case key.Matches(msg, keyUp):
+		m.cursor = max(0, m.cursor-1)
+	case key.Matches(msg, keyDown):
+		m.cursor = min(len(rows)-1, m.cursor+1)
+	case key.Matches(msg, keyLeft):
+		m.adjust(row, -1)
+	case key.Matches(msg, keyRight):
+		m.adjust(row, 1)
+	case row.kind == animRowShader && (key.Matches(msg, keyToggle) || msg.String() == "enter"):
+		return editShader(row.anim)
+	case key.Matches(msg, keyToggle), msg.String() == "enter":
+		m.toggle(row)
+	case msg.String() == "d":
+		if row.anim != nil {
+			row.anim.AnimationParams = config.DefaultAnimationParams(row.anim.Name)
+			m.changed()
+			m.message = "Reset " + row.anim.Name + " to niri's default"
+		}
+	case key.Matches(msg, keySave):
+		return m.saveConfig()
+	}
+	m.clampScroll()
+	return nil
+}
+
+// adjust moves the value on a row by one step in dir
+func (m *AnimationsModel) adjust(row animRow, dir int) {
+	switch row.kind {
+	case animRowPresets:
+		n := len(config.AnimationPresets())
+		m.preset = (m.preset + n + dir) % n
+		return
+	case animRowKind:
+		if row.anim.Kind == config.AnimationSpring {
+			row.anim.Kind = config.AnimationEasing
+		} else {
+			row.anim.Kind = config.AnimationSpring
+		}
+	case animRowCurve:
+		curves := config.EasingCurves
+		i := slices.Index(curves, row.anim.Easing.Curve)
+		row.anim.Easing.Curve = curves[(i+len(curves)+dir)%len(curves)]
+	case animRowSlider:
+		row.slider.adjust(dir)
+	case animRowSlowdown:
+		m.slowdownSlider().adjust(dir)
+	default:
+		return
+	}
+	m.changed()
+}
+
+// toggle handles space on a row: apply a preset, flip a flag or expand
+// an animation
+func (m *AnimationsModel) toggle(row animRow) {
+	switch row.kind {
+	case animRowPresets:
+		p := config.AnimationPresets()[m.preset]
+		m.config.ApplyAnimationPreset(p)
+		m.message = "Applied the " + p.Name + " preset"
+	case animRowEnabled:
+		m.config.AnimationsOff = !m.config.AnimationsOff
+	case animRowAnimation:
+		m.expanded[row.anim.Name] = !m.expanded[row.anim.Name]
+		return
+	case animRowOff:
+		row.anim.Off = !row.anim.Off
+	case animRowKind, animRowCurve:
+		m.adjust(row, 1)
+		return
+	default:
+		return
+	}
+	m.changed()
+}
+
+// changed marks the config dirty after an edit
+func (m *AnimationsModel) changed() {
+	m.dirty = true
+	m.syncPreset()
+}
+
+// syncPreset points the preset row at the preset the settings match
+func (m *AnimationsModel) syncPreset() {
+	active := m.config.ActiveAnimationPreset()
+	for i, p := range config.AnimationPresets() {
+		if p.Name == active {
+			m.preset = i
+		}
+	}
+}
+
+// rows returns every line of the screen below the title
+func (m *AnimationsModel) rows() []animRow {
+	rows := []animRow{{kind: animRowPresets}, {kind: animRowEnabled}, {kind: animRowSlowdown}}
+	if m.config == nil {
+		return rows
+	}
+	for _, a := range m.config.Animations {
+		rows = append(rows, animRow{kind: animRowAnimation, anim: a})
+		if !m.expanded[a.Name] {
+			continue
+		}
+		rows = append(rows,
+			animRow{kind: animRowOff, anim: a},
+			animRow{kind: animRowKind, anim: a},
+		)
+		if a.Kind == config.AnimationEasing {
+			rows = append(rows, animRow{kind: animRowCurve, anim: a})
+		}
+		for _, s := range animationSliders(a) {
+			rows = append(rows, animRow{kind: animRowSlider, anim: a, slider: s})
+		}
+		if a.ShaderEntryPoint() != "" {
+			rows = append(rows, animRow{kind: animRowShader, anim: a})
+		}
+	}
+	return rows
+}
+
+// animationSliders returns the sliders for an animation's parameters
+func animationSliders(a *config.Animation) []*floatSlider {
+	if a.Kind == config.AnimationSpring {
+		s := &a.Spring
+		return []*floatSlider{
+			{label: "Damping Ratio", min: 0.1, max: 2, step: 0.05, format: "%.2f",
+				get: func() float64 { return s.DampingRatio }, set: func(v float64) { s.DampingRatio = v }},
+			{label: "Stiffness", min: 50, max: 3000, step: 50, format: "%.0f",
+				get: func() float64 { return s.Stiffness }, set: func(v float64) { s.Stiffness = v }},
+			{label: "Epsilon", stops: epsilonStops, format: "%g",
+				get: func() float64 { return s.Epsilon }, set: func(v float64) { s.Epsilon = v }},
+		}
+	}
+
+	e := &a.Easing
+	sliders := []*floatSlider{
+		{label: "Duration", min: 0, max: 1500, step: 10, format: "%.0f ms",
+			get: func() float64 { return float64(e.DurationMs) }, set: func(v float64) { e.DurationMs = int(v) }},
+	}
+	if e.Curve == "cubic-bezier" {
+		for i, label := range []string{"x1", "y1", "x2", "y2"} {
+			lo, hi := 0.0, 1.0
+			if i%2 == 1 {
+				// y may leave [0, 1] to overshoot
+				lo, hi = -1, 2
+			}
+			sliders = append(sliders, &floatSlider{
+				label: "Bezier " + label, min: lo, max: hi, step: 0.05, format: "%.2f",
+				get: func() float64 { return e.Bezier[i] }, set: func(v float64) { e.Bezier[i] = v },
+			})
+		}
+	}
+	return sliders
+}
+
+// slowdownSlider returns the slider for the global slowdown
+func (m *AnimationsModel) slowdownSlider() *floatSlider {
+	c := m.config
+	return &floatSlider{
+		label: "Slowdown", min: 0.1, max: 5, step: 0.1, format: "%.1f×",
+		get: func() float64 { return c.AnimationSlowdown }, set: func(v float64) { c.AnimationSlowdown = v },
+	}
+}
+
+// adjust moves the slider by one step in dir
+func (s *floatSlider) adjust(dir int) {
+	v := s.get()
+	if len(s.stops) > 0 {
+		i := s.stopIndex()
+		if s.stops[i] == v || (v < s.stops[i]) == (dir > 0) {
+			// Off-stop values move to the neighbouring stop first
+			i += dir
+		}
+		s.set(s.stops[max(0, min(len(s.stops)-1, i))])
+		return
+	}
+	v += float64(dir) * s.step
+	// Snap to the step grid so values don't pick up float noise
+	v = math.Round(v/s.step) * s.step
+	s.set(max(s.min, min(s.max, v)))
+}
+
+// stopIndex returns the index of the stop nearest the value
+func (s *floatSlider) stopIndex() int {
+	v := s.get()
+	best := 0
+	for i, stop := range s.stops {
+		if math.Abs(math.Log(stop/v)) < math.Abs(math.Log(s.stops[best]/v)) {
+			best = i
+		}
+	}
+	return best
+}
+
+// fraction returns where the value sits between the ends, from 0 to 1
+func (s *floatSlider) fraction() float64 {
+	if len(s.stops) > 0 {
+		return float64(s.stopIndex()) / float64(len(s.stops)-1)
+	}
+	if s.max == s.min {
+		return 0
+	}
+	return max(0, min(1, (s.get()-s.min)/(s.max-s.min)))
+}
+
+// clampScroll keeps the cursor inside the visible part of the fine
+// tuning list. scroll counts from the first animation.
+func (m *AnimationsModel) clampScroll() {
+	cursor := m.cursor - int(animRowAnimation)
+	if cursor < m.scroll {
+		m.scroll = max(0, cursor)
+	}
+	if cursor >= m.scroll+m.visibleRows() {
+		m.scroll = cursor - m.visibleRows() + 1
+	}
+}
+
+// visibleRows is how many list lines fit on screen, leaving room for
+// the plot of the animation under the cursor
+func (m *AnimationsModel) visibleRows() int {
+	lines := m.height - animationsHeaderLines
+	if m.plotted() != nil {
+		lines -= plotLines + 1
+	}
+	return max(5, lines)
+}
+
+// plotted returns the animation under the cursor, whose curve is drawn
+// below the list
+func (m *AnimationsModel) plotted() *config.Animation {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return nil
+	}
+	return rows[m.cursor].anim
+}
+
+// View renders the animations screen
+func (m *AnimationsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(styles.TitleStyle.Render("Animations"))
+	b.WriteString("\n")
+	b.WriteString(styles.SectionStyle.Render("─────────────────────────────────────────"))
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+	if m.config == nil {
+		b.WriteString(styles.DimmedStyle.Render("Loading config..."))
+		return b.String()
+	}
+	if m.message != "" {
+		b.WriteString(styles.SuccessStyle.Render(m.message))
+		b.WriteString("\n\n")
+	}
+
+	rows := m.rows()
+	b.WriteString(styles.CardTitleStyle.Render("Presets"))
+	b.WriteString("\n")
+	for i := range animRowAnimation {
+		b.WriteString(m.renderRow(rows[i], m.cursor == int(i)))
+		if i == animRowPresets {
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(styles.CardTitleStyle.Render("Fine Tuning"))
+	b.WriteString("\n")
+
+	list := rows[animRowAnimation:]
+	start := min(m.scroll, len(list))
+	end := min(len(list), start+m.visibleRows())
+	for i := start; i < end; i++ {
+		b.WriteString(m.renderRow(list[i], m.cursor == i+int(animRowAnimation)))
+	}
+
+	if a := m.plotted(); a != nil {
+		b.WriteString("\n")
+		if a.Off || m.config.AnimationsOff {
+			b.WriteString(styles.DimmedStyle.Render("    " + a.Name + " is off: it jumps straight to the end"))
+			b.WriteString("\n")
+		} else {
+			b.WriteString(renderAnimationPlot(a, m.config.AnimationSlowdown))
+		}
+	}
+
+	if m.dirty {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("* Unsaved changes"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(styles.DimmedStyle.Render("↑↓ navigate • ←→ adjust • space expand/toggle/apply • d niri default • s save"))
+	return b.String()
+}
+
+// renderRow renders one line of the screen
+func (m *AnimationsModel) renderRow(row animRow, selected bool) string {
+	cursor := "  "
+	labelStyle := styles.LabelStyle
+	if selected {
+		cursor = styles.SuccessStyle.Render(styles.SymbolArrow + " ")
+		labelStyle = labelStyle.Foreground(styles.ColorGreen)
+	}
+
+	switch row.kind {
+	case animRowPresets:
+		return cursor + m.renderPresets(selected) + "\n"
+
+	case animRowEnabled:
+		label := labelStyle.Width(22).Render("Animations")
+		return fmt.Sprintf("%s%s %s\n", cursor, label, renderFormToggle(!m.config.AnimationsOff, selected))
+
+	case animRowSlowdown:
+		s := m.slowdownSlider()
+		return fmt.Sprintf("%s%s %s\n", cursor, labelStyle.Width(22).Render(s.label), renderFloatSlider(s, selected))
+
+	case animRowAnimation:
+		marker := "▶"
+		if m.expanded[row.anim.Name] {
+			marker = "▼"
+		}
+		nameStyle := lipgloss.NewStyle().Foreground(styles.ColorForeground)
+		if selected {
+			nameStyle = nameStyle.Foreground(styles.ColorGreen).Bold(true)
+		}
+		summary := animationSummary(row.anim)
+		if row.anim.AnimationParams == config.DefaultAnimationParams(row.anim.Name) {
+			summary += " (default)"
+		}
+		return fmt.Sprintf("%s%s %s  %s\n", cursor, marker, nameStyle.Width(32).Render(row.anim.Name),
+			styles.DimmedStyle.Render(summary))
+	}
+
+	// Parameter rows are indented under their animation
+	cursor = "  " + cursor
+	var label, value string
+	switch row.kind {
+	case animRowOff:
+		label, value = "Enabled", renderFormToggle(!row.anim.Off, selected)
+	case animRowKind:
+		label, value = "Type", renderChoice(row.anim.Kind.String(), selected)
+	case animRowCurve:
+		label, value = "Curve", renderChoice(row.anim.Easing.Curve, selected)
+	case animRowSlider:
+		label, value = row.slider.label, renderFloatSlider(row.slider, selected)
+	case animRowShader:
+		label, value = "Custom Shader", renderShaderStatus(row.anim, selected)
+	}
+	return fmt.Sprintf("%s%s %s\n", cursor, labelStyle.Width(20).Render(label), value)
+}
+
+// renderPresets renders the preset radio buttons
+func (m *AnimationsModel) renderPresets(selected bool) string {
+	active := m.config.ActiveAnimationPreset()
+	var parts []string
+	for i, p := range config.AnimationPresets() {
+		radio := "( )"
+		if p.Name == active {
+			radio = "(●)"
+		}
+		style := lipgloss.NewStyle().Foreground(styles.ColorForeground)
+		switch {
+		case selected && i == m.preset:
+			style = style.Foreground(styles.ColorGreen).Bold(true)
+		case p.Name == active:
+			style = style.Foreground(styles.ColorCyan)
+		}
+		parts = append(parts, style.Render(radio+" "+p.Name))
+	}
+	line := strings.Join(parts, "    ")
+	if selected {
+		line += "   " + styles.DimmedStyle.Render(config.AnimationPresets()[m.preset].Description)
+	}
+	return line
+}
+
+// animationSummary describes an animation's parameters in one line
+func animationSummary(a *config.Animation) string {
+	if a.Off {
+		return "off"
+	}
+	if a.Kind == config.AnimationSpring {
+		return fmt.Sprintf("spring damping %.2f, stiffness %.0f", a.Spring.DampingRatio, a.Spring.Stiffness)
+	}
+	return fmt.Sprintf("%s, %d ms", a.Easing.Curve, a.Easing.DurationMs)
+}
+
+// renderFloatSlider renders a slider with its value
+func renderFloatSlider(s *floatSlider, selected bool) string {
+	width := 20
+	pos := int(math.Round(s.fraction() * float64(width)))
+	slider := strings.Repeat("─", pos) + "●" + strings.Repeat("─", width-pos)
+
+	style := styles.DimmedStyle
+	valueStyle := styles.ValueStyle
+	if selected {
+		style = lipgloss.NewStyle().Foreground(styles.ColorCyan)
+		valueStyle = valueStyle.Foreground(styles.ColorGreen).Bold(true)
+	}
+	return fmt.Sprintf("[%s] %s", style.Render(slider), valueStyle.Render(fmt.Sprintf(s.format, s.get())))
+}
+
+// renderShaderStatus describes an animation's custom shader
+func renderShaderStatus(a *config.Animation, selected bool) string {
+	hint := ""
+	if selected {
+		hint = styles.DimmedStyle.Render("  enter edits in $EDITOR")
+	}
+	if a.CustomShader == "" {
+		return styles.ValueStyle.Render("none") + hint
+	}
+
+	lines := strings.Count(a.CustomShader, "\n")
+	status := styles.SuccessStyle.Render(styles.SymbolCheck + " " + a.ShaderEntryPoint())
+	if err := a.CheckShader(a.CustomShader); err != nil {
+		status = styles.ErrorStyle.Render(styles.SymbolCross + " " + err.Error())
+	}
+	return styles.ValueStyle.Render(fmt.Sprintf("%d lines ", lines)) + status + hint
+}
+
+// renderChoice renders a value picked with ←→
+func renderChoice(value string, selected bool) string {
+	if selected {
+		return styles.SuccessStyle.Bold(true).Render("◂ " + value + " ▸")
+	}
+	return styles.ValueStyle.Render("  " + value)
+}
+
+// saveConfig saves the config file
+func (m *AnimationsModel) saveConfig() tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = animationsSavedMsg{err: fmt.Errorf("save failed: %v", r)}
+			}
+		}()
+
+		if m.config == nil {
+			return animationsSavedMsg{err: fmt.Errorf("no config loaded")}
+		}
+		return animationsSavedMsg{err: config.SaveNiriConfig(m.config)}
+	}
+}
@@ -0,0 +1,178 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+	"github.com/edellingham/nirimatic/internal/system"
+	"github.com/sahilm/fuzzy"
+)
+
+// appPicker is the dialog that picks an installed app to spawn
+type appPicker struct {
+	filter  textinput.Model
+	apps    []system.Application
+	visible []int // indexes into apps, in match order
+	cursor  int
+	scroll  int
+	loading bool
+
+	app      *system.Application // picked app
+	accepted bool
+	done     bool
+}
+
+// appsLoadedMsg carries the installed apps
+type appsLoadedMsg struct {
+	apps []system.Application
+}
+
+// appPickerRows is how many apps the picker shows at once
+const appPickerRows = 12
+
+// newAppPicker opens the picker and starts reading the desktop files
+func newAppPicker() (*appPicker, tea.Cmd) {
+	p := &appPicker{loading: true}
+	p.filter = textinput.New()
+	p.filter.Prompt = "/ "
+	p.filter.Placeholder = "filter apps"
+	p.filter.Width = 40
+
+	load := func() tea.Msg {
+		return appsLoadedMsg{apps: system.LoadApplications()}
+	}
+	return p, tea.Batch(p.filter.Focus(), load)
+}
+
+// command returns the picked app's command as KDL arguments
+func (p *appPicker) command() string {
+	return config.StringArgs(p.app.Exec)
+}
+
+// update handles a message
+func (p *appPicker) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case appsLoadedMsg:
+		p.apps = msg.apps
+		p.loading = false
+		p.applyFilter()
+		return nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			p.done = true
+			return nil
+		case "enter":
+			if p.cursor < len(p.visible) {
+				p.app = &p.apps[p.visible[p.cursor]]
+				p.accepted = true
+				p.done = true
+			}
+			return nil
+		case "up", "ctrl+p":
+			p.move(-1)
+			return nil
+		case "down", "ctrl+n":
+			p.move(1)
+			return nil
+		case "pgup":
+			p.move(-appPickerRows)
+			return nil
+		case "pgdown":
+			p.move(appPickerRows)
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	before := p.filter.Value()
+	p.filter, cmd = p.filter.Update(msg)
+	if p.filter.Value() != before {
+		p.applyFilter()
+	}
+	return cmd
+}
+
+// move moves the cursor by delta, scrolling to keep it in view
+func (p *appPicker) move(delta int) {
+	p.cursor = max(0, min(len(p.visible)-1, p.cursor+delta))
+	if p.cursor < p.scroll {
+		p.scroll = p.cursor
+	}
+	if p.cursor >= p.scroll+appPickerRows {
+		p.scroll = p.cursor - appPickerRows + 1
+	}
+}
+
+// applyFilter recomputes the visible apps, fuzzy matching the filter
+// against each app's names and desktop file ID. Apps that ask to stay
+// out of menus only show up when searched for.
+func (p *appPicker) applyFilter() {
+	p.visible = nil
+	p.cursor, p.scroll = 0, 0
+
+	pattern := strings.TrimSpace(p.filter.Value())
+	if pattern == "" {
+		for i, app := range p.apps {
+			if !app.NoDisplay {
+				p.visible = append(p.visible, i)
+			}
+		}
+		return
+	}
+
+	targets := make([]string, len(p.apps))
+	for i, app := range p.apps {
+		targets[i] = app.Name + " " + app.GenericName + " " + app.ID
+	}
+	for _, match := range fuzzy.Find(pattern, targets) {
+		p.visible = append(p.visible, match.Index)
+	}
+}
+
+// view renders the picker
+func (p *appPicker) view() string {
+	var b strings.Builder
+	b.WriteString(styles.CardTitleStyle.Render("Pick Application"))
+	b.WriteString("\n\n")
+	b.WriteString(p.filter.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case p.loading:
+		b.WriteString(styles.DimmedStyle.Render("Reading desktop files..."))
+		b.WriteString("\n")
+	case len(p.visible) == 0:
+		b.WriteString(styles.DimmedStyle.Render("No matching apps"))
+		b.WriteString("\n")
+	}
+
+	end := min(len(p.visible), p.scroll+appPickerRows)
+	for i := p.scroll; i < end; i++ {
+		app := p.apps[p.visible[i]]
+		selected := i == p.cursor
+
+		nameStyle := lipgloss.NewStyle().Width(32).Foreground(styles.ColorForeground)
+		if selected {
+			nameStyle = nameStyle.Foreground(styles.ColorGreen).Bold(true)
+		}
+		b.WriteString(renderRowStart(selected))
+		b.WriteString(nameStyle.Render(truncate(app.Name, 31)))
+		b.WriteString(styles.DimmedStyle.Render(truncate(strings.Join(app.Exec, " "), 50)))
+		b.WriteString("\n")
+	}
+	if len(p.visible) > appPickerRows {
+		b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  %d of %d", p.cursor+1, len(p.visible))))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("type to filter • ↑↓ move • enter pick • esc cancel"))
+	return b.String()
+}
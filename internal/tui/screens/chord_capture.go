@@ -0,0 +1,378 @@
+package screens
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// Kitty keyboard protocol sequences. Flags 1|2 report modified keys
+// (including Super) unambiguously, plus press/repeat/release events.
+// The flags are pushed while the capture dialog is open and popped
+// afterwards, since bubbletea can't decode these keys itself.
+const (
+	kittyPush  = "\x1b[>3u"
+	kittyPop   = "\x1b[<u"
+	kittyQuery = "\x1b[?u"
+)
+
+// chordCapture is the dialog that records the next key press as a chord
+type chordCapture struct {
+	modKey   string // modifier written as Mod
+	chord    *config.KeyChord
+	kitty    bool // the terminal answered the kitty protocol query
+	err      string
+	accepted bool
+	done     bool
+}
+
+// newChordCapture opens the capture dialog and turns on the kitty
+// keyboard protocol
+func newChordCapture(modKey string) (*chordCapture, tea.Cmd) {
+	return &chordCapture{modKey: modKey}, writeTerminal(kittyPush + kittyQuery)
+}
+
+// writeTerminal sends an escape sequence to the terminal, between the
+// renderer's frames
+func writeTerminal(seq string) tea.Cmd {
+	return func() tea.Msg {
+		_, _ = io.WriteString(Terminal, seq)
+		return nil
+	}
+}
+
+// update handles a message. Once the dialog is done it pops the kitty
+// flags again.
+func (c *chordCapture) update(msg tea.Msg) tea.Cmd {
+	var chord config.KeyChord
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Paste {
+			return nil
+		}
+		switch msg.String() {
+		case "esc":
+			return c.finish(false)
+		case "enter":
+			if c.chord != nil {
+				return c.finish(true)
+			}
+		case "tab":
+			// Type the chord instead
+			return c.finish(false)
+		}
+		var ok bool
+		chord, ok = chordFromKey(msg)
+		if !ok {
+			c.err = fmt.Sprintf("can't name %q as an xkb key; press tab to type it", msg.String())
+			return nil
+		}
+
+	default:
+		seq, ok := csiSequence(msg)
+		if !ok {
+			return nil
+		}
+		if strings.HasPrefix(seq, "\x1b[?") && strings.HasSuffix(seq, "u") {
+			c.kitty = true
+			return nil
+		}
+		ev, ok := parseKittyKey(seq)
+		if !ok {
+			c.err = "can't name that key as an xkb key; press tab to type it"
+			return nil
+		}
+		if ev.release || ev.repeat || ev.modifierOnly {
+			return nil
+		}
+		if len(ev.chord.Modifiers) == 0 {
+			switch ev.chord.Key {
+			case "Escape", "Tab":
+				return c.finish(false)
+			case "Return":
+				if c.chord != nil {
+					return c.finish(true)
+				}
+			}
+		}
+		chord = ev.chord
+	}
+
+	chord.Modifiers = orderModifiers(chord.Modifiers, c.modKey)
+	c.chord = &chord
+	c.err = ""
+	return nil
+}
+
+// finish closes the dialog, keeping the chord if accepted
+func (c *chordCapture) finish(accepted bool) tea.Cmd {
+	c.done = true
+	c.accepted = accepted
+	return writeTerminal(kittyPop)
+}
+
+// view renders the dialog
+func (c *chordCapture) view() string {
+	var b strings.Builder
+	b.WriteString(styles.CardTitleStyle.Render("Record Keybind"))
+	b.WriteString("\n\n")
+
+	if c.chord == nil {
+		b.WriteString(styles.ValueStyle.Render("Press the key combination to bind..."))
+	} else {
+		b.WriteString(styles.LabelStyle.Render("Captured"))
+		b.WriteString(styles.SuccessStyle.Bold(true).Render(c.chord.String()))
+	}
+	b.WriteString("\n\n")
+
+	if c.err != "" {
+		b.WriteString(styles.ErrorStyle.Render(c.err))
+		b.WriteString("\n\n")
+	}
+
+	if c.kitty {
+		b.WriteString(styles.DimmedStyle.Render("Kitty keyboard protocol on: Super is detected."))
+	} else {
+		b.WriteString(styles.DimmedStyle.Render("This terminal can't report Super; type those chords instead."))
+	}
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("Chords niri already binds never reach the terminal."))
+	b.WriteString("\n\n")
+
+	if c.chord == nil {
+		b.WriteString(styles.DimmedStyle.Render("tab type instead • esc cancel"))
+	} else {
+		b.WriteString(styles.DimmedStyle.Render("enter accept • any key to retry • tab type instead • esc cancel"))
+	}
+	return b.String()
+}
+
+// orderModifiers writes modifiers in niri's usual order, spelling the
+// mod key as Mod
+func orderModifiers(mods []string, modKey string) []string {
+	var out []string
+	for _, name := range config.Modifiers() {
+		for _, m := range mods {
+			if m == modKey && name == "Mod" || m == name && m != modKey {
+				if !slices.Contains(out, name) {
+					out = append(out, name)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// legacyKeys maps bubbletea key names to xkb keysym names
+var legacyKeys = map[string]string{
+	"up": "Up", "down": "Down", "left": "Left", "right": "Right",
+	"home": "Home", "end": "End", "pgup": "Page_Up", "pgdown": "Page_Down",
+	"insert": "Insert", "delete": "Delete", "backspace": "BackSpace",
+	"enter": "Return", "tab": "Tab", "esc": "Escape", " ": "space", "space": "space",
+}
+
+// punctuationKeys maps printable ASCII punctuation to xkb keysym names.
+// Shifted symbols map to Shift plus the unshifted key of a US layout,
+// which is how niri sees them.
+var punctuationKeys = map[rune][2]string{
+	'-': {"", "Minus"}, '=': {"", "Equal"}, '[': {"", "BracketLeft"}, ']': {"", "BracketRight"},
+	'\\': {"", "Backslash"}, ';': {"", "Semicolon"}, '\'': {"", "Apostrophe"}, '`': {"", "Grave"},
+	',': {"", "Comma"}, '.': {"", "Period"}, '/': {"", "Slash"},
+	'_': {"Shift", "Minus"}, '+': {"Shift", "Equal"}, '{': {"Shift", "BracketLeft"}, '}': {"Shift", "BracketRight"},
+	'|': {"Shift", "Backslash"}, ':': {"Shift", "Semicolon"}, '"': {"Shift", "Apostrophe"}, '~': {"Shift", "Grave"},
+	'<': {"Shift", "Comma"}, '>': {"Shift", "Period"}, '?': {"Shift", "Slash"},
+	'!': {"Shift", "1"}, '@': {"Shift", "2"}, '#': {"Shift", "3"}, '$': {"Shift", "4"}, '%': {"Shift", "5"},
+	'^': {"Shift", "6"}, '&': {"Shift", "7"}, '*': {"Shift", "8"}, '(': {"Shift", "9"}, ')': {"Shift", "0"},
+}
+
+// chordFromKey converts a key decoded by bubbletea into a chord. Without
+// the kitty protocol only Ctrl, Alt and Shift can be seen.
+func chordFromKey(msg tea.KeyMsg) (config.KeyChord, bool) {
+	var mods []string
+	if msg.Alt {
+		mods = append(mods, "Alt")
+	}
+
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		if len(msg.Runes) != 1 {
+			return config.KeyChord{}, false
+		}
+		extra, key, ok := runeKey(msg.Runes[0])
+		if !ok {
+			return config.KeyChord{}, false
+		}
+		return config.KeyChord{Modifiers: append(mods, extra...), Key: key}, true
+	}
+
+	parts := strings.Split(strings.TrimPrefix(msg.String(), "alt+"), "+")
+	name := parts[len(parts)-1]
+	for _, m := range parts[:len(parts)-1] {
+		switch m {
+		case "ctrl":
+			mods = append(mods, "Ctrl")
+		case "shift":
+			mods = append(mods, "Shift")
+		default:
+			return config.KeyChord{}, false
+		}
+	}
+
+	if key, ok := legacyKeys[name]; ok {
+		return config.KeyChord{Modifiers: mods, Key: key}, true
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(name, "f")); err == nil && strings.HasPrefix(name, "f") {
+		return config.KeyChord{Modifiers: mods, Key: fmt.Sprintf("F%d", n)}, true
+	}
+	if r := []rune(name); len(r) == 1 {
+		if extra, key, ok := runeKey(r[0]); ok {
+			return config.KeyChord{Modifiers: append(mods, extra...), Key: key}, true
+		}
+	}
+	return config.KeyChord{}, false
+}
+
+// runeKey names the key that types r, with any extra modifier needed
+func runeKey(r rune) ([]string, string, bool) {
+	switch {
+	case r == ' ':
+		return nil, "space", true
+	case r >= 'a' && r <= 'z':
+		return nil, string(unicode.ToUpper(r)), true
+	case r >= 'A' && r <= 'Z':
+		return []string{"Shift"}, string(r), true
+	case r >= '0' && r <= '9':
+		return nil, string(r), true
+	}
+	if p, ok := punctuationKeys[r]; ok {
+		if p[0] != "" {
+			return []string{p[0]}, p[1], true
+		}
+		return nil, p[1], true
+	}
+	return nil, "", false
+}
+
+// kittyKey is a key event reported with the kitty keyboard protocol
+type kittyKey struct {
+	chord        config.KeyChord
+	repeat       bool
+	release      bool
+	modifierOnly bool
+}
+
+// kittyKeyRe matches CSI code[:alternates] ; mods[:event] [;text] final
+var kittyKeyRe = regexp.MustCompile(`^\x1b\[(\d*)(?::[\d:]*)?(?:;(\d*)(?::(\d+))?)?(?:;[\d:]*)?([u~ABCDEFHPQRS])$`)
+
+// kittyCSIKeys names the keys sent as CSI number ~
+var kittyCSIKeys = map[int]string{
+	2: "Insert", 3: "Delete", 5: "Page_Up", 6: "Page_Down", 7: "Home", 8: "End",
+	11: "F1", 12: "F2", 13: "F3", 14: "F4", 15: "F5", 17: "F6", 18: "F7",
+	19: "F8", 20: "F9", 21: "F10", 23: "F11", 24: "F12",
+}
+
+// kittyLetterKeys names the keys sent as CSI 1 ; mods letter
+var kittyLetterKeys = map[string]string{
+	"A": "Up", "B": "Down", "C": "Right", "D": "Left", "E": "KP_Begin",
+	"F": "End", "H": "Home", "P": "F1", "Q": "F2", "R": "F3", "S": "F4",
+}
+
+// kittyFunctionalKeys names the private-use code points the kitty
+// protocol uses for keys without text
+var kittyFunctionalKeys = map[int]string{
+	13: "Return", 9: "Tab", 127: "BackSpace", 27: "Escape",
+	57358: "Caps_Lock", 57359: "Scroll_Lock", 57360: "Num_Lock",
+	57361: "Print", 57362: "Pause", 57363: "Menu",
+	57409: "KP_Decimal", 57410: "KP_Divide", 57411: "KP_Multiply",
+	57412: "KP_Subtract", 57413: "KP_Add", 57414: "KP_Enter", 57415: "KP_Equal",
+	57416: "KP_Separator", 57417: "KP_Left", 57418: "KP_Right", 57419: "KP_Up",
+	57420: "KP_Down", 57421: "KP_Page_Up", 57422: "KP_Page_Down", 57423: "KP_Home",
+	57424: "KP_End", 57425: "KP_Insert", 57426: "KP_Delete", 57427: "KP_Begin",
+	57428: "XF86AudioPlay", 57429: "XF86AudioPause", 57430: "XF86AudioPlay",
+	57431: "XF86AudioRewind", 57432: "XF86AudioStop", 57433: "XF86AudioForward",
+	57434: "XF86AudioRewind", 57435: "XF86AudioNext", 57436: "XF86AudioPrev",
+	57437: "XF86AudioRecord", 57438: "XF86AudioLowerVolume",
+	57439: "XF86AudioRaiseVolume", 57440: "XF86AudioMute",
+}
+
+// parseKittyKey decodes a kitty keyboard protocol key event
+func parseKittyKey(seq string) (kittyKey, bool) {
+	m := kittyKeyRe.FindStringSubmatch(seq)
+	if m == nil {
+		return kittyKey{}, false
+	}
+	code, _ := strconv.Atoi(m[1])
+	if m[1] == "" {
+		code = 1
+	}
+
+	var ev kittyKey
+	switch event := m[3]; event {
+	case "2":
+		ev.repeat = true
+	case "3":
+		ev.release = true
+	}
+
+	// Modifiers are sent as 1 + a bit mask
+	if mods, err := strconv.Atoi(m[2]); err == nil && mods > 1 {
+		bits := mods - 1
+		for _, mod := range []struct {
+			bit  int
+			name string
+		}{
+			{1, "Shift"}, {2, "Alt"}, {4, "Ctrl"}, {8, "Super"},
+			{16, "Super"}, // Hyper is Mod4 like Super in the default xkb setup
+			{32, "Alt"},   // and Meta is Mod1 like Alt
+		} {
+			if bits&mod.bit != 0 && !slices.Contains(ev.chord.Modifiers, mod.name) {
+				ev.chord.Modifiers = append(ev.chord.Modifiers, mod.name)
+			}
+		}
+	}
+
+	switch final := m[4]; final {
+	case "u":
+		switch {
+		case code >= 57441 && code <= 57454:
+			// Shift, Ctrl, Alt, Super, Hyper, Meta and ISO level keys
+			ev.modifierOnly = true
+			return ev, true
+		case code >= 57376 && code <= 57398:
+			ev.chord.Key = fmt.Sprintf("F%d", code-57376+13)
+		case code >= 57399 && code <= 57408:
+			ev.chord.Key = fmt.Sprintf("KP_%d", code-57399)
+		default:
+			if key, ok := kittyFunctionalKeys[code]; ok {
+				ev.chord.Key = key
+				break
+			}
+			extra, key, ok := runeKey(rune(code))
+			if !ok {
+				return kittyKey{}, false
+			}
+			for _, mod := range extra {
+				if !slices.Contains(ev.chord.Modifiers, mod) {
+					ev.chord.Modifiers = append(ev.chord.Modifiers, mod)
+				}
+			}
+			ev.chord.Key = key
+		}
+	case "~":
+		key, ok := kittyCSIKeys[code]
+		if !ok {
+			return kittyKey{}, false
+		}
+		ev.chord.Key = key
+	default:
+		ev.chord.Key = kittyLetterKeys[final]
+	}
+	return ev, true
+}
@@ -0,0 +1,29 @@
+package screens
+
+import (
+	"reflect"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// csiSequence extracts the raw bytes of an escape sequence bubbletea
+// didn't recognize, such as a kitty keyboard protocol report.
+//
+// bubbletea v1.3.10 delivers these as its unexported
+// unknownCSISequenceMsg, a []byte, so the type is matched by name. This
+// is the only place that depends on it; csi_test.go fails if an upgrade
+// renames or changes the type.
+func csiSequence(msg tea.Msg) (string, bool) {
+	if msg == nil {
+		return "", false
+	}
+	v := reflect.ValueOf(msg)
+	t := v.Type()
+	if t.Name() != "unknownCSISequenceMsg" || t.PkgPath() != reflect.TypeOf(tea.KeyMsg{}).PkgPath() {
+		return "", false
+	}
+	if v.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+		return "", false
+	}
+	return string(v.Bytes()), true
+}
@@ -0,0 +1,54 @@
+package screens
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// csiRecorder quits on the first message csiSequence recognizes
+type csiRecorder struct {
+	seq string
+}
+
+func (m *csiRecorder) Init() tea.Cmd { return nil }
+
+func (m *csiRecorder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if seq, ok := csiSequence(msg); ok {
+		m.seq = seq
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *csiRecorder) View() string { return "" }
+
+func TestCSISequenceFromProgram(t *testing.T) {
+	// Super+A as the kitty keyboard protocol reports it
+	const report = "\x1b[97;9u"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	m := &csiRecorder{}
+	p := tea.NewProgram(m,
+		tea.WithInput(strings.NewReader(report)),
+		tea.WithOutput(io.Discard),
+		tea.WithContext(ctx),
+		tea.WithoutSignalHandler(),
+	)
+	if _, err := p.Run(); err != nil {
+		t.Fatalf("bubbletea no longer hands over unknown CSI sequences: %v", err)
+	}
+	if m.seq != report {
+		t.Fatalf("csiSequence got %q, want %q", m.seq, report)
+	}
+
+	ev, ok := parseKittyKey(m.seq)
+	if !ok || ev.chord.String() != "Super+A" {
+		t.Fatalf("parseKittyKey(%q) = %+v, %v", m.seq, ev, ok)
+	}
+}
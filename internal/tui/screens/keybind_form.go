@@ -0,0 +1,425 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// Form fields, in display order
+const (
+	formChord = iota
+	formAction
+	formArgs
+	formAllowWhenLocked
+	formAllowInhibiting
+	formRepeat
+	formCooldown
+	formTitle
+	formFieldCount
+)
+
+// bindForm edits a single bind
+type bindForm struct {
+	bind    *config.Bind // bind being edited, nil when adding
+	inputs  map[int]*textinput.Model
+	toggles map[int]bool
+	cursor  int
+	err     error
+
+	modKey  string        // key Mod stands for, captured chords use Mod for it
+	capture *chordCapture // open capture dialog, if any
+	picker  *appPicker    // open app picker, if any
+}
+
+// formLabels are the labels shown next to each field
+var formLabels = [formFieldCount]string{
+	formChord:           "Keybind",
+	formAction:          "Action",
+	formArgs:            "Arguments",
+	formAllowWhenLocked: "Allow When Locked",
+	formAllowInhibiting: "Allow Inhibiting",
+	formRepeat:          "Repeat",
+	formCooldown:        "Cooldown",
+	formTitle:           "Overlay Title",
+}
+
+// newBindForm creates a form filled from b, or a blank one if b is nil
+func newBindForm(b *config.Bind, modKey string) *bindForm {
+	f := &bindForm{
+		bind:    b,
+		inputs:  map[int]*textinput.Model{},
+		toggles: map[int]bool{},
+		modKey:  modKey,
+	}
+
+	newInput := func(field, width int, placeholder string) {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Placeholder = placeholder
+		input.Width = width
+		f.inputs[field] = &input
+	}
+	newInput(formChord, 40, "Mod+Shift+T")
+	newInput(formAction, 40, "spawn")
+	newInput(formArgs, 40, `"alacritty" "-e" "btop"`)
+	newInput(formCooldown, 6, "0")
+	newInput(formTitle, 40, "null hides the bind")
+
+	if b == nil {
+		b = config.NewBind(config.KeyChord{}, "")
+	}
+	f.inputs[formChord].SetValue(b.Chord.String())
+	f.inputs[formAction].SetValue(b.Action)
+	f.inputs[formArgs].SetValue(b.ArgsString())
+	if b.CooldownMs > 0 {
+		f.inputs[formCooldown].SetValue(strconv.Itoa(b.CooldownMs))
+	}
+	if b.HotkeyOverlayHidden {
+		f.inputs[formTitle].SetValue("null")
+	} else {
+		f.inputs[formTitle].SetValue(b.HotkeyOverlayTitle)
+	}
+	f.toggles[formAllowWhenLocked] = b.AllowWhenLocked
+	f.toggles[formAllowInhibiting] = b.AllowInhibiting
+	f.toggles[formRepeat] = b.Repeat
+
+	f.inputs[formChord].ShowSuggestions = true
+	f.refreshChordSuggestions()
+	f.inputs[formAction].ShowSuggestions = true
+	f.inputs[formAction].SetSuggestions(config.ActionCompletions(""))
+	return f
+}
+
+// startCapture opens the dialog recording the chord from a key press
+func (f *bindForm) startCapture() tea.Cmd {
+	f.cursor = formChord
+	var cmd tea.Cmd
+	f.capture, cmd = newChordCapture(f.modKey)
+	return tea.Batch(cmd, f.focus())
+}
+
+// refreshChordSuggestions offers modifier and keysym names to complete
+// the last part of the chord being typed
+func (f *bindForm) refreshChordSuggestions() {
+	input := f.inputs[formChord]
+	value := input.Value()
+	prefix, part := "", value
+	if i := strings.LastIndex(value, "+"); i >= 0 {
+		prefix, part = value[:i+1], value[i+1:]
+	}
+	if part == "" {
+		input.SetSuggestions(nil)
+		return
+	}
+
+	var suggestions []string
+	for _, m := range config.Modifiers() {
+		if strings.HasPrefix(strings.ToLower(m), strings.ToLower(part)) {
+			suggestions = append(suggestions, prefix+m)
+		}
+	}
+	for _, name := range config.KeysymCompletions(part) {
+		suggestions = append(suggestions, prefix+name)
+		if len(suggestions) >= maxSuggestions {
+			break
+		}
+	}
+	input.SetSuggestions(suggestions)
+}
+
+// maxSuggestions caps the completions offered for a chord
+const maxSuggestions = 50
+
+// focus focuses the field under the cursor
+func (f *bindForm) focus() tea.Cmd {
+	for field, input := range f.inputs {
+		if field != f.cursor {
+			input.Blur()
+		}
+	}
+	if input, ok := f.inputs[f.cursor]; ok {
+		return input.Focus()
+	}
+	return nil
+}
+
+// update handles a message for the field under the cursor
+func (f *bindForm) update(msg tea.Msg) tea.Cmd {
+	if f.capture != nil {
+		cmd := f.capture.update(msg)
+		if !f.capture.done {
+			return cmd
+		}
+		if f.capture.accepted {
+			f.inputs[formChord].SetValue(f.capture.chord.String())
+			f.inputs[formChord].CursorEnd()
+			f.refreshChordSuggestions()
+		}
+		f.capture = nil
+		return tea.Batch(cmd, f.focus())
+	}
+	if f.picker != nil {
+		cmd := f.picker.update(msg)
+		if !f.picker.done {
+			return cmd
+		}
+		if f.picker.accepted {
+			f.inputs[formAction].SetValue("spawn")
+			f.inputs[formArgs].SetValue(f.picker.command())
+			f.inputs[formArgs].CursorEnd()
+			f.cursor = formArgs
+		}
+		f.picker = nil
+		return tea.Batch(cmd, f.focus())
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if f.cursor == formChord && msg.String() == "ctrl+r" {
+			return f.startCapture()
+		}
+		if msg.String() == "ctrl+o" {
+			var cmd tea.Cmd
+			f.picker, cmd = newAppPicker()
+			return cmd
+		}
+		if (f.cursor == formChord || f.cursor == formAction) && msg.String() == "tab" {
+			// Complete the chord or action before moving on
+			input := f.inputs[f.cursor]
+			if s := input.CurrentSuggestion(); s != "" && s != input.Value() {
+				input.SetValue(s)
+				input.CursorEnd()
+				if f.cursor == formChord {
+					f.refreshChordSuggestions()
+				}
+				return nil
+			}
+		}
+
+		switch msg.String() {
+		case "up", "shift+tab":
+			f.cursor = (f.cursor + formFieldCount - 1) % formFieldCount
+			return f.focus()
+		case "down", "tab":
+			f.cursor = (f.cursor + 1) % formFieldCount
+			return f.focus()
+		case " ":
+			if _, ok := f.toggles[f.cursor]; ok {
+				f.toggles[f.cursor] = !f.toggles[f.cursor]
+				return nil
+			}
+		}
+	}
+
+	input, ok := f.inputs[f.cursor]
+	if !ok {
+		return nil
+	}
+	var cmd tea.Cmd
+	*input, cmd = input.Update(msg)
+	if f.cursor == formChord {
+		f.refreshChordSuggestions()
+	}
+	return cmd
+}
+
+// submit validates the form and writes it to the bind, creating one if
+// the form is adding
+func (f *bindForm) submit() (*config.Bind, error) {
+	chord := config.ParseKeyChord(strings.TrimSpace(f.inputs[formChord].Value()))
+	if err := chord.Validate(); err != nil {
+		return nil, err
+	}
+
+	action := strings.TrimSpace(f.inputs[formAction].Value())
+	if action == "" || strings.ContainsAny(action, " \t\"{};=") {
+		return nil, fmt.Errorf("invalid action %q", action)
+	}
+	spec, ok := config.LookupAction(action)
+	if !ok {
+		return nil, fmt.Errorf("unknown action %q", action)
+	}
+
+	args, props, err := config.ParseArgs(f.inputs[formArgs].Value())
+	if err != nil {
+		return nil, err
+	}
+	if err := spec.CheckArgs(args, props); err != nil {
+		return nil, err
+	}
+
+	cooldown := 0
+	if s := strings.TrimSpace(f.inputs[formCooldown].Value()); s != "" {
+		cooldown, err = strconv.Atoi(s)
+		if err != nil || cooldown < 0 {
+			return nil, fmt.Errorf("cooldown must be a number of milliseconds")
+		}
+	}
+
+	b := f.bind
+	if b == nil {
+		b = config.NewBind(chord, action)
+	}
+	b.Chord = chord
+	b.Action = action
+	b.Args = args
+	b.ActionProps = props
+	b.AllowWhenLocked = f.toggles[formAllowWhenLocked]
+	b.AllowInhibiting = f.toggles[formAllowInhibiting]
+	b.Repeat = f.toggles[formRepeat]
+	b.CooldownMs = cooldown
+
+	title := strings.TrimSpace(f.inputs[formTitle].Value())
+	b.HotkeyOverlayHidden = title == "null"
+	if b.HotkeyOverlayHidden {
+		title = ""
+	}
+	b.HotkeyOverlayTitle = title
+	return b, nil
+}
+
+// view renders the form
+func (f *bindForm) view() string {
+	if f.capture != nil {
+		return f.capture.view()
+	}
+	if f.picker != nil {
+		return f.picker.view()
+	}
+
+	var b strings.Builder
+
+	title := "Edit Keybind"
+	if f.bind == nil {
+		title = "Add Keybind"
+	}
+	b.WriteString(styles.CardTitleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	for field := 0; field < formFieldCount; field++ {
+		selected := field == f.cursor
+
+		cursor := "  "
+		labelStyle := styles.LabelStyle
+		if selected {
+			cursor = styles.SuccessStyle.Render(styles.SymbolArrow + " ")
+			labelStyle = labelStyle.Foreground(styles.ColorGreen)
+		}
+		label := labelStyle.Width(20).Render(formLabels[field])
+
+		var value string
+		if input, ok := f.inputs[field]; ok {
+			value = input.View()
+			if field == formCooldown {
+				value += " ms"
+			}
+		} else {
+			value = renderFormToggle(f.toggles[field], selected)
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, label, value)
+
+		if field == formChord && selected {
+			b.WriteString(f.chordHint())
+		}
+		if (field == formAction || field == formArgs) && selected {
+			b.WriteString(f.actionHint(field))
+		}
+	}
+
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("↑↓/tab move • ctrl+r record chord • ctrl+o pick app • space toggle • enter apply • esc cancel"))
+	return b.String()
+}
+
+// chordHint renders the line under the chord field: what is wrong with
+// the chord typed so far, or the names that complete it
+func (f *bindForm) chordHint() string {
+	input := f.inputs[formChord]
+	value := strings.TrimSpace(input.Value())
+	if value == "" {
+		return styles.DimmedStyle.Render(hintIndent+"ctrl+r to record a key press") + "\n"
+	}
+
+	for _, m := range config.ParseKeyChord(value).Modifiers {
+		if !config.IsModifier(m) {
+			return styles.ErrorStyle.Render(fmt.Sprintf("%sunknown modifier %q", hintIndent, m)) + "\n"
+		}
+	}
+
+	if matches := input.MatchedSuggestions(); len(matches) > 1 || len(matches) == 1 && matches[0] != value {
+		names := make([]string, 0, 6)
+		for _, m := range matches[:min(len(matches), 6)] {
+			names = append(names, m[strings.LastIndex(m, "+")+1:])
+		}
+		return styles.DimmedStyle.Render(hintIndent+strings.Join(names, "  ")+"  (tab completes)") + "\n"
+	}
+
+	if err := config.ParseKeyChord(value).Validate(); err != nil {
+		return styles.ErrorStyle.Render(hintIndent+err.Error()) + "\n"
+	}
+	return ""
+}
+
+// actionHint renders the line under the action and arguments fields:
+// the actions matching what was typed, or how the action is used and
+// what is wrong with its arguments
+func (f *bindForm) actionHint(field int) string {
+	input := f.inputs[formAction]
+	name := strings.TrimSpace(input.Value())
+	if name == "" {
+		return ""
+	}
+
+	if matches := input.MatchedSuggestions(); field == formAction && (len(matches) > 1 || len(matches) == 1 && matches[0] != name) {
+		shown := matches[:min(len(matches), 4)]
+		return styles.DimmedStyle.Render(hintIndent+strings.Join(shown, "  ")+"  (tab completes)") + "\n"
+	}
+
+	action, ok := config.LookupAction(name)
+	if !ok {
+		return styles.ErrorStyle.Render(fmt.Sprintf("%sunknown action %q", hintIndent, name)) + "\n"
+	}
+
+	hint := styles.DimmedStyle.Render(fmt.Sprintf("%s%s (niri %s+)", hintIndent, action.Usage(), action.Since)) + "\n" +
+		styles.DimmedStyle.Render(hintIndent+action.Description) + "\n"
+	if field == formArgs {
+		args, props, err := config.ParseArgs(f.inputs[formArgs].Value())
+		if err == nil {
+			err = action.CheckArgs(args, props)
+		}
+		if err != nil {
+			hint += styles.ErrorStyle.Render(hintIndent+err.Error()) + "\n"
+		}
+	}
+	return hint
+}
+
+// hintIndent lines hints up with the field values
+const hintIndent = "                       "
+
+// renderFormToggle renders a yes/no field
+func renderFormToggle(on, selected bool) string {
+	if on {
+		style := styles.ToggleOnStyle
+		if selected {
+			style = style.Bold(true)
+		}
+		return style.Render("[✓] Yes")
+	}
+	style := styles.ToggleOffStyle
+	if selected {
+		style = style.Foreground(styles.ColorComment)
+	}
+	return style.Render("[ ] No")
+}
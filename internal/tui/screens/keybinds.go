@@ -0,0 +1,581 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/cheatsheet"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+	"github.com/sahilm/fuzzy"
+)
+
+// KeybindsKeys are the app-wide bindings the keybinds screen uses
+type KeybindsKeys struct {
+	Filter key.Binding
+	Add    key.Binding
+	Edit   key.Binding
+	Delete key.Binding
+	Export key.Binding
+}
+
+// KeybindsModel is the model for the keybinds screen
+type KeybindsModel struct {
+	keys    KeybindsKeys
+	config  *config.NiriConfig
+	visible []*config.Bind // binds matching the filter, in display order
+	// problems found in the binds, refreshed whenever they change
+	problems []config.BindProblem
+	cursor   int
+	offset   int // first visible row
+	width    int
+	height   int
+	dirty    bool
+	err      error
+	message  string
+
+	filter    textinput.Model
+	filtering bool
+	form      *bindForm // open edit form, nil when browsing
+
+	exportPath textinput.Model // cheat sheet file, .md or .html
+	exporting  bool
+}
+
+// keybindsSavedMsg is sent when the keybinds screen saved the config
+type keybindsSavedMsg struct {
+	err error
+}
+
+// keybindsExportedMsg is sent when the cheat sheet has been written
+type keybindsExportedMsg struct {
+	path string
+	err  error
+}
+
+// chordColumnWidth is the width of the keybind column in the table
+const chordColumnWidth = 26
+
+// NewKeybindsModel creates a new keybinds model
+func NewKeybindsModel(keys KeybindsKeys) *KeybindsModel {
+	filter := textinput.New()
+	filter.Prompt = ""
+	filter.Placeholder = "type to filter"
+	filter.Width = 24
+
+	exportPath := textinput.New()
+	exportPath.Prompt = ""
+	exportPath.Width = 40
+	exportPath.SetValue("~/niri-keybinds.html")
+
+	return &KeybindsModel{
+		keys:       keys,
+		filter:     filter,
+		exportPath: exportPath,
+	}
+}
+
+// Init initializes the model. The config is shared with the settings
+// screen and arrives with the configLoadedMsg it requests.
+func (m *KeybindsModel) Init() tea.Cmd {
+	return nil
+}
+
+// SetSize sets the dimensions
+func (m *KeybindsModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clampScroll()
+}
+
+// Capturing reports whether the screen is taking text input, in which
+// case the app must not treat keys as global shortcuts
+func (m *KeybindsModel) Capturing() bool {
+	return m.filtering || m.exporting || m.form != nil
+}
+
+// Update handles messages
+func (m *KeybindsModel) Update(msg tea.Msg) (*KeybindsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case configLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.config = msg.config
+		m.err = nil
+		m.dirty = false
+		m.form = nil
+		m.checkBinds()
+		return m, nil
+
+	case configSavedMsg, startupSavedMsg, animationsSavedMsg, outputsSavedMsg, windowRuleSavedMsg:
+		// Saving from another screen writes the binds too
+		if savedErr(msg) == nil {
+			m.dirty = false
+		}
+		return m, nil
+
+	case keybindsSavedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error saving: %v", msg.err)
+		} else {
+			m.message = "Keybinds saved!"
+			m.dirty = false
+		}
+		return m, nil
+
+	case keybindsExportedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error exporting: %v", msg.err)
+		} else {
+			m.message = "Cheat sheet written to " + msg.path
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case m.form != nil:
+			return m, m.updateForm(msg)
+		case m.filtering:
+			return m, m.updateFilter(msg)
+		case m.exporting:
+			return m, m.updateExport(msg)
+		}
+		return m, m.handleKey(msg)
+	}
+
+	// Let the focused text input blink
+	var cmd tea.Cmd
+	switch {
+	case m.form != nil:
+		cmd = m.form.update(msg)
+	case m.filtering:
+		m.filter, cmd = m.filter.Update(msg)
+	case m.exporting:
+		m.exportPath, cmd = m.exportPath.Update(msg)
+	}
+	return m, cmd
+}
+
+// handleKey handles keys while browsing the table
+func (m *KeybindsModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, keyUp):
+		m.moveCursor(-1)
+	case key.Matches(msg, keyDown):
+		m.moveCursor(1)
+	case msg.String() == "pgup":
+		m.moveCursor(-m.tableHeight())
+	case msg.String() == "pgdown":
+		m.moveCursor(m.tableHeight())
+	case key.Matches(msg, m.keys.Filter):
+		m.filtering = true
+		m.message = ""
+		return m.filter.Focus()
+	case key.Matches(msg, m.keys.Add):
+		if m.config == nil {
+			return nil
+		}
+		m.form = newBindForm(nil, m.config.ModKey)
+		return m.form.startCapture()
+	case key.Matches(msg, m.keys.Edit), msg.String() == "enter":
+		if b := m.selected(); b != nil {
+			m.form = newBindForm(b, m.config.ModKey)
+			return m.form.focus()
+		}
+	case key.Matches(msg, m.keys.Delete):
+		if b := m.selected(); b != nil {
+			m.config.RemoveBind(b)
+			m.dirty = true
+			m.message = "Removed " + b.Chord.String()
+			m.checkBinds()
+		}
+	case key.Matches(msg, keySave):
+		return m.saveConfig()
+	case key.Matches(msg, m.keys.Export):
+		if m.config == nil {
+			return nil
+		}
+		m.exporting = true
+		m.message = ""
+		m.exportPath.CursorEnd()
+		return m.exportPath.Focus()
+	}
+	return nil
+}
+
+// updateExport handles keys while typing the cheat sheet path
+func (m *KeybindsModel) updateExport(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.exporting = false
+		m.exportPath.Blur()
+		return nil
+	case "enter":
+		m.exporting = false
+		m.exportPath.Blur()
+		return m.exportCheatSheet(strings.TrimSpace(m.exportPath.Value()))
+	}
+
+	var cmd tea.Cmd
+	m.exportPath, cmd = m.exportPath.Update(msg)
+	return cmd
+}
+
+// updateFilter handles keys while typing in the filter
+func (m *KeybindsModel) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.filter.SetValue("")
+		fallthrough
+	case "enter":
+		m.filtering = false
+		m.filter.Blur()
+		m.applyFilter()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.applyFilter()
+	return cmd
+}
+
+// updateForm handles keys while the edit form is open
+func (m *KeybindsModel) updateForm(msg tea.KeyMsg) tea.Cmd {
+	if m.form.capture != nil || m.form.picker != nil {
+		return m.form.update(msg)
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.form = nil
+		return nil
+	case "enter":
+		b, err := m.form.submit()
+		if err != nil {
+			m.form.err = err
+			return nil
+		}
+		if m.form.bind == nil {
+			m.config.AddBind(b)
+			m.message = "Added " + b.Chord.String()
+		} else {
+			m.message = "Updated " + b.Chord.String()
+		}
+		m.form = nil
+		m.dirty = true
+		m.checkBinds()
+		m.selectBind(b)
+		return nil
+	}
+	return m.form.update(msg)
+}
+
+// checkBinds looks for conflicts and mistakes in the binds, then
+// refreshes the table
+func (m *KeybindsModel) checkBinds() {
+	m.problems = nil
+	if m.config != nil {
+		m.problems = m.config.CheckBinds()
+	}
+	m.applyFilter()
+}
+
+// applyFilter recomputes the visible rows, fuzzy matching the filter
+// against each bind's chord and action
+func (m *KeybindsModel) applyFilter() {
+	m.visible = nil
+	if m.config == nil {
+		return
+	}
+
+	pattern := strings.TrimSpace(m.filter.Value())
+	if pattern == "" {
+		m.visible = append(m.visible, m.config.Binds...)
+	} else {
+		targets := make([]string, len(m.config.Binds))
+		for i, b := range m.config.Binds {
+			targets[i] = b.Chord.String() + " " + b.ActionString()
+		}
+		for _, match := range fuzzy.Find(pattern, targets) {
+			m.visible = append(m.visible, m.config.Binds[match.Index])
+		}
+	}
+	m.clampScroll()
+}
+
+// selected returns the bind under the cursor
+func (m *KeybindsModel) selected() *config.Bind {
+	if m.cursor < 0 || m.cursor >= len(m.visible) {
+		return nil
+	}
+	return m.visible[m.cursor]
+}
+
+// selectBind moves the cursor to a bind if it is visible
+func (m *KeybindsModel) selectBind(b *config.Bind) {
+	for i, v := range m.visible {
+		if v == b {
+			m.cursor = i
+			m.clampScroll()
+			return
+		}
+	}
+}
+
+// moveCursor moves the cursor by delta rows
+func (m *KeybindsModel) moveCursor(delta int) {
+	m.cursor += delta
+	m.clampScroll()
+}
+
+// clampScroll keeps the cursor in range and on screen
+func (m *KeybindsModel) clampScroll() {
+	m.cursor = max(0, min(m.cursor, len(m.visible)-1))
+	rows := m.tableHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.visible)-rows))
+}
+
+// tableHeight is the number of table rows that fit on screen
+func (m *KeybindsModel) tableHeight() int {
+	// Title, filter, table header, problems and footer lines
+	return max(1, m.height-14)
+}
+
+// View renders the keybinds screen
+func (m *KeybindsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(styles.TitleStyle.Render("Keybinds"))
+	b.WriteString("\n")
+	b.WriteString(styles.SectionStyle.Render("─────────────────────────────────────────"))
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	if m.form != nil {
+		b.WriteString(m.form.view())
+		return b.String()
+	}
+
+	if m.message != "" {
+		b.WriteString(styles.SuccessStyle.Render(m.message))
+		b.WriteString("\n\n")
+	}
+
+	if m.exporting {
+		b.WriteString(styles.LabelStyle.UnsetWidth().Render("Export to: "))
+		b.WriteString(m.exportPath.View())
+		b.WriteString("\n")
+		b.WriteString(styles.DimmedStyle.Render(".md for Markdown, .html for a page in the nirimatic colors • enter write • esc cancel"))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.renderFilterLine())
+	b.WriteString("\n\n")
+	b.WriteString(m.renderTable())
+	b.WriteString(m.renderProblems())
+
+	if m.dirty {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("* Unsaved changes"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(styles.DimmedStyle.Render("/ filter • enter edit • a add • d delete • s save • x export"))
+
+	return b.String()
+}
+
+// renderFilterLine renders the filter input and match count
+func (m *KeybindsModel) renderFilterLine() string {
+	label := styles.LabelStyle.UnsetWidth().Render("Filter: ")
+	var input string
+	switch {
+	case m.filtering:
+		input = m.filter.View()
+	case m.filter.Value() != "":
+		input = styles.ValueStyle.Render(m.filter.Value())
+	default:
+		input = styles.DimmedStyle.Render("press / to filter")
+	}
+
+	count := 0
+	if m.config != nil {
+		count = len(m.config.Binds)
+	}
+	line := fmt.Sprintf("%s%s  %s", label, input,
+		styles.DimmedStyle.Render(fmt.Sprintf("%d/%d", len(m.visible), count)))
+
+	var errors, warnings int
+	for _, p := range m.problems {
+		if p.Severity == config.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	if errors > 0 {
+		line += "  " + styles.ErrorStyle.Render(plural(errors, "error"))
+	}
+	if warnings > 0 {
+		line += "  " + styles.WarningStyle.Render(plural(warnings, "warning"))
+	}
+	return line
+}
+
+// renderTable renders the visible slice of the chord → action table
+func (m *KeybindsModel) renderTable() string {
+	if m.config == nil {
+		return styles.DimmedStyle.Render("Loading config...")
+	}
+	if len(m.visible) == 0 {
+		return styles.DimmedStyle.Render("No keybinds match")
+	}
+
+	actionWidth := max(10, m.width-chordColumnWidth-10)
+	chordStyle := lipgloss.NewStyle().Width(chordColumnWidth)
+	actionStyle := lipgloss.NewStyle().Width(actionWidth)
+
+	headerStyle := lipgloss.NewStyle().Foreground(styles.ColorPink).Bold(true)
+
+	var b strings.Builder
+	b.WriteString("    ")
+	b.WriteString(headerStyle.Inherit(chordStyle).Render("Keybind"))
+	b.WriteString(headerStyle.Inherit(actionStyle).Render("Action"))
+	b.WriteString("\n")
+
+	end := min(len(m.visible), m.offset+m.tableHeight())
+	for i := m.offset; i < end; i++ {
+		bind := m.visible[i]
+		chord := truncate(bind.Chord.String(), chordColumnWidth-1)
+		action := truncate(bind.ActionString(), actionWidth)
+		marker := m.problemMarker(bind)
+
+		if i == m.cursor {
+			b.WriteString(styles.SuccessStyle.Render(styles.SymbolArrow + " "))
+			b.WriteString(marker)
+			b.WriteString(chordStyle.Foreground(styles.ColorGreen).Bold(true).Render(chord))
+			b.WriteString(actionStyle.Foreground(styles.ColorForeground).Render(action))
+		} else {
+			b.WriteString("  ")
+			b.WriteString(marker)
+			b.WriteString(chordStyle.Foreground(styles.ColorCyan).Render(chord))
+			b.WriteString(actionStyle.Foreground(styles.ColorComment).Render(action))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(m.visible) > m.tableHeight() {
+		b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  %d-%d of %d", m.offset+1, end, len(m.visible))))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// problemMarker returns a dot colored by the worst problem of a bind,
+// or blank space if it has none
+func (m *KeybindsModel) problemMarker(b *config.Bind) string {
+	problems := config.ProblemsFor(m.problems, b)
+	if len(problems) == 0 {
+		return "  "
+	}
+	for _, p := range problems {
+		if p.Severity == config.SeverityError {
+			return styles.StatusError.Render(styles.SymbolError) + " "
+		}
+	}
+	return styles.StatusWarning.Render(styles.SymbolWarning) + " "
+}
+
+// renderProblems lists the problems of the selected bind
+func (m *KeybindsModel) renderProblems() string {
+	b := m.selected()
+	if b == nil {
+		return ""
+	}
+
+	var out strings.Builder
+	for _, p := range config.ProblemsFor(m.problems, b) {
+		style := styles.WarningStyle
+		if p.Severity == config.SeverityError {
+			style = styles.ErrorStyle
+		}
+		out.WriteString(style.Render(truncate(fmt.Sprintf("%s %s: %s", styles.SymbolWarning, p.Severity, p.Msg), m.width-4)))
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// saveConfig saves the config file
+func (m *KeybindsModel) saveConfig() tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = keybindsSavedMsg{err: fmt.Errorf("save failed: %v", r)}
+			}
+		}()
+
+		if m.config == nil {
+			return keybindsSavedMsg{err: fmt.Errorf("no config loaded")}
+		}
+		return keybindsSavedMsg{err: config.SaveNiriConfig(m.config)}
+	}
+}
+
+// exportCheatSheet writes the binds as a cheat sheet, in the format the
+// file extension asks for
+func (m *KeybindsModel) exportCheatSheet(path string) tea.Cmd {
+	sheet := cheatsheet.New(m.config)
+	return func() tea.Msg {
+		if path == "" {
+			return keybindsExportedMsg{err: fmt.Errorf("no file given")}
+		}
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return keybindsExportedMsg{err: err}
+			}
+			path = filepath.Join(home, rest)
+		}
+		out := sheet.Render(cheatsheet.FormatForPath(path))
+		return keybindsExportedMsg{path: path, err: os.WriteFile(path, []byte(out), 0o644)}
+	}
+}
+
+// plural formats a count with a noun, adding an s unless it is one
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// truncate shortens s to width cells, ending it with an ellipsis
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -25,14 +25,15 @@ type Field struct {
 
 // NiriSettingsModel is the model for the Niri settings screen
 type NiriSettingsModel struct {
-	config   *config.NiriConfig
-	fields   []Field
-	cursor   int
-	width    int
-	height   int
-	dirty    bool
-	err      error
-	message  string
+	configPath string
+	config     *config.NiriConfig
+	fields     []Field
+	cursor     int
+	width      int
+	height     int
+	dirty      bool
+	err        error
+	message    string
 }
 
 // configLoadedMsg is sent when config is loaded
@@ -47,8 +48,9 @@ type configSavedMsg struct {
 }
 
 // NewNiriSettingsModel creates a new Niri settings model
-func NewNiriSettingsModel() *NiriSettingsModel {
+func NewNiriSettingsModel(configPath string) *NiriSettingsModel {
 	return &NiriSettingsModel{
+		configPath: configPath,
 		fields: []Field{
 			// Layout section
 			{Label: "Gaps", Value: 10, Min: 0, Max: 50, Step: 1, Unit: "px"},
@@ -340,7 +342,7 @@ func (m *NiriSettingsModel) toggleValue() {
 // loadConfig loads the config file
 func (m *NiriSettingsModel) loadConfig() tea.Cmd {
 	return func() tea.Msg {
-		cfg, err := config.LoadNiriConfig(config.GetConfigPath())
+		cfg, err := config.LoadNiriConfig(m.configPath)
 		return configLoadedMsg{config: cfg, err: err}
 	}
 }
@@ -0,0 +1,272 @@
+package screens
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/outputlayout"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// Size of the arrangement canvas in terminal cells
+const (
+	canvasMaxWidth = 72
+	canvasHeight   = 12
+)
+
+// nudgeStep is how far shift+arrow moves an output, in logical pixels
+const nudgeStep = 10
+
+// outputArrangement is the enabled outputs laid out in logical space
+type outputArrangement struct {
+	outputs []*config.Output
+	rects   []outputlayout.Rect
+	known   []bool // whether each size comes from the config's mode and scale
+}
+
+// newOutputArrangement lays out the enabled outputs where niri would put
+// them
+func newOutputArrangement(outputs []*config.Output) *outputArrangement {
+	a := &outputArrangement{}
+	var placed []bool
+	for _, o := range outputs {
+		if o.Off {
+			continue
+		}
+		w, h, known := o.LogicalSize()
+		a.outputs = append(a.outputs, o)
+		a.rects = append(a.rects, outputlayout.Rect{Name: o.Name, X: o.X, Y: o.Y, W: w, H: h})
+		a.known = append(a.known, known)
+		placed = append(placed, o.Positioned)
+	}
+	outputlayout.Place(a.rects, placed)
+	return a
+}
+
+// apply writes the arranged positions to the outputs, reporting whether
+// any changed
+func (a *outputArrangement) apply() bool {
+	changed := false
+	for i, o := range a.outputs {
+		r := a.rects[i]
+		if !o.Positioned || o.X != r.X || o.Y != r.Y {
+			o.Positioned, o.X, o.Y = true, r.X, r.Y
+			changed = true
+		}
+	}
+	return changed
+}
+
+// startArranging opens the arrangement for moving outputs around
+func (m *OutputsModel) startArranging() {
+	a := newOutputArrangement(m.config.Outputs)
+	if len(a.outputs) == 0 {
+		m.message = "No enabled outputs to arrange"
+		return
+	}
+	m.arranging = a
+	m.arrangeCursor = 0
+	m.message = ""
+}
+
+// updateArrange handles keys while arranging outputs
+func (m *OutputsModel) updateArrange(msg tea.KeyMsg) tea.Cmd {
+	a, i := m.arranging, m.arrangeCursor
+	switch msg.String() {
+	case "esc":
+		m.arranging = nil
+	case "enter":
+		if a.apply() {
+			m.dirty = true
+			m.message = "Arranged outputs; press s to save"
+		}
+		m.arranging = nil
+	case "tab":
+		m.arrangeCursor = (i + 1) % len(a.rects)
+	case "shift+tab":
+		m.arrangeCursor = (i + len(a.rects) - 1) % len(a.rects)
+	case "left", "h":
+		a.rects[i] = outputlayout.Move(a.rects, i, -1, 0)
+	case "right", "l":
+		a.rects[i] = outputlayout.Move(a.rects, i, 1, 0)
+	case "up", "k":
+		a.rects[i] = outputlayout.Move(a.rects, i, 0, -1)
+	case "down", "j":
+		a.rects[i] = outputlayout.Move(a.rects, i, 0, 1)
+	case "shift+left", "H":
+		a.rects[i] = outputlayout.Nudge(a.rects, i, -nudgeStep, 0)
+	case "shift+right", "L":
+		a.rects[i] = outputlayout.Nudge(a.rects, i, nudgeStep, 0)
+	case "shift+up", "K":
+		a.rects[i] = outputlayout.Nudge(a.rects, i, 0, -nudgeStep)
+	case "shift+down", "J":
+		a.rects[i] = outputlayout.Nudge(a.rects, i, 0, nudgeStep)
+	}
+	return nil
+}
+
+// renderArrangement renders the canvas with the problems of the layout
+// below it, highlighting the selected output (-1 for none). While
+// arranging it also shows where the selected output is.
+func (m *OutputsModel) renderArrangement(a *outputArrangement, selected int, arranging bool) string {
+	var b strings.Builder
+	if len(a.rects) == 0 {
+		b.WriteString(styles.DimmedStyle.Render("No enabled outputs"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(renderOutputCanvas(a, selected, max(20, min(canvasMaxWidth, m.width-4)), canvasHeight))
+
+	if arranging {
+		r := a.rects[selected]
+		fmt.Fprintf(&b, "%s %s\n", styles.SuccessStyle.Render(r.Name),
+			styles.ValueStyle.Render(fmt.Sprintf("at %d,%d • %dx%d logical", r.X, r.Y, r.W, r.H)))
+	}
+	for i, known := range a.known {
+		if !known {
+			b.WriteString(styles.DimmedStyle.Render(assumedSizeNote(a.outputs[i])))
+			b.WriteString("\n")
+		}
+	}
+	for _, problem := range outputlayout.Check(a.rects) {
+		b.WriteString(styles.WarningStyle.Render(styles.SymbolWarning + " " + problem))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// assumedSizeNote says what an output's size on the canvas is guessed
+// from, since niri picks the mode and scale the config leaves out
+func assumedSizeNote(o *config.Output) string {
+	var guesses []string
+	if _, err := config.ParseOutputMode(o.Mode); err != nil {
+		guesses = append(guesses, "a 1920x1080 mode")
+	}
+	if o.Scale <= 0 {
+		guesses = append(guesses, "scale 1")
+	}
+	return fmt.Sprintf("%s is drawn assuming %s; niri may pick otherwise", o.Name, strings.Join(guesses, " and "))
+}
+
+// renderOutputCanvas draws every output as a labeled box, scaled to fit
+// cols by rows cells. Cells are about twice as tall as they are wide, so
+// a row covers twice the pixels of a column.
+func renderOutputCanvas(a *outputArrangement, selected, cols, rows int) string {
+	minX, minY := a.rects[0].X, a.rects[0].Y
+	maxX, maxY := a.rects[0].Right(), a.rects[0].Bottom()
+	for _, r := range a.rects[1:] {
+		minX, minY = min(minX, r.X), min(minY, r.Y)
+		maxX, maxY = max(maxX, r.Right()), max(maxY, r.Bottom())
+	}
+	perCol := max(float64(maxX-minX)/float64(cols), float64(maxY-minY)/float64(2*rows))
+	toCol := func(x int) int { return int(math.Round(float64(x-minX) / perCol)) }
+	toRow := func(y int) int { return int(math.Round(float64(y-minY) / (2 * perCol))) }
+
+	grid := make([][]rune, rows)
+	owner := make([][]int, rows)
+	for y := range grid {
+		grid[y] = []rune(strings.Repeat(" ", cols))
+		owner[y] = make([]int, cols)
+		for x := range owner[y] {
+			owner[y][x] = -1
+		}
+	}
+	set := func(x, y int, r rune, i int) {
+		if x >= 0 && y >= 0 && x < cols && y < rows {
+			grid[y][x], owner[y][x] = r, i
+		}
+	}
+
+	// Draw the selected output last so it is on top
+	order := make([]int, 0, len(a.rects))
+	for i := range a.rects {
+		if i != selected {
+			order = append(order, i)
+		}
+	}
+	if selected >= 0 {
+		order = append(order, selected)
+	}
+
+	for _, i := range order {
+		r := a.rects[i]
+		left, top := toCol(r.X), toRow(r.Y)
+		right := max(left+1, toCol(r.Right())-1)
+		bottom := max(top+1, toRow(r.Bottom())-1)
+		for y := top; y <= bottom; y++ {
+			for x := left; x <= right; x++ {
+				ch := ' '
+				switch {
+				case (y == top || y == bottom) && (x == left || x == right):
+					ch = boxCorner(y == top, x == left)
+				case y == top || y == bottom:
+					ch = '─'
+				case x == left || x == right:
+					ch = '│'
+				}
+				set(x, y, ch, i)
+			}
+		}
+
+		width := right - left - 1
+		labels := []string{r.Name, fmt.Sprintf("%dx%d", r.W, r.H), fmt.Sprintf("%d,%d", r.X, r.Y)}
+		for k, label := range labels {
+			y := top + 1 + k
+			if y >= bottom || width < 1 {
+				break
+			}
+			label = truncate(label, width)
+			start := left + 1 + (width-len([]rune(label)))/2
+			for n, ch := range []rune(label) {
+				set(start+n, y, ch, i)
+			}
+		}
+	}
+
+	// Wide layouts leave rows at the bottom unused
+	used := min(rows, toRow(maxY))
+
+	var b strings.Builder
+	for y := range grid[:used] {
+		b.WriteString("  ")
+		for x := 0; x < cols; {
+			end := x
+			for end < cols && owner[y][end] == owner[y][x] {
+				end++
+			}
+			b.WriteString(canvasStyle(owner[y][x], selected).Render(string(grid[y][x:end])))
+			x = end
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// boxCorner returns the box-drawing corner for a corner of a box
+func boxCorner(top, left bool) rune {
+	switch {
+	case top && left:
+		return '┌'
+	case top:
+		return '┐'
+	case left:
+		return '└'
+	}
+	return '┘'
+}
+
+// canvasStyle picks the color of an output's box
+func canvasStyle(i, selected int) lipgloss.Style {
+	switch {
+	case i < 0:
+		return lipgloss.NewStyle()
+	case i == selected:
+		return lipgloss.NewStyle().Foreground(styles.ColorGreen).Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(styles.ColorCyan)
+}
@@ -0,0 +1,315 @@
+package screens
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// Output form fields, in display order
+const (
+	outputName = iota
+	outputEnabled
+	outputMode
+	outputScale
+	outputTransform
+	outputX
+	outputY
+	outputVRR
+	outputFocus
+	outputBackground
+	outputFieldCount
+)
+
+// outputLabels are the labels shown next to each field
+var outputLabels = [outputFieldCount]string{
+	outputName:       "Name",
+	outputEnabled:    "Enabled",
+	outputMode:       "Mode",
+	outputScale:      "Scale",
+	outputTransform:  "Transform",
+	outputX:          "Position X",
+	outputY:          "Position Y",
+	outputVRR:        "Variable Refresh",
+	outputFocus:      "Focus at Startup",
+	outputBackground: "Background Color",
+}
+
+// transformChoices are the transforms the form cycles through, "" being
+// niri's default
+var transformChoices = append([]string{""}, config.OutputTransforms...)
+
+// VRR settings the form cycles through
+const (
+	vrrOff = iota
+	vrrOn
+	vrrOnDemand
+)
+
+var vrrLabels = []string{"off", "on", "on demand"}
+
+// outputForm edits a single output block
+type outputForm struct {
+	output      *config.Output // output being edited, nil when adding
+	inputs      [outputFieldCount]textinput.Model
+	enabled     bool
+	transform   int // index in transformChoices
+	vrr         int
+	focus       bool
+	cursor      int
+	err         error
+	suggestions []string // unconfigured connectors ctrl+n cycles through
+	suggested   int
+}
+
+// newOutputForm creates a form filled from o, or a blank one for a new
+// output named name if o is nil
+func newOutputForm(o *config.Output, name string, suggestions []string) *outputForm {
+	f := &outputForm{output: o, enabled: true, suggestions: suggestions, suggested: -1}
+	placeholders := map[int]string{
+		outputName:       "eDP-1",
+		outputMode:       "niri's pick, or 1920x1080@60",
+		outputScale:      "automatic",
+		outputX:          "automatic",
+		outputY:          "automatic",
+		outputBackground: "#000000",
+	}
+	for field, placeholder := range placeholders {
+		in := textinput.New()
+		in.Prompt = ""
+		in.Placeholder = placeholder
+		in.Width = 32
+		f.inputs[field] = in
+	}
+
+	if o == nil {
+		f.inputs[outputName].SetValue(name)
+		if name != "" {
+			f.cursor = outputMode
+		}
+		return f
+	}
+
+	f.cursor = outputMode
+	f.inputs[outputName].SetValue(o.Name)
+	f.enabled = !o.Off
+	f.inputs[outputMode].SetValue(o.Mode)
+	if o.Scale > 0 {
+		f.inputs[outputScale].SetValue(strconv.FormatFloat(o.Scale, 'f', -1, 64))
+	}
+	f.transform = max(0, slices.Index(transformChoices, o.Transform))
+	if o.Positioned {
+		f.inputs[outputX].SetValue(strconv.Itoa(o.X))
+		f.inputs[outputY].SetValue(strconv.Itoa(o.Y))
+	}
+	switch {
+	case o.VRROnDemand:
+		f.vrr = vrrOnDemand
+	case o.VariableRefreshRate:
+		f.vrr = vrrOn
+	}
+	f.focus = o.FocusAtStartup
+	f.inputs[outputBackground].SetValue(o.BackgroundColor)
+	return f
+}
+
+// isOutputText reports whether a field is a text input
+func isOutputText(field int) bool {
+	switch field {
+	case outputName, outputMode, outputScale, outputX, outputY, outputBackground:
+		return true
+	}
+	return false
+}
+
+// focusField focuses the input under the cursor, blurring the others
+func (f *outputForm) focusField() tea.Cmd {
+	var cmd tea.Cmd
+	for field := range f.inputs {
+		if !isOutputText(field) {
+			continue
+		}
+		if field == f.cursor {
+			cmd = f.inputs[field].Focus()
+		} else {
+			f.inputs[field].Blur()
+		}
+	}
+	return cmd
+}
+
+// move moves the cursor by delta fields. The name can only be typed
+// when adding.
+func (f *outputForm) move(delta int) tea.Cmd {
+	for {
+		f.cursor = (f.cursor + delta + outputFieldCount) % outputFieldCount
+		if f.cursor != outputName || f.output == nil {
+			return f.focusField()
+		}
+	}
+}
+
+// update handles a message for the field under the cursor
+func (f *outputForm) update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "shift+tab":
+			return f.move(-1)
+		case "down", "tab":
+			return f.move(1)
+		case "ctrl+n":
+			if f.output == nil && len(f.suggestions) > 0 {
+				f.suggested = (f.suggested + 1) % len(f.suggestions)
+				f.inputs[outputName].SetValue(f.suggestions[f.suggested])
+				f.inputs[outputName].CursorEnd()
+			}
+			return nil
+		case " ":
+			switch f.cursor {
+			case outputEnabled:
+				f.enabled = !f.enabled
+				return nil
+			case outputFocus:
+				f.focus = !f.focus
+				return nil
+			}
+		case "left", "right":
+			delta := 1
+			if msg.String() == "left" {
+				delta = -1
+			}
+			switch f.cursor {
+			case outputTransform:
+				f.transform = (f.transform + delta + len(transformChoices)) % len(transformChoices)
+				return nil
+			case outputVRR:
+				f.vrr = (f.vrr + delta + len(vrrLabels)) % len(vrrLabels)
+				return nil
+			}
+		}
+	}
+
+	if !isOutputText(f.cursor) {
+		return nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.cursor], cmd = f.inputs[f.cursor].Update(msg)
+	return cmd
+}
+
+// value returns the trimmed text of an input
+func (f *outputForm) value(field int) string {
+	return strings.TrimSpace(f.inputs[field].Value())
+}
+
+// submit validates the form and returns the output it describes. The
+// edited output is only changed by the caller, once the form checks out.
+func (f *outputForm) submit() (config.Output, error) {
+	var o config.Output
+	if f.output != nil {
+		o = *f.output
+	} else {
+		o.Name = f.value(outputName)
+	}
+
+	o.Off = !f.enabled
+	o.Mode = f.value(outputMode)
+	o.Scale = 0
+	if s := f.value(outputScale); s != "" {
+		scale, err := strconv.ParseFloat(s, 64)
+		if err != nil || scale <= 0 {
+			return o, fmt.Errorf("scale must be a positive number, such as 1.5")
+		}
+		o.Scale = scale
+	}
+	o.Transform = transformChoices[f.transform]
+
+	x, y := f.value(outputX), f.value(outputY)
+	o.Positioned = x != "" || y != ""
+	if o.Positioned {
+		var errX, errY error
+		o.X, errX = strconv.Atoi(x)
+		o.Y, errY = strconv.Atoi(y)
+		if errX != nil || errY != nil {
+			return o, fmt.Errorf("position needs whole numbers for both x and y, or neither")
+		}
+	}
+
+	o.VariableRefreshRate = f.vrr != vrrOff
+	o.VRROnDemand = f.vrr == vrrOnDemand
+	o.FocusAtStartup = f.focus
+	o.BackgroundColor = f.value(outputBackground)
+	return o, o.Validate()
+}
+
+// view renders the form
+func (f *outputForm) view() string {
+	var b strings.Builder
+
+	title := "Add Output"
+	if f.output != nil {
+		title = "Edit Output " + f.output.Name
+	}
+	b.WriteString(styles.CardTitleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	for field := 0; field < outputFieldCount; field++ {
+		if field == outputName && f.output != nil {
+			continue
+		}
+		selected := field == f.cursor
+
+		cursor := "  "
+		labelStyle := styles.LabelStyle
+		if selected {
+			cursor = styles.SuccessStyle.Render(styles.SymbolArrow + " ")
+			labelStyle = labelStyle.Foreground(styles.ColorGreen)
+		}
+		label := labelStyle.Width(20).Render(outputLabels[field])
+
+		var value string
+		switch field {
+		case outputEnabled:
+			value = renderFormToggle(f.enabled, selected)
+		case outputFocus:
+			value = renderFormToggle(f.focus, selected)
+		case outputTransform:
+			transform := transformChoices[f.transform]
+			if transform == "" {
+				transform = "normal (default)"
+			}
+			value = renderChoice(transform, selected)
+		case outputVRR:
+			value = renderChoice(vrrLabels[f.vrr], selected)
+		default:
+			value = f.inputs[field].View()
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, label, value)
+	}
+
+	if f.output == nil && len(f.suggestions) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.DimmedStyle.Render("Unconfigured: " + strings.Join(f.suggestions, ", ")))
+		b.WriteString("\n")
+	}
+
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	help := "↑↓/tab move • space toggle • ←→ choose • enter apply • esc cancel"
+	if f.output == nil && len(f.suggestions) > 0 {
+		help = "↑↓/tab move • ctrl+n next connector • space toggle • ←→ choose • enter apply • esc cancel"
+	}
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render(help))
+	return b.String()
+}
@@ -0,0 +1,523 @@
+package screens
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/niriipc"
+	"github.com/edellingham/nirimatic/internal/styles"
+	"github.com/edellingham/nirimatic/internal/system"
+)
+
+// revertTimeout is how long a live change lasts without being confirmed
+const revertTimeout = 15 * time.Second
+
+// liveChange is a change applied to a running output, waiting to be kept
+// or reverted
+type liveChange struct {
+	output        string
+	before, after system.LiveSettings
+	remaining     time.Duration
+	gen           int // tells this change's ticks from earlier ones
+}
+
+// liveOutputsMsg carries the outputs niri reports
+type liveOutputsMsg struct {
+	outputs []niriipc.Output
+	err     error
+}
+
+// liveAppliedMsg is sent when a live change was applied or reverted
+type liveAppliedMsg struct {
+	change   liveChange
+	reverted bool
+	err      error
+}
+
+// liveTickMsg counts down the pending change
+type liveTickMsg struct {
+	gen int
+}
+
+// loadLiveOutputs asks niri for its outputs
+func (m *OutputsModel) loadLiveOutputs() tea.Cmd {
+	control := m.control
+	return func() tea.Msg {
+		if control == nil {
+			return liveOutputsMsg{err: fmt.Errorf("no connection to niri")}
+		}
+		outputs, err := control.Outputs()
+		return liveOutputsMsg{outputs: outputs, err: err}
+	}
+}
+
+// applyLive applies a change, or reverts it, in the background
+func (m *OutputsModel) applyLive(change liveChange, revert bool) tea.Cmd {
+	control := m.control
+	return func() tea.Msg {
+		from, to := change.before, change.after
+		if revert {
+			from, to = to, from
+		}
+		err := control.SetOutput(change.output, from, to)
+		return liveAppliedMsg{change: change, reverted: revert, err: err}
+	}
+}
+
+// liveTick waits a second before counting down the pending change
+func liveTick(gen int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return liveTickMsg{gen: gen}
+	})
+}
+
+// updateLiveMsg handles the live pane's messages
+func (m *OutputsModel) updateLiveMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case liveOutputsMsg:
+		m.liveOutputs, m.liveErr = msg.outputs, msg.err
+		m.liveCursor = max(0, min(m.liveCursor, len(m.liveOutputs)-1))
+
+	case liveAppliedMsg:
+		switch {
+		case msg.err != nil && msg.reverted:
+			m.message = fmt.Sprintf("Error reverting %s: %v", msg.change.output, msg.err)
+		case msg.err != nil:
+			// Part of the change may have gone through, so put it all back
+			m.message = fmt.Sprintf("Error: %v; reverting", msg.err)
+			return tea.Batch(m.applyLive(msg.change, true), m.loadLiveOutputs())
+		case msg.reverted:
+			m.message = "Reverted " + msg.change.output
+		default:
+			m.liveGen++
+			change := msg.change
+			change.remaining, change.gen = revertTimeout, m.liveGen
+			m.pending = &change
+			m.message = ""
+			return tea.Batch(liveTick(change.gen), m.loadLiveOutputs())
+		}
+		return m.loadLiveOutputs()
+
+	case liveTickMsg:
+		if m.pending == nil || msg.gen != m.pending.gen {
+			return nil
+		}
+		m.pending.remaining -= time.Second
+		if m.pending.remaining <= 0 {
+			change := *m.pending
+			m.pending = nil
+			return m.applyLive(change, true)
+		}
+		return liveTick(msg.gen)
+	}
+	return nil
+}
+
+// updateLive handles keys in the live pane
+func (m *OutputsModel) updateLive(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case m.pending != nil:
+		return m.updatePending(msg)
+	case m.liveForm != nil:
+		return m.updateLiveForm(msg)
+	}
+
+	switch {
+	case key.Matches(msg, keyUp):
+		m.liveCursor = max(0, m.liveCursor-1)
+	case key.Matches(msg, keyDown):
+		m.liveCursor = max(0, min(len(m.liveOutputs)-1, m.liveCursor+1))
+	case msg.String() == "enter":
+		if m.liveCursor < len(m.liveOutputs) {
+			m.liveForm = newLiveForm(m.liveOutputs[m.liveCursor])
+			m.message = ""
+			return m.liveForm.focusField()
+		}
+	case msg.String() == "g":
+		return m.loadLiveOutputs()
+	case msg.String() == "v":
+		m.live = false
+	}
+	return nil
+}
+
+// updatePending handles keys while a live change waits to be confirmed
+func (m *OutputsModel) updatePending(msg tea.KeyMsg) tea.Cmd {
+	change := *m.pending
+	switch msg.String() {
+	case "y", "enter":
+		m.pending = nil
+		m.message = "Kept the new settings of " + change.output
+	case "p":
+		m.pending = nil
+		if m.config == nil {
+			m.message = "Kept the new settings; no config loaded to save them to"
+			return nil
+		}
+		persistLive(m.config, change.output, change.after)
+		m.dirty = true
+		return m.saveConfig()
+	case "n", "esc":
+		m.pending = nil
+		return m.applyLive(change, true)
+	}
+	return nil
+}
+
+// updateLiveForm handles keys while editing a live output
+func (m *OutputsModel) updateLiveForm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.liveForm = nil
+		return nil
+	case "enter":
+		after, err := m.liveForm.submit()
+		if err != nil {
+			m.liveForm.err = err
+			return nil
+		}
+		f := m.liveForm
+		m.liveForm = nil
+		if after == f.before {
+			m.message = "Nothing changed"
+			return nil
+		}
+		return m.applyLive(liveChange{output: f.output.Name, before: f.before, after: after}, false)
+	}
+	return m.liveForm.update(msg)
+}
+
+// persistLive writes live settings to an output's block, adding one if
+// the output has none
+func persistLive(c *config.NiriConfig, name string, s system.LiveSettings) {
+	o := c.Output(name)
+	if o == nil {
+		o, _ = c.AddOutput(name)
+	}
+	o.Off = !s.On
+	if !s.On {
+		return
+	}
+	if s.Mode.Width > 0 {
+		o.Mode = s.Mode.String()
+	}
+	o.Scale = s.Scale
+	if s.Transform != "normal" || o.Transform != "" {
+		o.Transform = s.Transform
+	}
+	o.Positioned, o.X, o.Y = true, s.X, s.Y
+	if s.VRR != o.VariableRefreshRate {
+		o.VariableRefreshRate, o.VRROnDemand = s.VRR, false
+	}
+}
+
+// renderLive renders the live pane
+func (m *OutputsModel) renderLive() string {
+	var b strings.Builder
+	if m.pending != nil {
+		b.WriteString(m.renderPending())
+		return b.String()
+	}
+	if m.liveForm != nil {
+		b.WriteString(m.liveForm.view())
+		return b.String()
+	}
+
+	b.WriteString(styles.CardTitleStyle.Render("Connected outputs"))
+	b.WriteString("\n")
+	switch {
+	case m.liveErr != nil:
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.liveErr)))
+		b.WriteString("\n")
+	case m.liveOutputs == nil:
+		b.WriteString(styles.DimmedStyle.Render("Asking niri..."))
+		b.WriteString("\n")
+	case len(m.liveOutputs) == 0:
+		b.WriteString(styles.DimmedStyle.Render("niri reports no outputs"))
+		b.WriteString("\n")
+	}
+
+	for i, o := range m.liveOutputs {
+		selected := i == m.liveCursor
+		s := system.OutputSettings(o)
+		b.WriteString(renderRowStart(selected))
+		b.WriteString(styles.RenderToggle(s.On))
+		b.WriteString(" ")
+		b.WriteString(rowTextStyle(selected, s.On).Width(outputColumnWidth).Render(truncate(o.Name, outputColumnWidth-1)))
+		b.WriteString(styles.DimmedStyle.Render(truncate(o.Description(), max(10, m.width-outputColumnWidth-10))))
+		b.WriteString("\n")
+		if s.On {
+			b.WriteString("        ")
+			b.WriteString(styles.ValueStyle.Render(liveSummary(o, s)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("Changes apply at once and revert after 15 seconds unless kept."))
+	return b.String()
+}
+
+// liveSummary describes an output's current state in one line
+func liveSummary(o niriipc.Output, s system.LiveSettings) string {
+	parts := []string{s.Mode.String(), "scale " + strconv.FormatFloat(s.Scale, 'f', -1, 64)}
+	if s.Transform != "normal" {
+		parts = append(parts, "transform "+s.Transform)
+	}
+	parts = append(parts, fmt.Sprintf("at %d,%d", s.X, s.Y))
+	switch {
+	case s.VRR:
+		parts = append(parts, "VRR on")
+	case o.VRRSupported:
+		parts = append(parts, "VRR off")
+	}
+	parts = append(parts, fmt.Sprintf("%d modes", len(o.Modes)))
+	return strings.Join(parts, " • ")
+}
+
+// renderPending renders the confirmation of a live change
+func (m *OutputsModel) renderPending() string {
+	var b strings.Builder
+	p := m.pending
+	b.WriteString(styles.CardTitleStyle.Render("Keep these settings?"))
+	b.WriteString("\n\n")
+	b.WriteString(styles.ValueStyle.Render("Applied new settings to " + p.output))
+	b.WriteString("\n")
+	b.WriteString(styles.WarningStyle.Render(fmt.Sprintf("Reverting in %ds", int(p.remaining.Seconds()))))
+	b.WriteString("\n\n")
+	b.WriteString(styles.DimmedStyle.Render("y keep • p keep and save to config • n revert now"))
+	return b.String()
+}
+
+// Live form fields, in display order
+const (
+	liveEnabled = iota
+	liveMode
+	liveScale
+	liveTransform
+	liveX
+	liveY
+	liveVRR
+	liveFieldCount
+)
+
+// liveLabels are the labels shown next to each field
+var liveLabels = [liveFieldCount]string{
+	liveEnabled:   "Enabled",
+	liveMode:      "Mode",
+	liveScale:     "Scale",
+	liveTransform: "Transform",
+	liveX:         "Position X",
+	liveY:         "Position Y",
+	liveVRR:       "Variable Refresh",
+}
+
+// scaleStep is how far ←→ moves the scale
+const scaleStep = 0.25
+
+// liveForm edits the settings of a running output
+type liveForm struct {
+	output    niriipc.Output
+	before    system.LiveSettings
+	enabled   bool
+	mode      int // index in output.Modes
+	scale     float64
+	transform int // index in config.OutputTransforms
+	x, y      textinput.Model
+	vrr       bool
+	cursor    int
+	err       error
+}
+
+// newLiveForm creates a form filled with an output's current settings
+func newLiveForm(o niriipc.Output) *liveForm {
+	s := system.OutputSettings(o)
+	f := &liveForm{
+		output:    o,
+		before:    s,
+		enabled:   s.On,
+		mode:      max(0, slices.Index(o.Modes, s.Mode)),
+		scale:     s.Scale,
+		transform: max(0, slices.Index(config.OutputTransforms, s.Transform)),
+		vrr:       s.VRR,
+		cursor:    liveMode,
+	}
+	if !s.On {
+		f.cursor = liveEnabled
+		// Offer the preferred mode to turn the output back on with
+		for i, mode := range o.Modes {
+			if mode.Preferred {
+				f.mode = i
+			}
+		}
+	}
+	for _, in := range []*textinput.Model{&f.x, &f.y} {
+		*in = textinput.New()
+		in.Prompt = ""
+		in.Width = 12
+	}
+	f.x.SetValue(strconv.Itoa(s.X))
+	f.y.SetValue(strconv.Itoa(s.Y))
+	return f
+}
+
+// focusField focuses the input under the cursor, blurring the other
+func (f *liveForm) focusField() tea.Cmd {
+	f.x.Blur()
+	f.y.Blur()
+	switch f.cursor {
+	case liveX:
+		return f.x.Focus()
+	case liveY:
+		return f.y.Focus()
+	}
+	return nil
+}
+
+// update handles a message for the field under the cursor
+func (f *liveForm) update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		delta := 0
+		switch msg.String() {
+		case "up", "shift+tab":
+			f.cursor = (f.cursor + liveFieldCount - 1) % liveFieldCount
+			return f.focusField()
+		case "down", "tab":
+			f.cursor = (f.cursor + 1) % liveFieldCount
+			return f.focusField()
+		case " ":
+			switch f.cursor {
+			case liveEnabled:
+				f.enabled = !f.enabled
+				return nil
+			case liveVRR:
+				if f.output.VRRSupported {
+					f.vrr = !f.vrr
+				}
+				return nil
+			}
+		case "left":
+			delta = -1
+		case "right":
+			delta = 1
+		}
+
+		if delta != 0 {
+			switch f.cursor {
+			case liveMode:
+				if n := len(f.output.Modes); n > 0 {
+					f.mode = (f.mode + delta + n) % n
+				}
+				return nil
+			case liveScale:
+				f.scale = math.Round(f.scale/scaleStep)*scaleStep + float64(delta)*scaleStep
+				f.scale = max(scaleStep, min(10, f.scale))
+				return nil
+			case liveTransform:
+				n := len(config.OutputTransforms)
+				f.transform = (f.transform + delta + n) % n
+				return nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	switch f.cursor {
+	case liveX:
+		f.x, cmd = f.x.Update(msg)
+	case liveY:
+		f.y, cmd = f.y.Update(msg)
+	}
+	return cmd
+}
+
+// submit validates the form and returns the settings it describes
+func (f *liveForm) submit() (system.LiveSettings, error) {
+	s := f.before
+	s.On = f.enabled
+	if !s.On {
+		return s, nil
+	}
+	if f.mode < len(f.output.Modes) {
+		s.Mode = f.output.Modes[f.mode]
+	}
+	s.Scale = f.scale
+	s.Transform = config.OutputTransforms[f.transform]
+	x, errX := strconv.Atoi(strings.TrimSpace(f.x.Value()))
+	y, errY := strconv.Atoi(strings.TrimSpace(f.y.Value()))
+	if errX != nil || errY != nil {
+		return s, fmt.Errorf("position needs whole numbers for x and y")
+	}
+	s.X, s.Y = x, y
+	s.VRR = f.vrr
+	return s, nil
+}
+
+// view renders the form
+func (f *liveForm) view() string {
+	var b strings.Builder
+	b.WriteString(styles.CardTitleStyle.Render("Live Settings of " + f.output.Name))
+	b.WriteString("\n\n")
+
+	for field := 0; field < liveFieldCount; field++ {
+		selected := field == f.cursor
+
+		cursor := "  "
+		labelStyle := styles.LabelStyle
+		if selected {
+			cursor = styles.SuccessStyle.Render(styles.SymbolArrow + " ")
+			labelStyle = labelStyle.Foreground(styles.ColorGreen)
+		}
+		label := labelStyle.Width(20).Render(liveLabels[field])
+
+		var value string
+		switch field {
+		case liveEnabled:
+			value = renderFormToggle(f.enabled, selected)
+		case liveMode:
+			value = styles.DimmedStyle.Render("  no modes reported")
+			if f.mode < len(f.output.Modes) {
+				mode := f.output.Modes[f.mode]
+				text := mode.String()
+				if mode.Preferred {
+					text += " (preferred)"
+				}
+				value = renderChoice(text, selected) +
+					styles.DimmedStyle.Render(fmt.Sprintf("  %d/%d", f.mode+1, len(f.output.Modes)))
+			}
+		case liveScale:
+			value = renderChoice(strconv.FormatFloat(f.scale, 'f', -1, 64), selected)
+		case liveTransform:
+			value = renderChoice(config.OutputTransforms[f.transform], selected)
+		case liveX:
+			value = f.x.View()
+		case liveY:
+			value = f.y.View()
+		case liveVRR:
+			if f.output.VRRSupported {
+				value = renderFormToggle(f.vrr, selected)
+			} else {
+				value = styles.DimmedStyle.Render("not supported")
+			}
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, label, value)
+	}
+
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("↑↓/tab move • space toggle • ←→ choose • enter apply live • esc cancel"))
+	return b.String()
+}
@@ -0,0 +1,462 @@
+package screens
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/niriipc"
+	"github.com/edellingham/nirimatic/internal/styles"
+	"github.com/edellingham/nirimatic/internal/system"
+)
+
+// OutputsKeys are the app-wide bindings the outputs screen uses
+type OutputsKeys struct {
+	Add  key.Binding
+	Edit key.Binding
+}
+
+// OutputsModel is the model for the outputs screen. It lists the output
+// blocks of the config, followed by the connectors that have none, and
+// has a live pane changing the outputs of the running niri.
+type OutputsModel struct {
+	keys    OutputsKeys
+	control system.OutputControl
+	config  *config.NiriConfig
+	cursor  int
+	width   int
+	height  int
+	dirty   bool
+	message string
+	err     error
+
+	connectors    []system.Connector
+	connectorsErr error
+
+	form          *outputForm        // open add/edit form, nil when browsing
+	arranging     *outputArrangement // layout being arranged, nil when browsing
+	arrangeCursor int                // output being moved
+
+	live        bool // showing the live pane
+	liveOutputs []niriipc.Output
+	liveErr     error
+	liveCursor  int
+	liveForm    *liveForm   // open live settings form
+	pending     *liveChange // live change waiting to be kept or reverted
+	liveGen     int
+}
+
+// outputsSavedMsg is sent when the outputs screen saved the config
+type outputsSavedMsg struct {
+	err error
+}
+
+// connectorsLoadedMsg carries the display connectors of the machine
+type connectorsLoadedMsg struct {
+	connectors []system.Connector
+	err        error
+}
+
+// outputColumnWidth is the width of the output name column
+const outputColumnWidth = 22
+
+// NewOutputsModel creates a new outputs model
+func NewOutputsModel(keys OutputsKeys, control system.OutputControl) *OutputsModel {
+	return &OutputsModel{keys: keys, control: control}
+}
+
+// Init lists the connectors. The niri config arrives with the
+// configLoadedMsg the settings screen requests.
+func (m *OutputsModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		connectors, err := system.LoadConnectors()
+		return connectorsLoadedMsg{connectors: connectors, err: err}
+	}
+}
+
+// SetSize sets the dimensions
+func (m *OutputsModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Capturing reports whether the screen is taking text input
+func (m *OutputsModel) Capturing() bool {
+	return m.form != nil || m.arranging != nil || m.liveForm != nil || m.pending != nil
+}
+
+// Update handles messages
+func (m *OutputsModel) Update(msg tea.Msg) (*OutputsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case configLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.config = msg.config
+		m.err = nil
+		m.dirty = false
+		m.form = nil
+		m.arranging = nil
+		m.clampCursor()
+		return m, nil
+
+	case configSavedMsg, keybindsSavedMsg, startupSavedMsg, animationsSavedMsg, windowRuleSavedMsg:
+		// Saving from another screen writes the outputs too
+		if savedErr(msg) == nil {
+			m.dirty = false
+		}
+		return m, nil
+
+	case outputsSavedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error saving: %v", msg.err)
+		} else {
+			m.message = "Outputs saved!"
+			m.dirty = false
+		}
+		return m, nil
+
+	case connectorsLoadedMsg:
+		m.connectors, m.connectorsErr = msg.connectors, msg.err
+		m.clampCursor()
+		return m, nil
+
+	case liveOutputsMsg, liveAppliedMsg, liveTickMsg:
+		return m, m.updateLiveMsg(msg)
+
+	case tea.KeyMsg:
+		switch {
+		case m.live:
+			return m, m.updateLive(msg)
+		case m.form != nil:
+			return m, m.updateForm(msg)
+		case m.arranging != nil:
+			return m, m.updateArrange(msg)
+		}
+		if m.config == nil {
+			return m, nil
+		}
+		return m, m.handleKey(msg)
+	}
+
+	switch {
+	case m.form != nil:
+		return m, m.form.update(msg)
+	case m.liveForm != nil:
+		return m, m.liveForm.update(msg)
+	}
+	return m, nil
+}
+
+// unconfigured returns the connectors without an output block
+func (m *OutputsModel) unconfigured() []system.Connector {
+	var out []system.Connector
+	for _, c := range m.connectors {
+		if m.config == nil || m.config.Output(c.Name) == nil {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// rowCount returns the number of rows: outputs, then unconfigured
+// connectors
+func (m *OutputsModel) rowCount() int {
+	if m.config == nil {
+		return 0
+	}
+	return len(m.config.Outputs) + len(m.unconfigured())
+}
+
+// clampCursor keeps the cursor in range
+func (m *OutputsModel) clampCursor() {
+	m.cursor = max(0, min(m.cursor, m.rowCount()-1))
+}
+
+// selected returns the output or unconfigured connector under the cursor
+func (m *OutputsModel) selected() (*config.Output, *system.Connector) {
+	if m.config == nil {
+		return nil, nil
+	}
+	if m.cursor < len(m.config.Outputs) {
+		return m.config.Outputs[m.cursor], nil
+	}
+	free := m.unconfigured()
+	if i := m.cursor - len(m.config.Outputs); i < len(free) {
+		return nil, &free[i]
+	}
+	return nil, nil
+}
+
+// handleKey handles keys while browsing the list
+func (m *OutputsModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	output, connector := m.selected()
+
+	switch {
+	case key.Matches(msg, keyUp):
+		m.cursor = max(0, m.cursor-1)
+	case key.Matches(msg, keyDown):
+		m.cursor = max(0, min(m.rowCount()-1, m.cursor+1))
+	case key.Matches(msg, keyToggle):
+		if output != nil {
+			output.Off = !output.Off
+			m.dirty = true
+		}
+	case key.Matches(msg, m.keys.Add):
+		name := ""
+		if connector != nil {
+			name = connector.Name
+		}
+		return m.openForm(nil, name)
+	case key.Matches(msg, m.keys.Edit), msg.String() == "enter":
+		switch {
+		case output != nil:
+			return m.openForm(output, "")
+		case connector != nil:
+			return m.openForm(nil, connector.Name)
+		}
+	case msg.String() == "p":
+		m.startArranging()
+	case msg.String() == "v":
+		m.live = true
+		m.message = ""
+		return m.loadLiveOutputs()
+	case key.Matches(msg, keySave):
+		return m.saveConfig()
+	}
+	return nil
+}
+
+// openForm opens the form on an output, or on a new one
+func (m *OutputsModel) openForm(o *config.Output, name string) tea.Cmd {
+	var suggestions []string
+	for _, c := range m.unconfigured() {
+		if c.Connected {
+			suggestions = append(suggestions, c.Name)
+		}
+	}
+	m.form = newOutputForm(o, name, suggestions)
+	m.message = ""
+	return m.form.focusField()
+}
+
+// updateForm handles keys while the form is open
+func (m *OutputsModel) updateForm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.form = nil
+		return nil
+	case "enter":
+		edited, err := m.form.submit()
+		if err != nil {
+			m.form.err = err
+			return nil
+		}
+		o := m.form.output
+		if o == nil {
+			if o, err = m.config.AddOutput(edited.Name); err != nil {
+				m.form.err = err
+				return nil
+			}
+			m.cursor = len(m.config.Outputs) - 1
+			m.message = "Added output " + o.Name
+		} else {
+			m.message = "Updated output " + o.Name
+		}
+		*o = edited
+		m.form = nil
+		m.dirty = true
+		m.clampCursor()
+		return nil
+	}
+	return m.form.update(msg)
+}
+
+// View renders the outputs screen
+func (m *OutputsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(styles.TitleStyle.Render("Outputs"))
+	b.WriteString("\n")
+	b.WriteString(styles.SectionStyle.Render("─────────────────────────────────────────"))
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	if m.form != nil {
+		b.WriteString(m.form.view())
+		return b.String()
+	}
+
+	if m.message != "" {
+		b.WriteString(styles.SuccessStyle.Render(m.message))
+		b.WriteString("\n\n")
+	}
+
+	if m.live {
+		b.WriteString(m.renderLive())
+		if m.liveForm == nil && m.pending == nil {
+			b.WriteString("\n\n")
+			b.WriteString(styles.DimmedStyle.Render("enter change live • g refresh • v back to config"))
+		}
+		return b.String()
+	}
+
+	if m.config == nil {
+		b.WriteString(styles.DimmedStyle.Render("Loading config..."))
+		return b.String()
+	}
+
+	b.WriteString(styles.CardTitleStyle.Render("Configured outputs"))
+	b.WriteString("\n")
+	b.WriteString(m.renderOutputs())
+	b.WriteString("\n")
+	b.WriteString(styles.CardTitleStyle.Render("Other connectors"))
+	b.WriteString("\n")
+	b.WriteString(m.renderConnectors())
+	b.WriteString("\n")
+	b.WriteString(styles.CardTitleStyle.Render("Arrangement"))
+	b.WriteString("\n")
+	if m.arranging != nil {
+		b.WriteString(m.renderArrangement(m.arranging, m.arrangeCursor, true))
+	} else {
+		a := newOutputArrangement(m.config.Outputs)
+		output, _ := m.selected()
+		b.WriteString(m.renderArrangement(a, slices.Index(a.outputs, output), false))
+	}
+
+	if m.dirty {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("* Unsaved changes"))
+	}
+
+	help := "space on/off • enter edit or configure • a add • p arrange • v live • s save"
+	if m.arranging != nil {
+		help = "tab next output • ←→↑↓ move and snap • shift+←→↑↓ nudge • enter apply • esc cancel"
+	}
+	b.WriteString("\n\n")
+	b.WriteString(styles.DimmedStyle.Render(help))
+
+	return b.String()
+}
+
+// renderOutputs renders the configured outputs
+func (m *OutputsModel) renderOutputs() string {
+	if len(m.config.Outputs) == 0 {
+		return styles.DimmedStyle.Render("No output blocks; niri sets up every monitor itself.") + "\n"
+	}
+
+	var b strings.Builder
+	for i, o := range m.config.Outputs {
+		selected := i == m.cursor
+		b.WriteString(renderRowStart(selected))
+		b.WriteString(styles.RenderToggle(!o.Off))
+		b.WriteString(" ")
+		name := rowTextStyle(selected, !o.Off).Width(outputColumnWidth)
+		b.WriteString(name.Render(truncate(o.Name, outputColumnWidth-1)))
+		b.WriteString(m.renderConnection(o.Name))
+		b.WriteString(styles.DimmedStyle.Render(truncate(outputSummary(o), max(10, m.width-outputColumnWidth-24))))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderConnectors renders the connectors without an output block
+func (m *OutputsModel) renderConnectors() string {
+	if m.connectorsErr != nil {
+		return styles.DimmedStyle.Render(truncate(fmt.Sprintf("Unavailable: %v", m.connectorsErr), m.width-4)) + "\n"
+	}
+	free := m.unconfigured()
+	if len(free) == 0 {
+		return styles.DimmedStyle.Render("Every connector has an output block") + "\n"
+	}
+
+	var b strings.Builder
+	for i, c := range free {
+		selected := len(m.config.Outputs)+i == m.cursor
+		b.WriteString(renderRowStart(selected))
+		b.WriteString("    ")
+		name := rowTextStyle(selected, c.Connected).Width(outputColumnWidth)
+		b.WriteString(name.Render(truncate(c.Name, outputColumnWidth-1)))
+		b.WriteString(m.renderConnection(c.Name))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderConnection shows whether a monitor is plugged into the named
+// connector. Outputs named by make and model match no connector.
+func (m *OutputsModel) renderConnection(name string) string {
+	status := ""
+	for _, c := range m.connectors {
+		if strings.EqualFold(c.Name, name) {
+			status = "not connected"
+			if c.Connected {
+				status = "connected"
+			}
+		}
+	}
+
+	style := styles.DimmedStyle
+	if status == "connected" {
+		style = styles.SuccessStyle
+	}
+	return style.Width(16).Render(status)
+}
+
+// outputSummary describes an output's settings in one line
+func outputSummary(o *config.Output) string {
+	var parts []string
+	if o.Mode != "" {
+		parts = append(parts, o.Mode)
+	}
+	if o.Scale > 0 {
+		parts = append(parts, "scale "+strconv.FormatFloat(o.Scale, 'f', -1, 64))
+	}
+	if o.Transform != "" && o.Transform != "normal" {
+		parts = append(parts, "transform "+o.Transform)
+	}
+	if o.Positioned {
+		parts = append(parts, fmt.Sprintf("at %d,%d", o.X, o.Y))
+	}
+	switch {
+	case o.VRROnDemand:
+		parts = append(parts, "VRR on demand")
+	case o.VariableRefreshRate:
+		parts = append(parts, "VRR")
+	}
+	if o.FocusAtStartup {
+		parts = append(parts, "focused at startup")
+	}
+	if o.BackgroundColor != "" {
+		parts = append(parts, "background "+o.BackgroundColor)
+	}
+	if len(parts) == 0 {
+		return "niri's defaults"
+	}
+	return strings.Join(parts, " • ")
+}
+
+// saveConfig saves the config file
+func (m *OutputsModel) saveConfig() tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = outputsSavedMsg{err: fmt.Errorf("save failed: %v", r)}
+			}
+		}()
+
+		if m.config == nil {
+			return outputsSavedMsg{err: fmt.Errorf("no config loaded")}
+		}
+		return outputsSavedMsg{err: config.SaveNiriConfig(m.config)}
+	}
+}
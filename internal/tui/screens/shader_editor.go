@@ -0,0 +1,103 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+)
+
+// shaderEditedMsg is sent when the editor editing a shader exits
+type shaderEditedMsg struct {
+	animation string
+	src       string
+	err       error
+}
+
+// editShader opens an animation's custom shader in the user's editor as
+// a temporary .glsl file. Animations without a shader start from a
+// template doing what niri does by default.
+func editShader(a *config.Animation) tea.Cmd {
+	name := a.Name
+	fail := func(err error) tea.Cmd {
+		return func() tea.Msg {
+			return shaderEditedMsg{animation: name, err: err}
+		}
+	}
+
+	f, err := os.CreateTemp("", "nirimatic-"+name+"-*.glsl")
+	if err != nil {
+		return fail(err)
+	}
+	path := f.Name()
+	_, err = f.WriteString(a.ShaderSource())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fail(err)
+	}
+
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return shaderEditedMsg{animation: name, err: fmt.Errorf("editor: %w", err)}
+		}
+		data, err := os.ReadFile(path)
+		return shaderEditedMsg{animation: name, src: string(data), err: err}
+	})
+}
+
+// editorCommand builds the command editing path with $VISUAL or $EDITOR,
+// falling back to vi. The variables may carry arguments, as in
+// "code --wait".
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// applyShader stores an edited shader and reports whether it passes the
+// basic checks
+func (m *AnimationsModel) applyShader(msg shaderEditedMsg) {
+	if msg.err != nil {
+		m.message = fmt.Sprintf("Error: %v", msg.err)
+		return
+	}
+	a := m.config.Animation(msg.animation)
+	if a == nil {
+		return
+	}
+
+	before := a.CustomShader
+	if before == "" && msg.src == a.ShaderSource() {
+		// The template came back untouched
+		m.message = "Shader unchanged"
+		return
+	}
+	a.SetCustomShader(msg.src)
+	if a.CustomShader == before {
+		m.message = "Shader unchanged"
+		return
+	}
+	m.changed()
+
+	switch {
+	case a.CustomShader == "":
+		m.message = "Removed the " + a.Name + " shader"
+	case a.CheckShader(a.CustomShader) != nil:
+		m.message = fmt.Sprintf("%v; fix it before saving", a.CheckShader(a.CustomShader))
+	default:
+		m.message = "Updated the " + a.Name + " shader"
+	}
+}
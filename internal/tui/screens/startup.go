@@ -0,0 +1,616 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+	"github.com/edellingham/nirimatic/internal/system"
+)
+
+// StartupKeys are the app-wide bindings the startup screen uses
+type StartupKeys struct {
+	Add    key.Binding
+	Edit   key.Binding
+	Delete key.Binding
+	Toggle key.Binding
+}
+
+// startupSource is the mechanism that starts an app with the session
+type startupSource int
+
+const (
+	sourceNiri startupSource = iota
+	sourceSystemd
+	sourceAutostart
+)
+
+func (s startupSource) String() string {
+	switch s {
+	case sourceSystemd:
+		return "systemd user service"
+	case sourceAutostart:
+		return "XDG autostart"
+	}
+	return "niri spawn-at-startup"
+}
+
+// startupRow is one line of the startup list
+type startupRow struct {
+	source startupSource
+	niri   *config.StartupEntry
+	unit   *system.Unit
+	auto   *system.AutostartEntry
+}
+
+// command returns the command the row runs
+func (r startupRow) command() []string {
+	switch r.source {
+	case sourceSystemd:
+		return r.unit.ExecStart
+	case sourceAutostart:
+		return r.auto.Command
+	}
+	if r.niri.Shell {
+		return append([]string{"sh", "-c"}, r.niri.Command...)
+	}
+	return r.niri.Command
+}
+
+// StartupModel is the model for the startup apps screen. It lists niri's
+// startup entries next to the systemd user services and XDG autostart
+// files that also start with the session.
+type StartupModel struct {
+	keys      StartupKeys
+	systemctl system.Systemctl
+	config    *config.NiriConfig
+	found     map[*config.StartupEntry]bool // whether each program is on PATH
+	cursor    int
+	width     int
+	height    int
+	dirty     bool
+	message   string
+	err       error
+
+	units        []system.Unit
+	unitsErr     error
+	autostart    []*system.AutostartEntry
+	autostartErr error
+
+	form   *startupForm // open add/edit form, nil when browsing
+	moving bool         // asking where to move the selected app
+}
+
+// startupSavedMsg is sent when the startup screen saved the config
+type startupSavedMsg struct {
+	err error
+}
+
+// sessionLoadedMsg carries the systemd units and autostart files
+type sessionLoadedMsg struct {
+	units        []system.Unit
+	unitsErr     error
+	autostart    []*system.AutostartEntry
+	autostartErr error
+}
+
+// sessionChangedMsg is sent when a unit or autostart file was changed
+type sessionChangedMsg struct {
+	message string
+	err     error
+}
+
+// commandColumnWidth is the width of the command column
+const commandColumnWidth = 46
+
+// NewStartupModel creates a new startup apps model
+func NewStartupModel(keys StartupKeys, systemctl system.Systemctl) *StartupModel {
+	return &StartupModel{
+		keys:      keys,
+		systemctl: systemctl,
+		found:     map[*config.StartupEntry]bool{},
+	}
+}
+
+// Init loads the systemd units and autostart files. The niri config
+// arrives with the configLoadedMsg the settings screen requests.
+func (m *StartupModel) Init() tea.Cmd {
+	return m.loadSession()
+}
+
+// SetSize sets the dimensions
+func (m *StartupModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Capturing reports whether the screen is taking text input
+func (m *StartupModel) Capturing() bool {
+	return m.form != nil || m.moving
+}
+
+// Update handles messages
+func (m *StartupModel) Update(msg tea.Msg) (*StartupModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case configLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.config = msg.config
+		m.err = nil
+		m.dirty = false
+		m.form = nil
+		m.refresh()
+		return m, nil
+
+	case configSavedMsg, keybindsSavedMsg, animationsSavedMsg, outputsSavedMsg, windowRuleSavedMsg:
+		// Saving from another screen writes the startup list too
+		if savedErr(msg) == nil {
+			m.dirty = false
+		}
+		return m, nil
+
+	case startupSavedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error saving: %v", msg.err)
+		} else {
+			m.message = "Startup apps saved!"
+			m.dirty = false
+		}
+		return m, nil
+
+	case sessionLoadedMsg:
+		m.units, m.unitsErr = msg.units, msg.unitsErr
+		m.autostart, m.autostartErr = msg.autostart, msg.autostartErr
+		m.refresh()
+		return m, nil
+
+	case sessionChangedMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error: %v", msg.err)
+		} else {
+			m.message = msg.message
+		}
+		return m, m.loadSession()
+
+	case startupMovedMsg:
+		return m, m.finishMove(msg)
+
+	case tea.KeyMsg:
+		switch {
+		case m.form != nil:
+			return m, m.updateForm(msg)
+		case m.moving:
+			return m, m.updateMove(msg)
+		}
+		return m, m.handleKey(msg)
+	}
+
+	if m.form != nil {
+		return m, m.form.update(msg)
+	}
+	return m, nil
+}
+
+// savedErr returns the error of a message reporting a save
+func savedErr(msg tea.Msg) error {
+	switch msg := msg.(type) {
+	case configSavedMsg:
+		return msg.err
+	case keybindsSavedMsg:
+		return msg.err
+	case startupSavedMsg:
+		return msg.err
+	case animationsSavedMsg:
+		return msg.err
+	case outputsSavedMsg:
+		return msg.err
+	case windowRuleSavedMsg:
+		return msg.err
+	}
+	return nil
+}
+
+// SaveResult reports whether msg is a screen reporting a save, and the
+// save's error
+func SaveResult(msg tea.Msg) (bool, error) {
+	switch msg.(type) {
+	case configSavedMsg, keybindsSavedMsg, startupSavedMsg, animationsSavedMsg, outputsSavedMsg, windowRuleSavedMsg:
+		return true, savedErr(msg)
+	}
+	return false, nil
+}
+
+// handleKey handles keys while browsing the list
+func (m *StartupModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	rows := m.rows()
+	var row *startupRow
+	if m.cursor < len(rows) {
+		row = &rows[m.cursor]
+	}
+	var entry *config.StartupEntry
+	if row != nil {
+		entry = row.niri
+	}
+
+	switch {
+	case msg.String() == "shift+up" || msg.String() == "K":
+		if entry != nil && m.config.MoveStartup(entry, -1) {
+			m.cursor--
+			m.dirty = true
+		}
+	case msg.String() == "shift+down" || msg.String() == "J":
+		if entry != nil && m.config.MoveStartup(entry, 1) {
+			m.cursor++
+			m.dirty = true
+		}
+	case key.Matches(msg, keyUp):
+		m.cursor = max(0, m.cursor-1)
+	case key.Matches(msg, keyDown):
+		m.cursor = max(0, min(len(rows)-1, m.cursor+1))
+	case key.Matches(msg, m.keys.Toggle):
+		if row != nil {
+			return m.toggle(*row)
+		}
+	case msg.String() == "t":
+		if row != nil && row.unit != nil {
+			return m.setUnitRunning(*row.unit, !row.unit.Active())
+		}
+	case msg.String() == "m":
+		if row != nil {
+			m.moving = true
+			m.message = ""
+		}
+	case key.Matches(msg, m.keys.Add):
+		if m.config != nil {
+			m.form = newStartupForm(nil)
+			return m.form.focus()
+		}
+	case key.Matches(msg, m.keys.Edit), msg.String() == "enter":
+		if entry != nil {
+			m.form = newStartupForm(entry)
+			return m.form.focus()
+		}
+	case key.Matches(msg, m.keys.Delete):
+		if entry != nil {
+			m.config.RemoveStartup(entry)
+			m.dirty = true
+			m.message = "Removed " + entry.CommandLine()
+			m.refresh()
+		}
+	case key.Matches(msg, keySave):
+		return m.saveConfig()
+	}
+	return nil
+}
+
+// toggle turns the app on a row off or on: niri entries are commented
+// out, units disabled and autostart files hidden
+func (m *StartupModel) toggle(row startupRow) tea.Cmd {
+	switch row.source {
+	case sourceNiri:
+		row.niri.Enabled = !row.niri.Enabled
+		m.dirty = true
+		return nil
+	case sourceSystemd:
+		unit, enable := row.unit.Name, !row.unit.Enabled()
+		return m.sessionCmd(func() (string, error) {
+			if err := system.SetUnitEnabled(m.systemctl, unit, enable); err != nil {
+				return "", err
+			}
+			if enable {
+				return "Enabled " + unit, nil
+			}
+			return "Disabled " + unit, nil
+		})
+	default:
+		entry, hide := row.auto, !row.auto.Hidden
+		return m.sessionCmd(func() (string, error) {
+			if err := entry.SetHidden(hide); err != nil {
+				return "", err
+			}
+			if hide {
+				return "Hid " + entry.Name, nil
+			}
+			return "Unhid " + entry.Name, nil
+		})
+	}
+}
+
+// setUnitRunning starts or stops a unit
+func (m *StartupModel) setUnitRunning(unit system.Unit, running bool) tea.Cmd {
+	return m.sessionCmd(func() (string, error) {
+		if err := system.SetUnitRunning(m.systemctl, unit.Name, running); err != nil {
+			return "", err
+		}
+		if running {
+			return "Started " + unit.Name, nil
+		}
+		return "Stopped " + unit.Name, nil
+	})
+}
+
+// sessionCmd runs a change to units or autostart files in the
+// background and reports how it went
+func (m *StartupModel) sessionCmd(change func() (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		message, err := change()
+		return sessionChangedMsg{message: message, err: err}
+	}
+}
+
+// loadSession reads the systemd units and autostart files
+func (m *StartupModel) loadSession() tea.Cmd {
+	systemctl := m.systemctl
+	return func() tea.Msg {
+		var msg sessionLoadedMsg
+		if systemctl != nil {
+			msg.units, msg.unitsErr = system.SessionUnits(systemctl)
+		}
+		msg.autostart, msg.autostartErr = system.LoadAutostart()
+		return msg
+	}
+}
+
+// updateForm handles keys while the form is open
+func (m *StartupModel) updateForm(msg tea.KeyMsg) tea.Cmd {
+	if m.form.picker != nil {
+		return m.form.update(msg)
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.form = nil
+		return nil
+	case "enter":
+		e, err := m.form.submit()
+		if err != nil {
+			m.form.err = err
+			return nil
+		}
+		if m.form.entry == nil {
+			m.config.AddStartup(e)
+			m.cursor = len(m.config.Startup) - 1
+			m.message = "Added " + e.CommandLine()
+		} else {
+			m.message = "Updated " + e.CommandLine()
+		}
+		m.form = nil
+		m.dirty = true
+		m.refresh()
+		return nil
+	}
+	return m.form.update(msg)
+}
+
+// rows returns every line of the list: niri entries, then units, then
+// autostart files
+func (m *StartupModel) rows() []startupRow {
+	var rows []startupRow
+	if m.config != nil {
+		for _, e := range m.config.Startup {
+			rows = append(rows, startupRow{source: sourceNiri, niri: e})
+		}
+	}
+	for i := range m.units {
+		rows = append(rows, startupRow{source: sourceSystemd, unit: &m.units[i]})
+	}
+	for _, e := range m.autostart {
+		rows = append(rows, startupRow{source: sourceAutostart, auto: e})
+	}
+	return rows
+}
+
+// refresh looks up every niri startup program on PATH and keeps the
+// cursor in range
+func (m *StartupModel) refresh() {
+	m.found = map[*config.StartupEntry]bool{}
+	if m.config != nil {
+		for _, e := range m.config.Startup {
+			m.found[e] = e.ProgramFound()
+		}
+	}
+	m.cursor = max(0, min(m.cursor, len(m.rows())-1))
+}
+
+// View renders the startup apps screen
+func (m *StartupModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(styles.TitleStyle.Render("Startup Applications"))
+	b.WriteString("\n")
+	b.WriteString(styles.SectionStyle.Render("─────────────────────────────────────────"))
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	if m.form != nil {
+		b.WriteString(m.form.view())
+		return b.String()
+	}
+
+	if m.message != "" {
+		b.WriteString(styles.SuccessStyle.Render(m.message))
+		b.WriteString("\n\n")
+	}
+
+	rows := m.rows()
+	b.WriteString(styles.CardTitleStyle.Render("niri spawn-at-startup"))
+	b.WriteString("\n")
+	b.WriteString(m.renderNiri(rows))
+	b.WriteString("\n")
+	b.WriteString(styles.CardTitleStyle.Render("systemd user services"))
+	b.WriteString("\n")
+	b.WriteString(m.renderUnits(rows))
+	b.WriteString("\n")
+	b.WriteString(styles.CardTitleStyle.Render("XDG autostart"))
+	b.WriteString("\n")
+	b.WriteString(m.renderAutostart(rows))
+
+	if m.moving && m.cursor < len(rows) {
+		b.WriteString("\n")
+		b.WriteString(m.renderMovePrompt(rows[m.cursor]))
+	}
+
+	if m.dirty {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("* Unsaved changes"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(styles.DimmedStyle.Render("space toggle • t start/stop • m move • a add • enter edit • d delete • shift+↑↓ reorder • s save"))
+
+	return b.String()
+}
+
+// renderRowStart renders the cursor column of a row
+func renderRowStart(selected bool) string {
+	if selected {
+		return styles.SuccessStyle.Render(styles.SymbolArrow + " ")
+	}
+	return "  "
+}
+
+// rowTextStyle picks the style of a row's main column
+func rowTextStyle(selected, on bool) lipgloss.Style {
+	style := lipgloss.NewStyle().Width(commandColumnWidth)
+	switch {
+	case selected:
+		return style.Foreground(styles.ColorGreen).Bold(true)
+	case on:
+		return style.Foreground(styles.ColorForeground)
+	}
+	return style.Foreground(styles.ColorComment)
+}
+
+// renderNiri renders niri's startup entries
+func (m *StartupModel) renderNiri(rows []startupRow) string {
+	if m.config == nil {
+		return styles.DimmedStyle.Render("Loading config...") + "\n"
+	}
+	if len(m.config.Startup) == 0 {
+		return styles.DimmedStyle.Render("Nothing starts with niri yet. Press a to add a program.") + "\n"
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		if row.source != sourceNiri {
+			continue
+		}
+		e := row.niri
+		command := e.CommandLine()
+		if e.Shell {
+			command = "sh: " + command
+		}
+
+		var status string
+		switch {
+		case !e.Enabled:
+			status = styles.DimmedStyle.Render("(disabled)")
+		case m.found[e]:
+			status = styles.SuccessStyle.Render(styles.SymbolCheck + " on PATH")
+		default:
+			status = styles.ErrorStyle.Render(styles.SymbolCross + " " + e.Program() + " not found")
+		}
+
+		selected := i == m.cursor
+		b.WriteString(renderRowStart(selected))
+		b.WriteString(styles.RenderToggle(e.Enabled))
+		b.WriteString(" ")
+		b.WriteString(rowTextStyle(selected, e.Enabled).Render(truncate(command, commandColumnWidth-1)))
+		b.WriteString(status)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderUnits renders the systemd user services of the session
+func (m *StartupModel) renderUnits(rows []startupRow) string {
+	if m.unitsErr != nil {
+		return styles.DimmedStyle.Render(truncate(fmt.Sprintf("Unavailable: %v", m.unitsErr), m.width-4)) + "\n"
+	}
+	if len(m.units) == 0 {
+		return styles.DimmedStyle.Render("No services start with "+system.SessionTarget) + "\n"
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		if row.source != sourceSystemd {
+			continue
+		}
+		u := row.unit
+		state := u.ActiveState
+		if state == "" {
+			state = "unknown"
+		}
+		status := strings.ToUpper(state[:1]) + state[1:] + " • " + u.UnitFileState
+
+		selected := i == m.cursor
+		b.WriteString(renderRowStart(selected))
+		b.WriteString(styles.RenderStatus(u.ActiveState))
+		b.WriteString("   ")
+		b.WriteString(rowTextStyle(selected, u.Enabled()).Render(truncate(u.Name, commandColumnWidth-1)))
+		if u.Active() {
+			b.WriteString(styles.SuccessStyle.Render(status))
+		} else {
+			b.WriteString(styles.DimmedStyle.Render(status))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderAutostart renders the user's autostart files
+func (m *StartupModel) renderAutostart(rows []startupRow) string {
+	if m.autostartErr != nil {
+		return styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.autostartErr)) + "\n"
+	}
+	if len(m.autostart) == 0 {
+		return styles.DimmedStyle.Render("No autostart files") + "\n"
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		if row.source != sourceAutostart {
+			continue
+		}
+		e := row.auto
+		status := styles.DimmedStyle.Render(truncate(strings.Join(e.Command, " "), max(10, m.width-commandColumnWidth-12)))
+		if e.Hidden {
+			status = styles.DimmedStyle.Render("(hidden)")
+		}
+
+		selected := i == m.cursor
+		b.WriteString(renderRowStart(selected))
+		b.WriteString(styles.RenderToggle(!e.Hidden))
+		b.WriteString(" ")
+		b.WriteString(rowTextStyle(selected, !e.Hidden).Render(truncate(e.Name, commandColumnWidth-1)))
+		b.WriteString(status)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// saveConfig saves the config file
+func (m *StartupModel) saveConfig() tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = startupSavedMsg{err: fmt.Errorf("save failed: %v", r)}
+			}
+		}()
+
+		if m.config == nil {
+			return startupSavedMsg{err: fmt.Errorf("no config loaded")}
+		}
+		return startupSavedMsg{err: config.SaveNiriConfig(m.config)}
+	}
+}
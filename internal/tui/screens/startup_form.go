@@ -0,0 +1,197 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// Startup form fields, in display order
+const (
+	startupCommand = iota
+	startupShell
+	startupEnabled
+	startupFieldCount
+)
+
+// startupForm edits a single startup entry
+type startupForm struct {
+	entry   *config.StartupEntry // entry being edited, nil when adding
+	command textinput.Model
+	shell   bool
+	enabled bool
+	cursor  int
+	err     error
+	picker  *appPicker // open app picker, if any
+}
+
+// startupLabels are the labels shown next to each field
+var startupLabels = [startupFieldCount]string{
+	startupCommand: "Command",
+	startupShell:   "Run With sh -c",
+	startupEnabled: "Enabled",
+}
+
+// newStartupForm creates a form filled from e, or a blank one if e is nil
+func newStartupForm(e *config.StartupEntry) *startupForm {
+	f := &startupForm{entry: e, enabled: true}
+
+	f.command = textinput.New()
+	f.command.Prompt = ""
+	f.command.Placeholder = `"qs" "-c" "noctalia-shell"`
+	f.command.Width = 48
+
+	if e != nil {
+		f.command.SetValue(e.ArgsString())
+		f.shell = e.Shell
+		f.enabled = e.Enabled
+	}
+	return f
+}
+
+// focus focuses the command input if the cursor is on it
+func (f *startupForm) focus() tea.Cmd {
+	if f.cursor == startupCommand {
+		return f.command.Focus()
+	}
+	f.command.Blur()
+	return nil
+}
+
+// update handles a message for the field under the cursor
+func (f *startupForm) update(msg tea.Msg) tea.Cmd {
+	if f.picker != nil {
+		cmd := f.picker.update(msg)
+		if !f.picker.done {
+			return cmd
+		}
+		if f.picker.accepted {
+			f.command.SetValue(f.picker.command())
+			f.command.CursorEnd()
+			f.shell = false
+			f.cursor = startupCommand
+		}
+		f.picker = nil
+		return tea.Batch(cmd, f.focus())
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+o":
+			var cmd tea.Cmd
+			f.picker, cmd = newAppPicker()
+			return cmd
+		case "up", "shift+tab":
+			f.cursor = (f.cursor + startupFieldCount - 1) % startupFieldCount
+			return f.focus()
+		case "down", "tab":
+			f.cursor = (f.cursor + 1) % startupFieldCount
+			return f.focus()
+		case " ":
+			switch f.cursor {
+			case startupShell:
+				f.shell = !f.shell
+				return nil
+			case startupEnabled:
+				f.enabled = !f.enabled
+				return nil
+			}
+		}
+	}
+
+	if f.cursor != startupCommand {
+		return nil
+	}
+	var cmd tea.Cmd
+	f.command, cmd = f.command.Update(msg)
+	return cmd
+}
+
+// submit validates the form and writes it to the entry, creating one if
+// the form is adding
+func (f *startupForm) submit() (*config.StartupEntry, error) {
+	args, props, err := config.ParseArgs(f.command.Value())
+	if err != nil {
+		return nil, err
+	}
+	if len(props) > 0 {
+		return nil, fmt.Errorf("startup commands take no properties")
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("enter the program to run")
+	}
+
+	var command []string
+	for _, arg := range args {
+		s, ok := arg.AsString()
+		if !ok {
+			return nil, fmt.Errorf("quote every argument, as in %s", f.command.Placeholder)
+		}
+		command = append(command, s)
+	}
+	if f.shell && len(command) != 1 {
+		return nil, fmt.Errorf("a shell command is a single string")
+	}
+
+	e := f.entry
+	if e == nil {
+		e = config.NewStartupEntry()
+	}
+	e.Command = command
+	e.Shell = f.shell
+	e.Enabled = f.enabled
+	return e, nil
+}
+
+// view renders the form
+func (f *startupForm) view() string {
+	if f.picker != nil {
+		return f.picker.view()
+	}
+
+	var b strings.Builder
+
+	title := "Edit Startup App"
+	if f.entry == nil {
+		title = "Add Startup App"
+	}
+	b.WriteString(styles.CardTitleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	for field := 0; field < startupFieldCount; field++ {
+		selected := field == f.cursor
+
+		cursor := "  "
+		labelStyle := styles.LabelStyle
+		if selected {
+			cursor = styles.SuccessStyle.Render(styles.SymbolArrow + " ")
+			labelStyle = labelStyle.Foreground(styles.ColorGreen)
+		}
+		label := labelStyle.Width(20).Render(startupLabels[field])
+
+		var value string
+		switch field {
+		case startupCommand:
+			value = f.command.View()
+		case startupShell:
+			value = renderFormToggle(f.shell, selected)
+		case startupEnabled:
+			value = renderFormToggle(f.enabled, selected)
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, label, value)
+	}
+
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("↑↓/tab move • ctrl+o pick app • space toggle • enter apply • esc cancel"))
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package screens
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/styles"
+	"github.com/edellingham/nirimatic/internal/system"
+)
+
+// startupMovedMsg is sent when an app was set up under its new mechanism
+type startupMovedMsg struct {
+	from    *config.StartupEntry // niri entry to drop, nil if it came from elsewhere
+	to      *config.StartupEntry // niri entry to add, nil if it moved elsewhere
+	message string
+	err     error
+}
+
+// moveTargets maps the keys of the move prompt to the mechanisms
+var moveTargets = map[string]startupSource{
+	"n": sourceNiri,
+	"u": sourceSystemd,
+	"a": sourceAutostart,
+}
+
+// updateMove handles keys while asking where to move the selected app
+func (m *StartupModel) updateMove(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "esc" {
+		m.moving = false
+		return nil
+	}
+	target, ok := moveTargets[msg.String()]
+	if !ok {
+		return nil
+	}
+	m.moving = false
+
+	rows := m.rows()
+	if m.cursor >= len(rows) {
+		return nil
+	}
+	row := rows[m.cursor]
+	if target == row.source {
+		m.message = "Already a " + target.String()
+		return nil
+	}
+	command := row.command()
+	if len(command) == 0 {
+		m.message = "Error: nothing to run"
+		return nil
+	}
+
+	if target == sourceNiri {
+		if m.config == nil {
+			m.message = "Error: no config loaded"
+			return nil
+		}
+		// The entry is only added once the old mechanism is off, so a
+		// failure can't leave the app starting twice
+		e := config.NewStartupEntry(command...)
+		return func() tea.Msg {
+			if _, err := m.turnOff(row); err != nil {
+				return startupMovedMsg{err: err}
+			}
+			return startupMovedMsg{to: e, message: "Moved " + e.CommandLine() + " to niri"}
+		}
+	}
+
+	systemctl := m.systemctl
+	name := appName(command)
+	return func() tea.Msg {
+		if target == sourceSystemd && systemctl == nil {
+			return startupMovedMsg{err: fmt.Errorf("systemd is not available")}
+		}
+		// Turn the old mechanism off first and back on if creating the
+		// new one fails, so nothing is left behind either way
+		restore, err := m.turnOff(row)
+		if err != nil {
+			return startupMovedMsg{err: err}
+		}
+		var created string
+		switch target {
+		case sourceSystemd:
+			created, err = system.CreateSessionUnit(systemctl, name, command)
+		default:
+			var e *system.AutostartEntry
+			if e, err = system.CreateAutostart(name, command); err == nil {
+				created = e.Path()
+			}
+		}
+		if err != nil {
+			if rerr := restore(); rerr != nil {
+				err = fmt.Errorf("%w; turning the app back on also failed: %v", err, rerr)
+			}
+			return startupMovedMsg{err: err}
+		}
+		return startupMovedMsg{from: row.niri, message: "Created " + created}
+	}
+}
+
+// turnOff stops a unit or autostart file from starting the app before it
+// moves, and returns a function that puts it back as it was. niri
+// entries are removed by finishMove instead.
+func (m *StartupModel) turnOff(row startupRow) (restore func() error, err error) {
+	switch row.source {
+	case sourceSystemd:
+		enabled := row.unit.Enabled()
+		if err := system.SetUnitEnabled(m.systemctl, row.unit.Name, false); err != nil {
+			return nil, err
+		}
+		return func() error {
+			if !enabled {
+				return nil
+			}
+			return system.SetUnitEnabled(m.systemctl, row.unit.Name, true)
+		}, nil
+	case sourceAutostart:
+		hidden := row.auto.Hidden
+		if err := row.auto.SetHidden(true); err != nil {
+			return nil, err
+		}
+		return func() error { return row.auto.SetHidden(hidden) }, nil
+	}
+	return func() error { return nil }, nil
+}
+
+// finishMove adds or drops the niri entry of an app that moved into or
+// out of niri and reloads the session list
+func (m *StartupModel) finishMove(msg startupMovedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.message = fmt.Sprintf("Error: %v", msg.err)
+		return m.loadSession()
+	}
+	m.message = msg.message
+	if (msg.from != nil || msg.to != nil) && m.config != nil {
+		if msg.from != nil {
+			m.config.RemoveStartup(msg.from)
+		}
+		if msg.to != nil {
+			m.config.AddStartup(msg.to)
+		}
+		m.dirty = true
+		m.message += ", press s to save"
+		m.refresh()
+	}
+	return m.loadSession()
+}
+
+// appName derives a file name for an app from its command
+func appName(command []string) string {
+	program := command[0]
+	if len(command) == 3 && filepath.Base(program) == "sh" && command[1] == "-c" {
+		if fields := strings.Fields(command[2]); len(fields) > 0 {
+			program = fields[0]
+		}
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '-'
+	}, filepath.Base(program))
+	name = strings.Trim(name, "-.")
+	if name == "" {
+		return "startup-app"
+	}
+	return name
+}
+
+// renderMovePrompt asks where to move the app on a row
+func (m *StartupModel) renderMovePrompt(row startupRow) string {
+	var options []string
+	for _, o := range []struct {
+		key    string
+		target startupSource
+	}{{"n", sourceNiri}, {"u", sourceSystemd}, {"a", sourceAutostart}} {
+		if o.target != row.source {
+			options = append(options, o.key+" "+o.target.String())
+		}
+	}
+	return styles.WarningStyle.Render("Move to: "+strings.Join(options, " • ")) +
+		styles.DimmedStyle.Render(" • esc cancel")
+}
@@ -0,0 +1,29 @@
+package screens
+
+import (
+	"os"
+	"sync"
+)
+
+// Terminal is the output nirimatic's programs render to; pass it to
+// tea.WithOutput. Writes are serialized, so escape sequences sent from
+// commands never land in the middle of a frame.
+var Terminal = &terminalOutput{file: os.Stdout}
+
+// terminalOutput wraps the terminal with a lock around writes. Read,
+// Close and Fd pass through so bubbletea still sees a terminal and can
+// query its size.
+type terminalOutput struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (t *terminalOutput) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.file.Write(p)
+}
+
+func (t *terminalOutput) Read(p []byte) (int, error) { return t.file.Read(p) }
+func (t *terminalOutput) Close() error               { return t.file.Close() }
+func (t *terminalOutput) Fd() uintptr                { return t.file.Fd() }
@@ -0,0 +1,342 @@
+package screens
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/niriipc"
+	"github.com/edellingham/nirimatic/internal/styles"
+)
+
+// Window rule form fields, in display order
+const (
+	ruleAppID = iota
+	ruleTitle
+	ruleFloating
+	ruleWidth
+	ruleOpacity
+	ruleBlockOut
+	ruleWorkspace
+	ruleFieldCount
+)
+
+// ruleLabels are the labels shown next to each field
+var ruleLabels = [ruleFieldCount]string{
+	ruleAppID:     "Match App ID",
+	ruleTitle:     "Match Title",
+	ruleFloating:  "Open Floating",
+	ruleWidth:     "Column Width",
+	ruleOpacity:   "Opacity",
+	ruleBlockOut:  "Block Out From",
+	ruleWorkspace: "Open On Workspace",
+}
+
+// floatingChoices are the open-floating values, unset first
+var floatingChoices = []string{"unchanged", "yes", "no"}
+
+// windowRuleForm writes a window rule matching a live window
+type windowRuleForm struct {
+	window  niriipc.Window
+	appID   textinput.Model
+	title   textinput.Model
+	width   textinput.Model
+	opacity textinput.Model
+
+	floating   int // index into floatingChoices
+	blockOut   int // index into blockOuts
+	blockOuts  []string
+	workspace  int // index into workspaces
+	workspaces []string
+
+	cursor int
+	err    error
+}
+
+// newWindowRuleForm creates a form for a rule matching w, picking the
+// workspace from the named ones in workspaces
+func newWindowRuleForm(w niriipc.Window, workspaces []niriipc.Workspace) *windowRuleForm {
+	f := &windowRuleForm{
+		window:     w,
+		appID:      newRuleInput("^org\\.example\\.App$", 48),
+		title:      newRuleInput("ctrl+t for this title", 48),
+		width:      newRuleInput("50% or 1200", 12),
+		opacity:    newRuleInput("0.9", 12),
+		blockOuts:  append([]string{"none"}, config.BlockOutTargets...),
+		workspaces: []string{"any"},
+	}
+
+	// Match on the app id, which stays put, and only fall back on the
+	// title, which changes with what the window shows
+	if w.AppID != nil && *w.AppID != "" {
+		f.appID.SetValue(config.ExactRegex(*w.AppID))
+	} else if w.Title != nil {
+		f.title.SetValue(config.ExactRegex(*w.Title))
+	}
+	if w.IsFloating {
+		f.floating = slices.Index(floatingChoices, "yes")
+	}
+
+	named := slices.DeleteFunc(slices.Clone(workspaces), func(ws niriipc.Workspace) bool {
+		return ws.Name == nil
+	})
+	slices.SortFunc(named, func(a, b niriipc.Workspace) int {
+		return cmp.Or(cmp.Compare(deref(a.Output), deref(b.Output)), cmp.Compare(a.Idx, b.Idx))
+	})
+	for _, ws := range named {
+		if w.WorkspaceID != nil && ws.ID == *w.WorkspaceID {
+			f.workspace = len(f.workspaces)
+		}
+		f.workspaces = append(f.workspaces, *ws.Name)
+	}
+	return f
+}
+
+// newRuleInput creates one of the form's text inputs
+func newRuleInput(placeholder string, width int) textinput.Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Placeholder = placeholder
+	input.Width = width
+	return input
+}
+
+// input returns the text input of a field, or nil for choices
+func (f *windowRuleForm) input(field int) *textinput.Model {
+	switch field {
+	case ruleAppID:
+		return &f.appID
+	case ruleTitle:
+		return &f.title
+	case ruleWidth:
+		return &f.width
+	case ruleOpacity:
+		return &f.opacity
+	}
+	return nil
+}
+
+// focus focuses the input under the cursor, if any
+func (f *windowRuleForm) focus() tea.Cmd {
+	var cmd tea.Cmd
+	for field := 0; field < ruleFieldCount; field++ {
+		input := f.input(field)
+		switch {
+		case input == nil:
+		case field == f.cursor:
+			cmd = input.Focus()
+		default:
+			input.Blur()
+		}
+	}
+	return cmd
+}
+
+// update handles a message for the field under the cursor
+func (f *windowRuleForm) update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "shift+tab":
+			f.cursor = (f.cursor + ruleFieldCount - 1) % ruleFieldCount
+			return f.focus()
+		case "down", "tab":
+			f.cursor = (f.cursor + 1) % ruleFieldCount
+			return f.focus()
+		case "ctrl+t":
+			if f.window.Title != nil {
+				f.title.SetValue(config.ExactRegex(*f.window.Title))
+				f.title.CursorEnd()
+			}
+			return nil
+		case "left", "right", " ":
+			step := 1
+			if msg.String() == "left" {
+				step = -1
+			}
+			switch f.cursor {
+			case ruleFloating:
+				f.floating = cycle(f.floating, step, len(floatingChoices))
+				return nil
+			case ruleBlockOut:
+				f.blockOut = cycle(f.blockOut, step, len(f.blockOuts))
+				return nil
+			case ruleWorkspace:
+				f.workspace = cycle(f.workspace, step, len(f.workspaces))
+				return nil
+			}
+		}
+	}
+
+	input := f.input(f.cursor)
+	if input == nil {
+		return nil
+	}
+	var cmd tea.Cmd
+	*input, cmd = input.Update(msg)
+	return cmd
+}
+
+// cycle steps i through n choices, wrapping at either end
+func cycle(i, step, n int) int {
+	return (i + step + n) % n
+}
+
+// submit validates the form and returns the rule it describes
+func (f *windowRuleForm) submit() (config.WindowRule, error) {
+	r := config.WindowRule{
+		AppID:              strings.TrimSpace(f.appID.Value()),
+		Title:              strings.TrimSpace(f.title.Value()),
+		DefaultColumnWidth: strings.TrimSpace(f.width.Value()),
+	}
+	if choice := floatingChoices[f.floating]; choice != "unchanged" {
+		floating := choice == "yes"
+		r.OpenFloating = &floating
+	}
+	if s := strings.TrimSpace(f.opacity.Value()); s != "" {
+		opacity, err := strconv.ParseFloat(s, 64)
+		if err != nil || opacity <= 0 || opacity > 1 {
+			return r, fmt.Errorf("opacity %q: use a number above 0, up to 1", s)
+		}
+		r.Opacity = opacity
+	}
+	if f.blockOut > 0 {
+		r.BlockOutFrom = f.blockOuts[f.blockOut]
+	}
+	if f.workspace > 0 {
+		r.OpenOnWorkspace = f.workspaces[f.workspace]
+	}
+	return r, r.Validate()
+}
+
+// view renders the form
+func (f *windowRuleForm) view() string {
+	var b strings.Builder
+
+	b.WriteString(styles.CardTitleStyle.Render("New Window Rule for " + windowName(f.window)))
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("Title: " + f.window.TitleOr("—")))
+	b.WriteString("\n\n")
+
+	for field := 0; field < ruleFieldCount; field++ {
+		selected := field == f.cursor
+
+		cursor := "  "
+		labelStyle := styles.LabelStyle
+		if selected {
+			cursor = styles.SuccessStyle.Render(styles.SymbolArrow + " ")
+			labelStyle = labelStyle.Foreground(styles.ColorGreen)
+		}
+		label := labelStyle.Width(20).Render(ruleLabels[field])
+
+		var value string
+		switch field {
+		case ruleFloating:
+			value = renderChoice(floatingChoices[f.floating], selected)
+		case ruleBlockOut:
+			value = renderChoice(f.blockOuts[f.blockOut], selected)
+		case ruleWorkspace:
+			value = renderChoice(f.workspaces[f.workspace], selected)
+		default:
+			value = f.input(field).View()
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, label, value)
+	}
+
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("↑↓/tab move • ←→ choose • ctrl+t match this title • enter add rule • esc cancel"))
+	return b.String()
+}
+
+// windowRuleSavedMsg is sent when a new window rule has been saved
+type windowRuleSavedMsg struct {
+	name string // the window the rule was made from
+	err  error
+}
+
+// saveWindowRule adds a rule to the config and saves it
+func saveWindowRule(cfg *config.NiriConfig, r config.WindowRule, name string) tea.Cmd {
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = windowRuleSavedMsg{name: name, err: fmt.Errorf("save failed: %v", r)}
+			}
+		}()
+
+		if cfg == nil {
+			return windowRuleSavedMsg{name: name, err: fmt.Errorf("no config loaded")}
+		}
+		return windowRuleSavedMsg{name: name, err: cfg.SaveWindowRule(r)}
+	}
+}
+
+// RuleEditorModel is the window rule form on its own, for `nirimatic rule`.
+// It quits once the rule is saved or the form is cancelled.
+type RuleEditorModel struct {
+	config *config.NiriConfig
+	form   *windowRuleForm
+	saving bool
+	saved  bool
+	err    error
+}
+
+// NewRuleEditorModel creates an editor for a rule matching w, saved to cfg
+func NewRuleEditorModel(cfg *config.NiriConfig, w niriipc.Window, workspaces []niriipc.Workspace) *RuleEditorModel {
+	return &RuleEditorModel{config: cfg, form: newWindowRuleForm(w, workspaces)}
+}
+
+// Saved reports whether the rule was saved, and the error if saving failed
+func (m *RuleEditorModel) Saved() (bool, error) {
+	return m.saved, m.err
+}
+
+// Init focuses the first field
+func (m *RuleEditorModel) Init() tea.Cmd {
+	return m.form.focus()
+}
+
+// Update handles messages
+func (m *RuleEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case windowRuleSavedMsg:
+		m.saving = false
+		m.saved, m.err = msg.err == nil, msg.err
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		if m.saving {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, tea.Quit
+		case "enter":
+			r, err := m.form.submit()
+			if err != nil {
+				m.form.err = err
+				return m, nil
+			}
+			m.saving = true
+			return m, saveWindowRule(m.config, r, windowName(m.form.window))
+		}
+	}
+	return m, m.form.update(msg)
+}
+
+// View renders the form
+func (m *RuleEditorModel) View() string {
+	if m.saving {
+		return styles.DimmedStyle.Render("Saving...") + "\n"
+	}
+	return m.form.view() + "\n"
+}
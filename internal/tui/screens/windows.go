@@ -0,0 +1,684 @@
+package screens
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/edellingham/nirimatic/internal/config"
+	"github.com/edellingham/nirimatic/internal/niriipc"
+	"github.com/edellingham/nirimatic/internal/styles"
+	"github.com/sahilm/fuzzy"
+)
+
+// WindowsKeys are the app-wide bindings the windows screen uses
+type WindowsKeys struct {
+	Filter key.Binding
+	Add    key.Binding // window rule from the selected window
+}
+
+// ActionRunner runs niri actions. *niriipc.Client is one; it is an
+// interface so a fake can stand in for niri.
+type ActionRunner interface {
+	Action(a niriipc.Action) error
+}
+
+// WindowsModel is the model for the windows screen, a switcher and
+// inspector for the windows niri has open. It follows niri's event
+// stream, which the app hands to every screen.
+type WindowsModel struct {
+	keys    WindowsKeys
+	actions ActionRunner
+	config  *config.NiriConfig // nil until loaded
+	niri    *niriipc.State     // nil until niri is connected
+	visible []windowRow        // windows matching the filter, in display order
+	cursor  int
+	offset  int // first visible row
+	width   int
+	height  int
+	message string
+	err     error
+
+	filter    textinput.Model
+	filtering bool
+	prompt    *windowPrompt   // open move or width prompt
+	form      *windowRuleForm // open window rule form
+}
+
+// windowRow is a window with the workspace it is on
+type windowRow struct {
+	window    niriipc.Window
+	workspace *niriipc.Workspace // nil for windows on no workspace
+}
+
+// windowActionMsg is sent when an action on a window has run
+type windowActionMsg struct {
+	done string // what was done, for the message
+	err  error
+}
+
+// Column widths of the windows table
+const (
+	appIDColumnWidth        = 24
+	workspaceColumnWidth    = 12
+	windowOutputColumnWidth = 12
+	layoutColumnWidth       = 10
+)
+
+// NewWindowsModel creates a new windows model. actions may be nil
+// outside a niri session.
+func NewWindowsModel(keys WindowsKeys, actions ActionRunner) *WindowsModel {
+	filter := textinput.New()
+	filter.Prompt = ""
+	filter.Placeholder = "type to filter"
+	filter.Width = 24
+
+	return &WindowsModel{keys: keys, actions: actions, filter: filter}
+}
+
+// Init initializes the model. The windows arrive on the event stream.
+func (m *WindowsModel) Init() tea.Cmd {
+	return nil
+}
+
+// SetSize sets the dimensions
+func (m *WindowsModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clampScroll()
+}
+
+// SetConnected tells the screen whether the event stream is up. A new
+// stream starts with every window again.
+func (m *WindowsModel) SetConnected(connected bool) {
+	m.niri = nil
+	if connected {
+		m.niri = niriipc.NewState()
+	}
+	m.prompt = nil
+	m.form = nil
+	m.applyFilter()
+}
+
+// Capturing reports whether the screen is taking text input
+func (m *WindowsModel) Capturing() bool {
+	return m.filtering || m.prompt != nil || m.form != nil
+}
+
+// Update handles messages
+func (m *WindowsModel) Update(msg tea.Msg) (*WindowsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case niriipc.Event:
+		if m.niri != nil {
+			m.niri.Apply(msg)
+			m.applyFilter()
+		}
+		return m, nil
+
+	case configLoadedMsg:
+		if msg.err == nil {
+			m.config = msg.config
+		}
+		return m, nil
+
+	case windowRuleSavedMsg:
+		if msg.err != nil {
+			m.message, m.err = "", fmt.Errorf("saving the rule: %w", msg.err)
+		} else {
+			m.message, m.err = "Added a window rule for "+msg.name, nil
+		}
+		return m, nil
+
+	case windowActionMsg:
+		if msg.err != nil {
+			m.message, m.err = "", msg.err
+		} else {
+			m.message, m.err = msg.done, nil
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case m.filtering:
+			return m, m.updateFilter(msg)
+		case m.prompt != nil:
+			return m, m.updatePrompt(msg)
+		case m.form != nil:
+			return m, m.updateForm(msg)
+		}
+		return m, m.handleKey(msg)
+	}
+
+	var cmd tea.Cmd
+	switch {
+	case m.filtering:
+		m.filter, cmd = m.filter.Update(msg)
+	case m.prompt != nil:
+		m.prompt.input, cmd = m.prompt.input.Update(msg)
+	case m.form != nil:
+		cmd = m.form.update(msg)
+	}
+	return m, cmd
+}
+
+// handleKey handles keys while browsing the list
+func (m *WindowsModel) handleKey(msg tea.KeyMsg) tea.Cmd {
+	row := m.selected()
+
+	switch {
+	case key.Matches(msg, keyUp):
+		m.cursor--
+		m.clampScroll()
+		return nil
+	case key.Matches(msg, keyDown):
+		m.cursor++
+		m.clampScroll()
+		return nil
+	case key.Matches(msg, m.keys.Filter):
+		m.filtering = true
+		return m.filter.Focus()
+	}
+	if row == nil {
+		return nil
+	}
+	if key.Matches(msg, m.keys.Add) {
+		m.form = newWindowRuleForm(row.window, slices.Collect(maps.Values(m.niri.Workspaces)))
+		m.message, m.err = "", nil
+		return m.form.focus()
+	}
+
+	w := row.window
+	name := windowName(w)
+	switch msg.String() {
+	case "enter":
+		return m.run(niriipc.FocusWindow(w.ID), "Focused "+name)
+	case "c":
+		return m.run(niriipc.CloseWindow(w.ID), "Asked "+name+" to close")
+	case "f":
+		done := "Made " + name + " float"
+		if w.IsFloating {
+			done = "Tiled " + name
+		}
+		return m.run(niriipc.ToggleWindowFloating(w.ID), done)
+	case "m":
+		return m.openPrompt(promptWorkspace, w)
+	case "o":
+		return m.openPrompt(promptOutput, w)
+	case "w":
+		return m.openPrompt(promptWidth, w)
+	}
+	return nil
+}
+
+// run runs a niri action in the background
+func (m *WindowsModel) run(a niriipc.Action, done string) tea.Cmd {
+	actions := m.actions
+	return func() tea.Msg {
+		if actions == nil {
+			return windowActionMsg{err: fmt.Errorf("no connection to niri")}
+		}
+		return windowActionMsg{done: done, err: actions.Action(a)}
+	}
+}
+
+// updateForm handles keys while the window rule form is open
+func (m *WindowsModel) updateForm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.form = nil
+		return nil
+	case "enter":
+		r, err := m.form.submit()
+		if err != nil {
+			m.form.err = err
+			return nil
+		}
+		name := windowName(m.form.window)
+		m.form = nil
+		return saveWindowRule(m.config, r, name)
+	}
+	return m.form.update(msg)
+}
+
+// updateFilter handles keys while typing in the filter
+func (m *WindowsModel) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.filter.SetValue("")
+		fallthrough
+	case "enter":
+		m.filtering = false
+		m.filter.Blur()
+		m.applyFilter()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.applyFilter()
+	return cmd
+}
+
+// rows returns every window, grouped by output and workspace
+func (m *WindowsModel) rows() []windowRow {
+	if m.niri == nil {
+		return nil
+	}
+	rows := make([]windowRow, 0, len(m.niri.Windows))
+	for _, w := range m.niri.Windows {
+		row := windowRow{window: w}
+		if w.WorkspaceID != nil {
+			if ws, ok := m.niri.Workspaces[*w.WorkspaceID]; ok {
+				row.workspace = &ws
+			}
+		}
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b windowRow) int {
+		return cmp.Or(
+			cmp.Compare(a.output(), b.output()),
+			cmp.Compare(a.workspaceIdx(), b.workspaceIdx()),
+			cmp.Compare(a.window.ID, b.window.ID),
+		)
+	})
+	return rows
+}
+
+// output returns the name of the row's output
+func (r windowRow) output() string {
+	if r.workspace == nil || r.workspace.Output == nil {
+		return ""
+	}
+	return *r.workspace.Output
+}
+
+// workspaceIdx returns the position of the row's workspace on its output
+func (r windowRow) workspaceIdx() int {
+	if r.workspace == nil {
+		return 0
+	}
+	return r.workspace.Idx
+}
+
+// workspaceLabel names the row's workspace
+func (r windowRow) workspaceLabel() string {
+	if r.workspace == nil {
+		return ""
+	}
+	return r.workspace.Label()
+}
+
+// applyFilter recomputes the visible rows, fuzzy matching the filter
+// against each window's app id, title, workspace and output
+func (m *WindowsModel) applyFilter() {
+	var selected *uint64
+	if row := m.selected(); row != nil {
+		id := row.window.ID
+		selected = &id
+	}
+
+	rows := m.rows()
+	pattern := strings.TrimSpace(m.filter.Value())
+	if pattern == "" {
+		m.visible = rows
+	} else {
+		targets := make([]string, len(rows))
+		for i, r := range rows {
+			targets[i] = strings.Join([]string{r.window.AppIDOr(""), r.window.TitleOr(""), r.workspaceLabel(), r.output()}, " ")
+		}
+		m.visible = nil
+		for _, match := range fuzzy.Find(pattern, targets) {
+			m.visible = append(m.visible, rows[match.Index])
+		}
+	}
+
+	// Stay on the same window as the list changes around it
+	if selected != nil {
+		for i, r := range m.visible {
+			if r.window.ID == *selected {
+				m.cursor = i
+			}
+		}
+	}
+	m.clampScroll()
+}
+
+// selected returns the row under the cursor
+func (m *WindowsModel) selected() *windowRow {
+	if m.cursor < 0 || m.cursor >= len(m.visible) {
+		return nil
+	}
+	return &m.visible[m.cursor]
+}
+
+// clampScroll keeps the cursor in range and on screen
+func (m *WindowsModel) clampScroll() {
+	m.cursor = max(0, min(m.cursor, len(m.visible)-1))
+	rows := m.tableHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.visible)-rows))
+}
+
+// tableHeight is the number of table rows that fit on screen
+func (m *WindowsModel) tableHeight() int {
+	// Title, filter, table header, details and footer lines
+	return max(1, m.height-16)
+}
+
+// View renders the windows screen
+func (m *WindowsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(styles.TitleStyle.Render("Windows"))
+	b.WriteString("\n")
+	b.WriteString(styles.SectionStyle.Render("─────────────────────────────────────────"))
+	b.WriteString("\n\n")
+
+	switch {
+	case m.err != nil:
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	case m.message != "":
+		b.WriteString(styles.SuccessStyle.Render(m.message))
+		b.WriteString("\n\n")
+	}
+
+	if m.niri == nil {
+		b.WriteString(styles.DimmedStyle.Render("Waiting for niri..."))
+		return b.String()
+	}
+
+	b.WriteString(m.renderFilterLine())
+	b.WriteString("\n\n")
+	b.WriteString(m.renderTable())
+	b.WriteString("\n")
+
+	if m.prompt != nil {
+		b.WriteString(m.prompt.view())
+		return b.String()
+	}
+	if m.form != nil {
+		b.WriteString(m.form.view())
+		return b.String()
+	}
+
+	b.WriteString(m.renderDetails())
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("/ filter • enter focus • c close • f float/tile • m move to workspace • o move to output • w width • a add window rule"))
+	return b.String()
+}
+
+// renderFilterLine renders the filter input and match count
+func (m *WindowsModel) renderFilterLine() string {
+	label := styles.LabelStyle.UnsetWidth().Render("Filter: ")
+	var input string
+	switch {
+	case m.filtering:
+		input = m.filter.View()
+	case m.filter.Value() != "":
+		input = styles.ValueStyle.Render(m.filter.Value())
+	default:
+		input = styles.DimmedStyle.Render("press / to filter")
+	}
+	return fmt.Sprintf("%s%s  %s", label, input,
+		styles.DimmedStyle.Render(fmt.Sprintf("%d/%d", len(m.visible), len(m.niri.Windows))))
+}
+
+// renderTable renders the visible slice of the windows table
+func (m *WindowsModel) renderTable() string {
+	if len(m.visible) == 0 {
+		if len(m.niri.Windows) == 0 {
+			return styles.DimmedStyle.Render("No open windows") + "\n"
+		}
+		return styles.DimmedStyle.Render("No windows match") + "\n"
+	}
+
+	titleWidth := max(10, m.width-appIDColumnWidth-workspaceColumnWidth-windowOutputColumnWidth-layoutColumnWidth-10)
+	appStyle := lipgloss.NewStyle().Width(appIDColumnWidth)
+	titleStyle := lipgloss.NewStyle().Width(titleWidth)
+	workspaceStyle := lipgloss.NewStyle().Width(workspaceColumnWidth)
+	outputStyle := lipgloss.NewStyle().Width(windowOutputColumnWidth)
+	layoutStyle := lipgloss.NewStyle().Width(layoutColumnWidth)
+
+	headerStyle := lipgloss.NewStyle().Foreground(styles.ColorPink).Bold(true)
+
+	var b strings.Builder
+	b.WriteString("    ")
+	b.WriteString(headerStyle.Inherit(appStyle).Render("App ID"))
+	b.WriteString(headerStyle.Inherit(titleStyle).Render("Title"))
+	b.WriteString(headerStyle.Inherit(workspaceStyle).Render("Workspace"))
+	b.WriteString(headerStyle.Inherit(outputStyle).Render("Output"))
+	b.WriteString(headerStyle.Inherit(layoutStyle).Render("Layout"))
+	b.WriteString("\n")
+
+	end := min(len(m.visible), m.offset+m.tableHeight())
+	for i := m.offset; i < end; i++ {
+		row := m.visible[i]
+		w := row.window
+		selected := i == m.cursor
+
+		focus := "  "
+		if w.IsFocused {
+			focus = styles.StatusOK.Render(styles.SymbolOK) + " "
+		}
+		layout := "tiled"
+		if w.IsFloating {
+			layout = "floating"
+		}
+
+		cells := []string{
+			appStyle.Render(truncate(w.AppIDOr("—"), appIDColumnWidth-1)),
+			titleStyle.Render(truncate(w.TitleOr("—"), titleWidth-1)),
+			workspaceStyle.Render(truncate(row.workspaceLabel(), workspaceColumnWidth-1)),
+			outputStyle.Render(truncate(row.output(), windowOutputColumnWidth-1)),
+			layoutStyle.Render(layout),
+		}
+		text := lipgloss.NewStyle().Foreground(styles.ColorComment)
+		if selected {
+			text = lipgloss.NewStyle().Foreground(styles.ColorGreen).Bold(true)
+		} else if w.IsFocused {
+			text = lipgloss.NewStyle().Foreground(styles.ColorForeground)
+		}
+
+		b.WriteString(renderRowStart(selected))
+		b.WriteString(focus)
+		b.WriteString(text.Render(strings.Join(cells, "")))
+		b.WriteString("\n")
+	}
+
+	if len(m.visible) > m.tableHeight() {
+		b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  %d-%d of %d", m.offset+1, end, len(m.visible))))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderDetails shows everything niri reports about the selected window
+func (m *WindowsModel) renderDetails() string {
+	row := m.selected()
+	if row == nil {
+		return ""
+	}
+	w := row.window
+
+	parts := []string{fmt.Sprintf("id %d", w.ID)}
+	if w.PID != nil {
+		parts = append(parts, fmt.Sprintf("pid %d", *w.PID))
+	}
+	if w.IsFocused {
+		parts = append(parts, "focused")
+	}
+	if w.IsUrgent {
+		parts = append(parts, "urgent")
+	}
+	return styles.DimmedStyle.Render(truncate(strings.Join(parts, " • "), max(10, m.width-4))) + "\n"
+}
+
+// windowName names a window in messages
+func windowName(w niriipc.Window) string {
+	return w.AppIDOr(w.TitleOr(fmt.Sprintf("window %d", w.ID)))
+}
+
+// Kinds of window prompt
+const (
+	promptWorkspace = iota
+	promptOutput
+	promptWidth
+)
+
+// windowPrompt asks where to move a window, or how wide to make it
+type windowPrompt struct {
+	kind   int
+	window niriipc.Window
+	// Workspaces or outputs to pick from, by label, with the workspace
+	// ids for promptWorkspace
+	choices []string
+	ids     []uint64
+	choice  int
+	input   textinput.Model // the width, for promptWidth
+	err     error
+}
+
+// openPrompt opens a prompt for the window
+func (m *WindowsModel) openPrompt(kind int, w niriipc.Window) tea.Cmd {
+	p := &windowPrompt{kind: kind, window: w}
+	switch kind {
+	case promptWorkspace:
+		workspaces := make([]niriipc.Workspace, 0, len(m.niri.Workspaces))
+		for _, ws := range m.niri.Workspaces {
+			workspaces = append(workspaces, ws)
+		}
+		slices.SortFunc(workspaces, func(a, b niriipc.Workspace) int {
+			return cmp.Or(cmp.Compare(deref(a.Output), deref(b.Output)), cmp.Compare(a.Idx, b.Idx))
+		})
+		for _, ws := range workspaces {
+			if w.WorkspaceID != nil && ws.ID == *w.WorkspaceID {
+				p.choice = len(p.choices)
+			}
+			p.choices = append(p.choices, deref(ws.Output)+" / "+ws.Label())
+			p.ids = append(p.ids, ws.ID)
+		}
+	case promptOutput:
+		for _, ws := range m.niri.Workspaces {
+			if ws.Output != nil && !slices.Contains(p.choices, *ws.Output) {
+				p.choices = append(p.choices, *ws.Output)
+			}
+		}
+		slices.Sort(p.choices)
+	case promptWidth:
+		p.input = textinput.New()
+		p.input.Prompt = ""
+		p.input.Placeholder = "50%"
+		p.input.Width = 12
+	}
+
+	if kind != promptWidth && len(p.choices) == 0 {
+		m.message, m.err = "", fmt.Errorf("niri reports nowhere to move %s", windowName(w))
+		return nil
+	}
+	m.prompt = p
+	m.message, m.err = "", nil
+	if kind == promptWidth {
+		return p.input.Focus()
+	}
+	return nil
+}
+
+// deref returns the string a pointer points at, or "" for nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// updatePrompt handles keys while a prompt is open
+func (m *WindowsModel) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	p := m.prompt
+	switch msg.String() {
+	case "esc":
+		m.prompt = nil
+		return nil
+	case "enter":
+		a, done, err := p.action()
+		if err != nil {
+			p.err = err
+			return nil
+		}
+		m.prompt = nil
+		return m.run(a, done)
+	}
+
+	if p.kind == promptWidth {
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return cmd
+	}
+	switch msg.String() {
+	case "left", "up", "shift+tab", "h", "k":
+		p.choice = (p.choice + len(p.choices) - 1) % len(p.choices)
+	case "right", "down", "tab", "l", "j":
+		p.choice = (p.choice + 1) % len(p.choices)
+	}
+	return nil
+}
+
+// action returns the action the prompt describes
+func (p *windowPrompt) action() (niriipc.Action, string, error) {
+	name := windowName(p.window)
+	switch p.kind {
+	case promptWorkspace:
+		return niriipc.MoveWindowToWorkspace(p.window.ID, p.ids[p.choice], false),
+			"Moved " + name + " to " + p.choices[p.choice], nil
+	case promptOutput:
+		return niriipc.MoveWindowToMonitor(p.window.ID, p.choices[p.choice]),
+			"Moved " + name + " to " + p.choices[p.choice], nil
+	}
+	change, err := niriipc.ParseSizeChange(p.input.Value())
+	if err != nil {
+		return niriipc.Action{}, "", err
+	}
+	return niriipc.SetWindowWidth(p.window.ID, change), "Resized " + name, nil
+}
+
+// view renders the prompt
+func (p *windowPrompt) view() string {
+	var b strings.Builder
+	name := windowName(p.window)
+	label := styles.LabelStyle.UnsetWidth()
+	switch p.kind {
+	case promptWorkspace:
+		b.WriteString(label.Render("Move " + name + " to workspace: "))
+		b.WriteString(renderChoice(p.choices[p.choice], true))
+	case promptOutput:
+		b.WriteString(label.Render("Move " + name + " to output: "))
+		b.WriteString(renderChoice(p.choices[p.choice], true))
+	case promptWidth:
+		b.WriteString(label.Render("Column width of " + name + ": "))
+		b.WriteString(p.input.View())
+	}
+	b.WriteString("\n")
+
+	if p.err != nil {
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", p.err)))
+		b.WriteString("\n")
+	}
+
+	help := "←→ choose • enter move • esc cancel"
+	if p.kind == promptWidth {
+		help = "1200 pixels • 50% of the screen • +10% or -100 to adjust • enter apply • esc cancel"
+	}
+	b.WriteString(styles.DimmedStyle.Render(help))
+	return b.String()
+}